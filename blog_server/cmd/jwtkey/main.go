@@ -0,0 +1,186 @@
+// jwtkey 管理 JWT 签名密钥 (在 blog_server 目录下运行，读取 config.yaml 中的 jwt.key_dir)
+//
+//	go run ./cmd/jwtkey list                      列出密钥
+//	go run ./cmd/jwtkey generate [-alg EdDSA]     生成新密钥 (只用于校验，所有实例加载后再 activate)
+//	go run ./cmd/jwtkey activate <kid>            切换签名密钥
+//	go run ./cmd/jwtkey rotate [-alg EdDSA]       generate + activate (单实例部署)
+//	go run ./cmd/jwtkey retire [-force] <kid>     删除旧密钥 (用它签发的 Token 立即失效)
+//	go run ./cmd/jwtkey public <kid>              导出公钥 PEM
+//
+// 服务每分钟重新加载一次密钥目录，不需要重启
+package main
+
+import (
+	"flag"
+	"fmt"
+	"my-blog/config"
+	"my-blog/pkg/utils"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	config.InitConfig()
+
+	cmd, args := os.Args[1], os.Args[2:]
+	var err error
+	switch cmd {
+	case "list":
+		err = list()
+	case "generate", "rotate":
+		fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+		alg := fs.String("alg", utils.AlgEdDSA, "签名算法: RS256 / EdDSA")
+		fs.Parse(args)
+		err = generate(*alg, cmd == "rotate")
+	case "activate":
+		err = activate(kidArg(args))
+	case "retire":
+		fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+		force := fs.Bool("force", false, "不等待旧 Token 过期，立即删除")
+		fs.Parse(args)
+		err = retire(kidArg(fs.Args()), *force)
+	case "public":
+		err = public(kidArg(args))
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		os.Exit(1)
+	}
+}
+
+func list() error {
+	keys, err := config.ReadJwtKeyDir()
+	if err != nil {
+		return err
+	}
+	active := config.ActiveJwtKidFile()
+	fmt.Printf("密钥目录: %s\n", config.JwtKeyDir())
+	for _, key := range keys {
+		mark := " "
+		if key.Kid == active {
+			mark = "*"
+		}
+		usage := "签名+校验"
+		if !key.CanSign() {
+			usage = "仅校验"
+		}
+		fmt.Printf("%s %-32s %-6s %s\n", mark, key.Kid, key.Alg, usage)
+	}
+	if len(config.Config.Jwt.Keys) > 0 || config.Config.Jwt.ActiveKid != "" {
+		fmt.Println("注意: config.yaml 中还声明了 jwt.keys / jwt.active_kid，以配置文件为准")
+	}
+	return nil
+}
+
+func generate(alg string, activateNow bool) error {
+	kid, err := config.GenerateJwtKey(alg)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已生成密钥: %s (%s)\n", kid, alg)
+	if !activateNow {
+		fmt.Printf("确认所有实例都已加载 (约 1 分钟) 后执行: go run ./cmd/jwtkey activate %s\n", kid)
+		return nil
+	}
+	return activate(kid)
+}
+
+func activate(kid string) error {
+	key, err := findKey(kid)
+	if err != nil {
+		return err
+	}
+	if !key.CanSign() {
+		return fmt.Errorf("%s 只有公钥，不能用于签名", kid)
+	}
+	if err := config.ActivateJwtKey(kid); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 签名密钥已切换为: %s\n", kid)
+	fmt.Printf("旧密钥签发的 Token 最长 %s 后全部过期，之后可以 retire 旧密钥\n", accessTTL())
+	return nil
+}
+
+func retire(kid string, force bool) error {
+	if kid == config.ActiveJwtKidFile() {
+		return fmt.Errorf("%s 是当前签名密钥，请先 activate 其他密钥", kid)
+	}
+	if _, err := findKey(kid); err != nil {
+		return err
+	}
+
+	// 切换签名密钥之后，要等旧 Token 全部过期才能删除旧密钥
+	if !force {
+		if info, err := os.Stat(filepath.Join(config.JwtKeyDir(), "active")); err == nil {
+			if wait := time.Until(info.ModTime().Add(accessTTL())); wait > 0 {
+				return fmt.Errorf("签名密钥刚切换，旧 Token 还未全部过期，请 %s 后再试 (或加 -force)", wait.Round(time.Second))
+			}
+		}
+	}
+
+	if err := os.Remove(filepath.Join(config.JwtKeyDir(), kid+".pem")); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已删除密钥: %s\n", kid)
+	return nil
+}
+
+func public(kid string) error {
+	key, err := findKey(kid)
+	if err != nil {
+		return err
+	}
+	data, err := key.PublicKeyPEM()
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+// --- Helper Functions ---
+
+func findKey(kid string) (*utils.JwtKey, error) {
+	keys, err := config.ReadJwtKeyDir()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.Kid == kid {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("密钥不存在: %s", kid)
+}
+
+func accessTTL() time.Duration {
+	if config.Config.Jwt.AccessTTLMinutes > 0 {
+		return time.Duration(config.Config.Jwt.AccessTTLMinutes) * time.Minute
+	}
+	return 30 * time.Minute
+}
+
+func kidArg(args []string) string {
+	if len(args) != 1 || args[0] == "" {
+		usage()
+	}
+	return args[0]
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `用法: go run ./cmd/jwtkey <命令> [参数]
+  list                      列出密钥 (* 为当前签名密钥)
+  generate [-alg EdDSA]     生成新密钥，可选 RS256 / EdDSA
+  activate <kid>            切换签名密钥
+  rotate [-alg EdDSA]       生成并立即切换 (单实例部署)
+  retire [-force] <kid>     删除旧密钥
+  public <kid>              导出公钥 PEM`)
+	os.Exit(2)
+}
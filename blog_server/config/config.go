@@ -31,6 +31,73 @@ type AppConfig struct {
 		UploadAvatarDir string `yaml:"upload_avatar_dir"`
 		ArticleImgDir   string `yaml:"article_img_dir"`
 	} `yaml:"file"`
+	// [NEW] Token 有效期配置
+	Jwt struct {
+		AccessTTLMinutes int `yaml:"access_ttl_minutes"` // Access Token 有效期 (分钟)
+		RefreshTTLHours  int `yaml:"refresh_ttl_hours"`  // Refresh Token 有效期 (小时)
+		// [NEW] 签名密钥 (见 jwt_keys.go)
+		KeyDir    string         `yaml:"key_dir"`    // 密钥目录：每个 <kid>.pem 是一把密钥，active 文件记录签名用的 kid
+		ActiveKid string         `yaml:"active_kid"` // 可选，指定签名用的 kid (优先于 active 文件)
+		Keys      []JwtKeyConfig `yaml:"keys"`       // 可选，直接在配置中声明的密钥
+	} `yaml:"jwt"`
+	// [NEW] 登录安全配置
+	Security struct {
+		LoginCaptchaThreshold int `yaml:"login_captcha_threshold"` // 失败 N 次后需要图形验证码
+		LoginLockThreshold    int `yaml:"login_lock_threshold"`    // 失败 M 次后锁定账号
+		LoginLockMinutes      int `yaml:"login_lock_minutes"`      // 锁定时长 (同时也是失败计数的统计窗口)
+		// [NEW] 这些角色必须开启两步验证，未开启时登录后不授予该角色
+		MfaRequiredRoles []string `yaml:"mfa_required_roles"`
+		MfaIssuer        string   `yaml:"mfa_issuer"` // 验证器 App 中显示的名称
+		// [NEW] 邮件登录链接
+		MagicLinkUrl        string `yaml:"magic_link_url"`         // 前端落地页，后端拼上 ?token=xxx
+		MagicLinkTTLMinutes int    `yaml:"magic_link_ttl_minutes"` // 链接有效期
+	} `yaml:"security"`
+	// [NEW] 密码策略 (见 password.go，各项说明见 config/config.yaml)
+	Password struct {
+		MinLength     int    `yaml:"min_length"`     // 最短长度，默认 8
+		MaxLength     int    `yaml:"max_length"`     // 最长长度，默认 64 (bcrypt 最多 72 字节)
+		MinClasses    int    `yaml:"min_classes"`    // 大写、小写、数字、符号 至少包含几类，默认 2
+		HistorySize   int    `yaml:"history_size"`   // 不能与最近 N 次的密码相同，0 表示不限制
+		BreachedCheck *bool  `yaml:"breached_check"` // 拒绝出现在泄露密码库中的密码，不填默认开启
+		BreachedFile  string `yaml:"breached_file"`  // 可选，替换内置的泄露密码库
+	} `yaml:"password"`
+	// [NEW] Passkey (WebAuthn) 依赖方配置，RPID 必须是前端访问的域名
+	Webauthn struct {
+		RPID          string   `yaml:"rp_id"`
+		RPDisplayName string   `yaml:"rp_display_name"`
+		RPOrigins     []string `yaml:"rp_origins"` // 允许发起认证的前端地址
+	} `yaml:"webauthn"`
+	// [NEW] 第三方登录 (OAuth2 / OpenID Connect)
+	OAuth struct {
+		// 登录完成后跳回的前端页面，后端会在后面拼上 ?ticket=xxx 或 ?error=xxx
+		FrontendRedirect string                `yaml:"frontend_redirect"`
+		Providers        []OAuthProviderConfig `yaml:"providers"`
+	} `yaml:"oauth"`
+	// [NEW] 全文搜索 (见 search.go)
+	Search struct {
+		// 用户词典文件，格式同 pkg/search/dict.txt (每行 "词 [词频] [拼音]")，站点常用的技术词汇加在这里
+		UserDicts []string `yaml:"user_dicts"`
+	} `yaml:"search"`
+}
+
+// [NEW] 单个第三方登录提供方
+type OAuthProviderConfig struct {
+	Name         string   `yaml:"name"`         // 唯一标识，出现在回调地址中，如 github
+	Type         string   `yaml:"type"`         // github / oidc
+	DisplayName  string   `yaml:"display_name"` // 登录按钮上显示的名称
+	ClientId     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectUrl  string   `yaml:"redirect_url"` // 后端回调地址: .../api/oauth/{name}/callback
+	Issuer       string   `yaml:"issuer"`       // 仅 oidc：签发者地址 (自动发现 .well-known/openid-configuration)
+	Scopes       []string `yaml:"scopes"`       // 可选，不填使用默认值
+}
+
+// [NEW] 配置文件中声明的 JWT 密钥：HS256 填 secret，RS256 / EdDSA 填 PEM 文件路径
+type JwtKeyConfig struct {
+	Kid    string `yaml:"kid"`
+	Alg    string `yaml:"alg"`    // HS256 时必填，PEM 文件的算法由密钥类型决定
+	Secret string `yaml:"secret"` // HS256 密钥，至少 32 字节
+	File   string `yaml:"file"`   // PEM 文件 (私钥，或只用于校验的公钥)
 }
 
 var Config AppConfig
@@ -24,6 +24,8 @@ func InitDB() {
 	var err error
 	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
+		// [NEW] 把唯一索引冲突等数据库错误转换为 gorm.ErrDuplicatedKey，便于业务层判断
+		TranslateError: true,
 	})
 
 	if err != nil {
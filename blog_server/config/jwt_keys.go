@@ -0,0 +1,184 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"my-blog/pkg/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// [NEW] JWT 签名密钥加载
+// 来源：配置文件中的 jwt.keys + jwt.key_dir 目录下的 <kid>.pem
+// 签名用的 kid：jwt.active_kid > key_dir/active 文件 > 目录中最新的私钥
+// 轮换流程 (go run ./cmd/jwtkey)：generate 生成新密钥 -> 所有实例加载后 activate -> Token 过期后 retire 旧密钥
+
+const jwtActiveFile = "active"
+
+// InitJwtKeys 启动时加载密钥，之后每分钟重新加载一次 (轮换密钥不需要重启)
+func InitJwtKeys() {
+	if err := LoadJwtKeys(true); err != nil {
+		log.Fatalf("❌ JWT 密钥加载失败: %v", err)
+	}
+	log.Printf("✅ JWT 密钥加载成功，当前签名密钥: %s", utils.ActiveJwtKid())
+
+	go func() {
+		for range time.Tick(time.Minute) {
+			if err := LoadJwtKeys(false); err != nil {
+				log.Printf("⚠️ JWT 密钥重新加载失败，继续使用旧密钥: %v", err)
+			}
+		}
+	}()
+}
+
+// LoadJwtKeys 读取全部密钥并替换；generateIfEmpty 为 true 时没有任何密钥会自动生成一把
+func LoadJwtKeys(generateIfEmpty bool) error {
+	keys, err := readConfigJwtKeys()
+	if err != nil {
+		return err
+	}
+	dirKeys, err := ReadJwtKeyDir()
+	if err != nil {
+		return err
+	}
+	keys = append(keys, dirKeys...)
+
+	if len(keys) == 0 {
+		if !generateIfEmpty {
+			return errors.New("没有可用的密钥")
+		}
+		kid, err := GenerateJwtKey(utils.AlgEdDSA)
+		if err != nil {
+			return err
+		}
+		if err := ActivateJwtKey(kid); err != nil {
+			return err
+		}
+		log.Printf("⚠️ 未配置 JWT 密钥，已自动生成: %s", filepath.Join(JwtKeyDir(), kid+".pem"))
+		return LoadJwtKeys(false)
+	}
+
+	return utils.SetJwtKeys(keys, activeJwtKid(keys))
+}
+
+// JwtKeyDir 密钥目录 (默认 ./keys)
+func JwtKeyDir() string {
+	if Config.Jwt.KeyDir == "" {
+		return "keys"
+	}
+	return Config.Jwt.KeyDir
+}
+
+// ReadJwtKeyDir 读取密钥目录下的全部 PEM 文件 (按 kid 排序)，目录不存在时返回空
+func ReadJwtKeyDir() ([]*utils.JwtKey, error) {
+	files, err := filepath.Glob(filepath.Join(JwtKeyDir(), "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var keys []*utils.JwtKey
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := utils.ParsePEMKey(strings.TrimSuffix(filepath.Base(file), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// GenerateJwtKey 在密钥目录中生成一把新私钥，返回 kid (此时只用于校验，activate 后才用于签名)
+func GenerateJwtKey(alg string) (string, error) {
+	data, err := utils.GenerateJwtKeyPEM(alg)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(JwtKeyDir(), 0700); err != nil {
+		return "", err
+	}
+	kid := utils.NewJwtKid()
+	if err := os.WriteFile(filepath.Join(JwtKeyDir(), kid+".pem"), data, 0600); err != nil {
+		return "", err
+	}
+	return kid, nil
+}
+
+// ActivateJwtKey 把 kid 写入 active 文件，之后签发的 Token 使用这把密钥
+func ActivateJwtKey(kid string) error {
+	if err := os.MkdirAll(JwtKeyDir(), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(JwtKeyDir(), jwtActiveFile), []byte(kid+"\n"), 0600)
+}
+
+// ActiveJwtKidFile 读取 active 文件，不存在时返回空
+func ActiveJwtKidFile() string {
+	data, err := os.ReadFile(filepath.Join(JwtKeyDir(), jwtActiveFile))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// --- Helper Functions ---
+
+func readConfigJwtKeys() ([]*utils.JwtKey, error) {
+	var keys []*utils.JwtKey
+	for _, cfg := range Config.Jwt.Keys {
+		if cfg.Kid == "" {
+			return nil, errors.New("jwt.keys 中的 kid 不能为空")
+		}
+		switch {
+		case cfg.Secret != "":
+			if cfg.Alg != "" && cfg.Alg != utils.AlgHS256 {
+				return nil, fmt.Errorf("密钥 %s: secret 只能用于 HS256", cfg.Kid)
+			}
+			key, err := utils.NewHMACKey(cfg.Kid, []byte(cfg.Secret))
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		case cfg.File != "":
+			data, err := os.ReadFile(cfg.File)
+			if err != nil {
+				return nil, err
+			}
+			key, err := utils.ParsePEMKey(cfg.Kid, data)
+			if err != nil {
+				return nil, err
+			}
+			if cfg.Alg != "" && cfg.Alg != key.Alg {
+				return nil, fmt.Errorf("密钥 %s: 算法与密钥类型不一致 (%s)", cfg.Kid, key.Alg)
+			}
+			keys = append(keys, key)
+		default:
+			return nil, fmt.Errorf("密钥 %s: 必须填写 secret 或 file", cfg.Kid)
+		}
+	}
+	return keys, nil
+}
+
+// 签名用的 kid：配置 > active 文件 > 最后一把有私钥的密钥
+func activeJwtKid(keys []*utils.JwtKey) string {
+	if Config.Jwt.ActiveKid != "" {
+		return Config.Jwt.ActiveKid
+	}
+	if kid := ActiveJwtKidFile(); kid != "" {
+		return kid
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		if keys[i].CanSign() {
+			return keys[i].Kid
+		}
+	}
+	return ""
+}
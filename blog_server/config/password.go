@@ -0,0 +1,58 @@
+package config
+
+import (
+	"io"
+	"log"
+	"my-blog/pkg/utils"
+	"os"
+)
+
+// [NEW] 密码策略 + 泄露密码库
+
+// InitPasswordPolicy 启动时加载泄露密码库
+func InitPasswordPolicy() {
+	if !BreachedCheckEnabled() {
+		log.Println("⚠️ 已关闭泄露密码检查 (password.breached_check: false)")
+		return
+	}
+
+	var r io.Reader
+	if Config.Password.BreachedFile != "" {
+		f, err := os.Open(Config.Password.BreachedFile)
+		if err != nil {
+			log.Fatalf("❌ 泄露密码库加载失败: %v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	count, err := utils.LoadBreachedPasswords(r)
+	if err != nil {
+		log.Fatalf("❌ 泄露密码库加载失败: %v", err)
+	}
+	log.Printf("✅ 泄露密码库加载成功，共 %d 条", count)
+}
+
+// PasswordPolicy 当前的密码强度要求 (未配置的项使用默认值)
+func PasswordPolicy() utils.PasswordPolicy {
+	p := utils.PasswordPolicy{
+		MinLength:   Config.Password.MinLength,
+		MaxLength:   Config.Password.MaxLength,
+		MinClasses:  Config.Password.MinClasses,
+		HistorySize: Config.Password.HistorySize,
+	}
+	if p.MinLength <= 0 {
+		p.MinLength = 8
+	}
+	if p.MaxLength <= 0 || p.MaxLength > 72 {
+		p.MaxLength = 64
+	}
+	if p.MinClasses <= 0 {
+		p.MinClasses = 2
+	}
+	return p
+}
+
+// BreachedCheckEnabled 是否检查泄露密码库 (只有显式配置为 false 才关闭)
+func BreachedCheckEnabled() bool {
+	return Config.Password.BreachedCheck == nil || *Config.Password.BreachedCheck
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"log"
+	"my-blog/pkg/search"
+	"os"
+)
+
+// [NEW] 搜索分词的用户词典
+
+// InitSearchDict 启动时把用户词典追加到内置词典 (要在建搜索索引之前调用)
+func InitSearchDict() {
+	for _, file := range Config.Search.UserDicts {
+		f, err := os.Open(file)
+		if err != nil {
+			log.Fatalf("❌ 用户词典加载失败: %v", err)
+		}
+		count, err := search.LoadUserDict(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("❌ 用户词典加载失败 (%s): %v", file, err)
+		}
+		log.Printf("✅ 用户词典加载成功 (%s)，共 %d 个词", file, count)
+	}
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 个人访问令牌 (API Token)，用于脚本、CI 发布文章
+type AccessTokenController struct {
+	accessTokenService service.AccessTokenService
+}
+
+func NewAccessTokenController(accessTokenService service.AccessTokenService) *AccessTokenController {
+	return &AccessTokenController{accessTokenService: accessTokenService}
+}
+
+// GET /api/user/token/scopes
+// 可选的授权范围
+func (ctrl *AccessTokenController) Scopes(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.Ok().Put("scopes", ctrl.accessTokenService.Scopes()))
+}
+
+// GET /api/user/token/list
+func (ctrl *AccessTokenController) List(c *gin.Context) {
+	tokens, err := ctrl.accessTokenService.List(c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("获取令牌列表失败"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("tokens", tokens))
+}
+
+// POST /api/user/token/create
+// 前端传参: { "name": "CI 发布", "scopes": ["articles:write"], "expiresInDays": 90 } (0 表示永不过期)
+// 明文令牌只在这里返回一次
+func (ctrl *AccessTokenController) Create(c *gin.Context) {
+	var dto struct {
+		Name          string   `json:"name"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays int      `json:"expiresInDays"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	token, plain, err := ctrl.accessTokenService.Create(c.GetInt("userId"), dto.Name, dto.Scopes, dto.ExpiresInDays)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().
+		Put("msg", "令牌已创建，请立即复制保存，关闭后将无法再次查看").
+		Put("token", plain).
+		Put("accessToken", token))
+}
+
+// POST /api/user/token/revoke
+// 前端传参: { "id": 1 }
+func (ctrl *AccessTokenController) Revoke(c *gin.Context) {
+	var dto struct {
+		Id int `json:"id"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.Id <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	if err := ctrl.accessTokenService.Revoke(c.GetInt("userId"), dto.Id); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "令牌已删除"))
+}
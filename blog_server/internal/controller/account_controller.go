@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"mime"
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 个人数据导出、注销账号
+type AccountController struct {
+	accountService service.AccountService
+}
+
+func NewAccountController(accountService service.AccountService) *AccountController {
+	return &AccountController{accountService: accountService}
+}
+
+// GET /api/user/account/export?format=zip
+// format: json (单个文件) / zip (按类别分文件，文章另附 Markdown)，默认 zip
+func (ctrl *AccountController) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", "zip")
+
+	filename, content, err := ctrl.accountService.Export(c.GetInt("userId"), format)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+
+	contentType := "application/zip"
+	if format == "json" {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// POST /api/user/account/sendDeleteCode
+// 向账号邮箱发送注销验证码
+func (ctrl *AccountController) SendDeleteCode(c *gin.Context) {
+	if err := ctrl.accountService.SendDeleteCode(c.GetInt("userId")); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "验证码已发送至账号邮箱"))
+}
+
+// POST /api/user/account/delete
+// 前端传参: { "mode": "anonymize" | "remove", "code": "123456", "password": "" }
+// 有邮箱的账号填 code，没有邮箱的填 password
+func (ctrl *AccountController) Delete(c *gin.Context) {
+	var dto struct {
+		Mode     string `json:"mode"`
+		Code     string `json:"code"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	if err := ctrl.accountService.Delete(c.GetInt("userId"), dto.Mode, dto.Code, dto.Password); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "账号已注销"))
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"my-blog/internal/model"
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 后台用户管理 (替代原来公开的 GET /api/users)
+type AdminUserController struct {
+	adminUserService service.AdminUserService
+}
+
+func NewAdminUserController(adminUserService service.AdminUserService) *AdminUserController {
+	return &AdminUserController{adminUserService: adminUserService}
+}
+
+// POST /api/admin/user/list
+// 前端传参: { "pageParams": {"page":1, "rows":20}, "userCondition": {"username":"", "email":"", "createdFrom":"2025-01-01T00:00:00+08:00", "valid":0} }
+func (ctrl *AdminUserController) List(c *gin.Context) {
+	var req struct {
+		PageParams    utils.PageParams    `json:"pageParams"`
+		UserCondition model.UserCondition `json:"userCondition"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数格式错误"))
+		return
+	}
+	if req.PageParams.Page <= 0 {
+		req.PageParams.Page = 1
+	}
+	if req.PageParams.Rows <= 0 || req.PageParams.Rows > 100 {
+		req.PageParams.Rows = 20
+	}
+
+	users, total, err := ctrl.adminUserService.Search(&req.UserCondition, req.PageParams.Page, req.PageParams.Rows)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("查询失败"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("users", users).Put("total", total))
+}
+
+// GET /api/admin/user/detail?userId=
+// 用户信息 + 活跃度汇总 + 登录设备
+func (ctrl *AdminUserController) Detail(c *gin.Context) {
+	userId, _ := strconv.Atoi(c.Query("userId"))
+	if userId <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	detail, err := ctrl.adminUserService.Detail(userId)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().
+		Put("user", detail.User).
+		Put("activity", detail.Activity).
+		Put("sessions", detail.Sessions))
+}
+
+// POST /api/admin/user/ban
+// 前端传参: { "userId": 2 }
+func (ctrl *AdminUserController) Ban(c *gin.Context) {
+	userId, ok := bindUserId(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.adminUserService.Ban(c.GetInt("userId"), userId); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "已封禁，该用户的所有设备已下线"))
+}
+
+// POST /api/admin/user/unban
+// 前端传参: { "userId": 2 }
+func (ctrl *AdminUserController) Unban(c *gin.Context) {
+	userId, ok := bindUserId(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.adminUserService.Unban(userId); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "已解封"))
+}
+
+// POST /api/admin/user/resetPassword
+// 前端传参: { "userId": 2 }
+func (ctrl *AdminUserController) ResetPassword(c *gin.Context) {
+	userId, ok := bindUserId(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.adminUserService.ForcePasswordReset(c.GetInt("userId"), userId); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "密码已重置，已邮件通知用户"))
+}
+
+// POST /api/admin/user/assignRoles
+// 前端传参: { "userId": 2, "roles": ["author"] }
+func (ctrl *AdminUserController) ChangeRoles(c *gin.Context) {
+	var dto struct {
+		UserId int      `json:"userId"`
+		Roles  []string `json:"roles"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.UserId <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	if err := ctrl.adminUserService.ChangeRoles(c.GetInt("userId"), dto.UserId, dto.Roles); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "角色分配成功"))
+}
+
+// --- Helper Functions ---
+
+func bindUserId(c *gin.Context) (int, bool) {
+	var dto struct {
+		UserId int `json:"userId"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.UserId <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return 0, false
+	}
+	return dto.UserId, true
+}
@@ -5,8 +5,8 @@ import (
 	"my-blog/internal/service"
 	"my-blog/pkg/utils" // 引入我们刚写的工具包
 	"net/http"
+	"net/url"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -38,7 +38,8 @@ func (ctrl *ArticleController) Detail(c *gin.Context) {
 	idStr := c.Param("id")
 	id, _ := strconv.Atoi(idStr)
 
-	article, err := ctrl.articleService.GetArticleDetail(id)
+	// [MODIFY] 作者本人可以查看自己的草稿
+	article, err := ctrl.articleService.GetArticleDetail(id, c.GetInt("userId"))
 	if err != nil {
 		c.JSON(http.StatusOK, utils.Error("文章不存在"))
 		return
@@ -48,6 +49,21 @@ func (ctrl *ArticleController) Detail(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.Ok().Put("article", article))
 }
 
+// [NEW] 按 slug 查文章 GET /api/article/slug/:slug
+// slug 是改名前的旧链接时 301 跳转到现在的链接
+func (ctrl *ArticleController) DetailBySlug(c *gin.Context) {
+	article, current, err := ctrl.articleService.GetArticleBySlug(c.Param("slug"), c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("文章不存在"))
+		return
+	}
+	if current != "" {
+		c.Redirect(http.StatusMovedPermanently, "/api/article/slug/"+url.PathEscape(current))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("article", article))
+}
+
 // [NEW] 对应 Java 的 @PostMapping("/getAPageOfArticle")
 func (ctrl *ArticleController) GetPage(c *gin.Context) {
 	// 1. 接收前端传来的 JSON 参数
@@ -93,7 +109,8 @@ func (ctrl *ArticleController) Publish(c *gin.Context) {
 	}
 
 	// 5. 调用 Service
-	err := ctrl.articleService.Publish(&article, isEdit)
+	// [MODIFY] 作者取自登录用户，编辑时校验是否为作者本人或管理员
+	err := ctrl.articleService.Publish(&article, isEdit, currentOperator(c))
 	if err != nil {
 		c.JSON(http.StatusOK, utils.Error("操作失败: "+err.Error()))
 		return
@@ -117,7 +134,8 @@ func (ctrl *ArticleController) Delete(c *gin.Context) {
 
 	id, _ := strconv.Atoi(idStr)
 
-	if err := ctrl.articleService.Delete(id); err != nil {
+	// [MODIFY] 只能删除自己的文章 (管理员除外)
+	if err := ctrl.articleService.Delete(id, currentOperator(c)); err != nil {
 		c.JSON(http.StatusOK, utils.Error("删除失败: "+err.Error()))
 		return
 	}
@@ -147,6 +165,26 @@ func (ctrl *ArticleController) GetAllTags(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
+// [NEW] 推荐标签
+// POST /api/article/tag/suggest
+// 前端传参: { "title": "...", "content": "..." }，返回 tags: ["微服务", "Go", ...]
+func (ctrl *ArticleController) SuggestTags(c *gin.Context) {
+	var dto struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+	tags, err := ctrl.articleService.SuggestTags(dto.Title, dto.Content)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("tags", tags))
+}
+
 // [REAL] 获取排行
 func (ctrl *ArticleController) GetLikeRanking(c *gin.Context) {
 	articles, err := ctrl.articleService.GetHotArticles()
@@ -181,19 +219,7 @@ func (ctrl *ArticleController) GetArticleAndFirstPageCommentByArticleId(c *gin.C
 	// 如果还没接 JWT，暂时写死 1。
 	// 如果接了，用 userId := c.GetInt("userId")
 	// [NEW] 真实逻辑：尝试解析 Token，但不强制要求
-	userId := 0 // 默认为游客
-	tokenStr := c.GetHeader("Authorization")
-	if strings.HasPrefix(tokenStr, "Bearer ") {
-		tokenStr = tokenStr[7:]
-	}
-
-	if tokenStr != "" {
-		if claims, err := utils.ParseToken(tokenStr); err == nil {
-			if v, ok := claims["userId"].(float64); ok {
-				userId = int(v)
-			}
-		}
-	}
+	userId := c.GetInt("userId") // 游客为 0 (middleware.OptionalAuth)
 
 	// 4. 调用我们在 Service 层写好的“超级接口”
 	// 这个接口会同时搞定：文章详情 + 是否点赞(IsLiked) + 第一页评论
@@ -242,6 +268,8 @@ func (ctrl *ArticleController) GetReadRanking(c *gin.Context) {
 
 // [NEW] 文章搜索接口 (按标签)
 // 对应 Java: @PostMapping("/articleSearch")
+// [MODIFY] 全文搜索：articleCondition 传 keyword (或 title / content) 时按相关度排序，
+// 每篇文章带 highlight.title / highlight.snippet；可同时按 categoryId (含子分类)、userId、tag、startDate / endDate 筛选
 func (ctrl *ArticleController) ArticleSearch(c *gin.Context) {
 	// 定义请求参数结构体，匹配前端 JSON 结构
 	// 前端传参: { "pageParams": {...}, "articleCondition": {...} }
@@ -291,8 +319,11 @@ func (ctrl *ArticleController) GetMyArticles(c *gin.Context) {
 		params.Rows = 10
 	}
 
+	// [MODIFY] 自己的文章返回所有状态，可通过 ?status=draft 等筛选
 	condition := &model.ArticleCondition{
-		UserId: c.GetInt("userId"),
+		UserId:   c.GetInt("userId"),
+		ViewerId: c.GetInt("userId"),
+		Status:   c.Query("status"),
 	}
 
 	res, err := ctrl.articleService.Search(&params, condition)
@@ -325,3 +356,13 @@ func (ctrl *ArticleController) GetMyLikedArticles(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, res)
 }
+
+// [NEW] 当前登录用户 (middleware.Auth 写入的上下文)，用于校验文章、评论、回复的归属
+func currentOperator(c *gin.Context) *model.Operator {
+	return &model.Operator{
+		UserId:      c.GetInt("userId"),
+		Username:    c.GetString("username"),
+		Roles:       c.GetStringSlice("roles"),
+		Permissions: c.GetStringSlice("permissions"),
+	}
+}
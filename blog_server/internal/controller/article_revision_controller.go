@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 文章历史版本 (作者本人或管理员)
+type ArticleRevisionController struct {
+	revisionService service.ArticleRevisionService
+}
+
+func NewArticleRevisionController(revisionService service.ArticleRevisionService) *ArticleRevisionController {
+	return &ArticleRevisionController{revisionService: revisionService}
+}
+
+// GET /api/article/revision/list?articleId=1&page=1&rows=20
+// 新的版本在前，不返回正文
+func (ctrl *ArticleRevisionController) List(c *gin.Context) {
+	articleId, _ := strconv.Atoi(c.Query("articleId"))
+	if articleId <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	rows, _ := strconv.Atoi(c.DefaultQuery("rows", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if rows <= 0 || rows > 100 {
+		rows = 20
+	}
+
+	revisions, total, err := ctrl.revisionService.List(articleId, currentOperator(c), page, rows)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("revisions", revisions).Put("total", total))
+}
+
+// GET /api/article/revision/detail?articleId=1&version=2
+func (ctrl *ArticleRevisionController) Detail(c *gin.Context) {
+	articleId, _ := strconv.Atoi(c.Query("articleId"))
+	version, _ := strconv.Atoi(c.Query("version"))
+	if articleId <= 0 || version <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	revision, err := ctrl.revisionService.Get(articleId, version, currentOperator(c))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("revision", revision))
+}
+
+// GET /api/article/revision/diff?articleId=1&from=2&to=5
+// 返回 unified diff 文本 (与 git diff 格式一致)
+func (ctrl *ArticleRevisionController) Diff(c *gin.Context) {
+	articleId, _ := strconv.Atoi(c.Query("articleId"))
+	from, _ := strconv.Atoi(c.Query("from"))
+	to, _ := strconv.Atoi(c.Query("to"))
+	if articleId <= 0 || from <= 0 || to <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	diff, err := ctrl.revisionService.Diff(articleId, from, to, currentOperator(c))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("diff", diff))
+}
+
+// POST /api/article/revision/restore
+// 前端传参: { "articleId": 1, "version": 2 }
+func (ctrl *ArticleRevisionController) Restore(c *gin.Context) {
+	var dto struct {
+		ArticleId int `json:"articleId"`
+		Version   int `json:"version"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.ArticleId <= 0 || dto.Version <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	revision, err := ctrl.revisionService.Restore(dto.ArticleId, dto.Version, currentOperator(c))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("revision", revision).Put("msg", "已恢复"))
+}
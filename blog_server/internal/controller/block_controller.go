@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"my-blog/internal/model"
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 拉黑 / 屏蔽用户
+type BlockController struct {
+	blockService service.BlockService
+}
+
+func NewBlockController(blockService service.BlockService) *BlockController {
+	return &BlockController{blockService: blockService}
+}
+
+// POST /api/user/block
+// 前端传参: { "userId": 2, "type": "block" }  type: block (拉黑，默认) / mute (屏蔽)
+func (ctrl *BlockController) Block(c *gin.Context) {
+	var dto struct {
+		UserId int    `json:"userId"`
+		Type   string `json:"type"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.UserId <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+	if dto.Type == "" {
+		dto.Type = model.BlockTypeBlock
+	}
+
+	if err := ctrl.blockService.Block(c.GetInt("userId"), dto.UserId, dto.Type); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	msg := "已拉黑"
+	if dto.Type == model.BlockTypeMute {
+		msg = "已屏蔽"
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", msg))
+}
+
+// POST /api/user/unblock
+// 前端传参: { "userId": 2 }  (拉黑和屏蔽都用这个解除)
+func (ctrl *BlockController) Unblock(c *gin.Context) {
+	userId, ok := bindUserId(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.blockService.Unblock(c.GetInt("userId"), userId); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "已解除"))
+}
+
+// GET /api/user/block/list?type=&page=1&rows=20
+// type 为空时返回拉黑和屏蔽的全部用户
+func (ctrl *BlockController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	rows, _ := strconv.Atoi(c.DefaultQuery("rows", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if rows <= 0 || rows > 100 {
+		rows = 20
+	}
+
+	users, total, err := ctrl.blockService.List(c.GetInt("userId"), c.Query("type"), page, rows)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("查询失败"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("users", users).Put("total", total))
+}
+
+// GET /api/user/block/status?userId=2
+// 返回 type: block / mute / "" (未拉黑)
+func (ctrl *BlockController) Status(c *gin.Context) {
+	userId, _ := strconv.Atoi(c.Query("userId"))
+	if userId <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+	blockType, err := ctrl.blockService.Status(c.GetInt("userId"), userId)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("查询失败"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("type", blockType))
+}
@@ -28,7 +28,8 @@ func (ctrl *CommentController) GetComments(c *gin.Context) {
 		pageParams = utils.PageParams{Page: 1, Rows: 10}
 	}
 
-	result, err := ctrl.commentService.GetComments(articleId, &pageParams)
+	// [MODIFY] 登录用户看不到自己拉黑 / 屏蔽的用户的评论
+	result, err := ctrl.commentService.GetComments(articleId, c.GetInt("userId"), &pageParams)
 	if err != nil {
 		c.JSON(http.StatusOK, utils.Error(err.Error()))
 		return
@@ -38,11 +39,11 @@ func (ctrl *CommentController) GetComments(c *gin.Context) {
 
 // [POST] 发表评论 /api/comment/insert
 func (ctrl *CommentController) InsertComment(c *gin.Context) {
-	// 1. 定义 DTO 接收前端参数 (ArticleAndComment.vue 传的是 author 和 articleId)
+	// 1. 定义 DTO 接收前端参数 (ArticleAndComment.vue 传的是 articleId)
+	// [MODIFY] 不再接收 author，评论者只能是当前登录用户
 	var dto struct {
 		ArticleId interface{} `json:"articleId"` // 容错：接收 string 或 int
 		Content   string      `json:"content"`
-		Author    string      `json:"author"` // 前端传过来的用户名
 	}
 
 	if err := c.ShouldBindJSON(&dto); err != nil {
@@ -65,12 +66,12 @@ func (ctrl *CommentController) InsertComment(c *gin.Context) {
 	comment := model.Comment{
 		ArticleId: finalArticleId,
 		Content:   dto.Content,
-		Author:    dto.Author,   // 先存前端传的 author
-		Ip:        c.ClientIP(), // 获取真实 IP
-		Location:  "未知",         // 后面可以用 ip2region 库解析
+		UserId:    c.GetInt("userId"), // [MODIFY] 使用 Token 中的 ID，而不是前端传的
+		Ip:        c.ClientIP(),       // 获取真实 IP
+		Location:  "未知",               // 后面可以用 ip2region 库解析
 	}
 
-	// 4. 调用 Service (它会自动根据 Author 查 UserId)
+	// 4. 调用 Service (它会根据 UserId 查作者名)
 	if err := ctrl.commentService.AddComment(&comment); err != nil {
 		c.JSON(http.StatusOK, utils.Error("评论失败: "+err.Error()))
 		return
@@ -102,6 +103,21 @@ func (ctrl *CommentController) LikeComment(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
+// [NEW] 删除评论 /api/comment/deleteById?id=
+// 只能删除自己的评论 (管理员除外)，评论下的回复一并删除
+func (ctrl *CommentController) DeleteComment(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Query("id"))
+	if id <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+	if err := ctrl.commentService.DeleteComment(id, currentOperator(c)); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "删除成功"))
+}
+
 // [NEW] 获取我的评论
 func (ctrl *CommentController) GetMyComment(c *gin.Context) {
 	var params utils.PageParams
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 关注作者、粉丝 / 关注列表、关注动态
+type FollowController struct {
+	followService service.FollowService
+}
+
+func NewFollowController(followService service.FollowService) *FollowController {
+	return &FollowController{followService: followService}
+}
+
+// POST /api/user/follow
+// 前端传参: { "userId": 2 }
+func (ctrl *FollowController) Follow(c *gin.Context) {
+	userId, ok := bindUserId(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.followService.Follow(c.GetInt("userId"), userId); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "已关注"))
+}
+
+// POST /api/user/unfollow
+// 前端传参: { "userId": 2 }
+func (ctrl *FollowController) Unfollow(c *gin.Context) {
+	userId, ok := bindUserId(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.followService.Unfollow(c.GetInt("userId"), userId); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "已取消关注"))
+}
+
+// GET /api/user/follow/status?userId=2
+// 当前用户是否已关注该用户 (个人主页上的"关注"按钮)
+func (ctrl *FollowController) Status(c *gin.Context) {
+	userId, _ := strconv.Atoi(c.Query("userId"))
+	if userId <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+	following, err := ctrl.followService.IsFollowing(c.GetInt("userId"), userId)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("查询失败"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("following", following))
+}
+
+// GET /api/user/:id/followers?page=1&rows=20
+func (ctrl *FollowController) Followers(c *gin.Context) {
+	userId, page, rows, ok := followListParams(c)
+	if !ok {
+		return
+	}
+	users, total, err := ctrl.followService.Followers(userId, page, rows)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("查询失败"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("users", users).Put("total", total))
+}
+
+// GET /api/user/:id/following?page=1&rows=20
+func (ctrl *FollowController) Following(c *gin.Context) {
+	userId, page, rows, ok := followListParams(c)
+	if !ok {
+		return
+	}
+	users, total, err := ctrl.followService.Following(userId, page, rows)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("查询失败"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("users", users).Put("total", total))
+}
+
+// GET /api/user/feed?cursor=&rows=10
+// 关注的作者发表的文章，游标翻页：下一页把返回的 nextCursor 原样传回，nextCursor 为空表示到底了
+func (ctrl *FollowController) Feed(c *gin.Context) {
+	rows, _ := strconv.Atoi(c.DefaultQuery("rows", "10"))
+	if rows <= 0 || rows > 50 {
+		rows = 10
+	}
+
+	feed, err := ctrl.followService.Feed(c.GetInt("userId"), c.Query("cursor"), rows)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("articles", feed.Articles).Put("nextCursor", feed.NextCursor))
+}
+
+// --- Helper Functions ---
+
+// 解析 :id 和分页参数，出错时已写好响应
+func followListParams(c *gin.Context) (userId, page, rows int, ok bool) {
+	userId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userId <= 0 {
+		c.JSON(http.StatusOK, utils.Error("ID必须是数字"))
+		return 0, 0, 0, false
+	}
+	page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	rows, _ = strconv.Atoi(c.DefaultQuery("rows", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if rows <= 0 || rows > 100 {
+		rows = 20
+	}
+	return userId, page, rows, true
+}
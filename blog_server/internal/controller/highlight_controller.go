@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 代码高亮主题 (文章正文由服务端高亮，前端只需要引入对应主题的 CSS)
+type HighlightController struct{}
+
+func NewHighlightController() *HighlightController {
+	return &HighlightController{}
+}
+
+// GET /api/article/highlight.css?style=github
+// 前端用法: <link rel="stylesheet" href="/api/article/highlight.css?style=monokai">
+func (ctrl *HighlightController) CSS(c *gin.Context) {
+	css, err := service.HighlightCSS(c.DefaultQuery("style", service.DefaultHighlightStyle))
+	if err != nil {
+		c.String(http.StatusNotFound, "/* %s */", err.Error())
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, "text/css; charset=utf-8", []byte(css))
+}
+
+// GET /api/article/highlight/styles
+func (ctrl *HighlightController) Styles(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.Ok().
+		Put("styles", service.HighlightStyles()).
+		Put("default", service.DefaultHighlightStyle))
+}
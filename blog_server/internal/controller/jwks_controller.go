@@ -0,0 +1,16 @@
+package controller
+
+import (
+	"my-blog/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 公开 JWT 校验公钥 (JWKS)，其他内部服务据此校验本站签发的 Access Token
+// GET /.well-known/jwks.json
+// 返回标准 JWKS 格式 (不包装 utils.Result)，HS256 密钥不会出现在这里
+func Jwks(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.JWKS())
+}
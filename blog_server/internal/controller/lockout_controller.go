@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 登录锁定管理 (管理员)
+type LockoutController struct {
+	loginGuard service.LoginGuardService
+}
+
+func NewLockoutController(loginGuard service.LoginGuardService) *LockoutController {
+	return &LockoutController{loginGuard: loginGuard}
+}
+
+// GET /api/admin/lockout/list
+func (ctrl *LockoutController) List(c *gin.Context) {
+	list, err := ctrl.loginGuard.ListLockouts()
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("lockouts", list))
+}
+
+// POST /api/admin/lockout/clear
+// 前端传参: { "username": "xxx" }
+func (ctrl *LockoutController) Clear(c *gin.Context) {
+	var dto struct {
+		Username string `json:"username"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	if err := ctrl.loginGuard.ClearLockout(dto.Username); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "已解除锁定"))
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 两步验证 (TOTP) 管理
+type MfaController struct {
+	mfaService   service.MfaService
+	userService  service.UserService
+	tokenService service.TokenService
+}
+
+func NewMfaController(mfaService service.MfaService, userService service.UserService, tokenService service.TokenService) *MfaController {
+	return &MfaController{
+		mfaService:   mfaService,
+		userService:  userService,
+		tokenService: tokenService,
+	}
+}
+
+// 前端统一传参: { "code": "123456" } (6位验证码，部分接口也接受恢复码)
+type mfaCodeDTO struct {
+	Code string `json:"code"`
+}
+
+// GET /api/user/mfa/status
+func (ctrl *MfaController) Status(c *gin.Context) {
+	status, err := ctrl.mfaService.Status(c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("mfa", status))
+}
+
+// POST /api/user/mfa/setup
+// 返回密钥和二维码，用户用 App 扫码后调用 enable 确认
+func (ctrl *MfaController) Setup(c *gin.Context) {
+	setup, err := ctrl.mfaService.Setup(c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("setup", setup))
+}
+
+// POST /api/user/mfa/enable
+// 校验通过后返回恢复码 (只展示这一次)，并给当前会话换发已完成两步验证的 Token
+func (ctrl *MfaController) Enable(c *gin.Context) {
+	var dto mfaCodeDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	userId := c.GetInt("userId")
+	codes, err := ctrl.mfaService.Enable(userId, dto.Code)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+
+	res := utils.Ok().Put("msg", "两步验证已开启").Put("recoveryCodes", codes)
+
+	// 当前会话升级 (之前因未开启两步验证而暂扣的角色此时恢复)
+	if user, err := ctrl.userService.GetUserDetail(userId); err == nil {
+		if pair, err := ctrl.tokenService.UpgradeSession(user, c.GetString("sessionId")); err == nil {
+			res.Put("user", user).
+				Put("token", pair.AccessToken).
+				Put("refreshToken", pair.RefreshToken).
+				Put("expiresIn", pair.ExpiresIn)
+		}
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// POST /api/user/mfa/disable
+func (ctrl *MfaController) Disable(c *gin.Context) {
+	var dto mfaCodeDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	if err := ctrl.mfaService.Disable(c.GetInt("userId"), dto.Code); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "两步验证已关闭"))
+}
+
+// POST /api/user/mfa/recoveryCodes
+// 重新生成恢复码，旧的全部作废
+func (ctrl *MfaController) RegenerateRecoveryCodes(c *gin.Context) {
+	var dto mfaCodeDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	codes, err := ctrl.mfaService.RegenerateRecoveryCodes(c.GetInt("userId"), dto.Code)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("recoveryCodes", codes))
+}
+
+// POST /api/admin/user/mfa/reset (管理员)
+// 前端传参: { "userId": 1 }，用于用户丢失手机且没有恢复码的情况
+func (ctrl *MfaController) Reset(c *gin.Context) {
+	var dto struct {
+		UserId int `json:"userId"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.UserId <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	if err := ctrl.mfaService.Reset(dto.UserId); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "已重置该用户的两步验证"))
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"my-blog/config"
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 第三方登录 (OAuth2 / OpenID Connect)
+type OAuthController struct {
+	oauthService service.OAuthService
+	userService  service.UserService
+}
+
+func NewOAuthController(oauthService service.OAuthService, userService service.UserService) *OAuthController {
+	return &OAuthController{oauthService: oauthService, userService: userService}
+}
+
+// GET /api/oauth/providers
+// 登录页据此渲染第三方登录按钮
+func (ctrl *OAuthController) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.Ok().Put("providers", ctrl.oauthService.Providers()))
+}
+
+// GET /api/oauth/:provider/authUrl
+// 返回第三方授权页地址，前端 window.location 跳转
+func (ctrl *OAuthController) AuthURL(c *gin.Context) {
+	authURL, err := ctrl.oauthService.AuthURL(c.Param("provider"), 0)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("url", authURL))
+}
+
+// GET /api/user/oauth/:provider/bindUrl (需要登录)
+// 已登录用户绑定第三方账号
+func (ctrl *OAuthController) BindURL(c *gin.Context) {
+	authURL, err := ctrl.oauthService.AuthURL(c.Param("provider"), c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("url", authURL))
+}
+
+// GET /api/oauth/:provider/callback
+// 第三方授权后浏览器跳回这里，处理完再重定向到前端页面：
+// 登录 -> ?ticket=xxx (前端调用 /api/oauth/login 换 Token)；绑定 -> ?bound=provider；失败 -> ?error=原因
+func (ctrl *OAuthController) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+	params := url.Values{}
+
+	if errMsg := c.Query("error"); errMsg != "" {
+		// 用户在第三方页面点了取消
+		params.Set("error", "已取消授权")
+	} else if ticket, bound, err := ctrl.oauthService.Callback(provider, c.Query("code"), c.Query("state")); err != nil {
+		params.Set("error", err.Error())
+	} else if bound {
+		params.Set("bound", provider)
+	} else {
+		params.Set("ticket", ticket)
+	}
+
+	redirect := config.Config.OAuth.FrontendRedirect
+	if redirect == "" {
+		redirect = "http://localhost:5173/oauth/callback"
+	}
+	c.Redirect(http.StatusFound, redirect+"?"+params.Encode())
+}
+
+// POST /api/oauth/login
+// 前端传参: { "ticket": "..." }，返回格式与 /api/login 相同
+func (ctrl *OAuthController) Login(c *gin.Context) {
+	var dto struct {
+		Ticket string `json:"ticket"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	result, err := ctrl.userService.LoginOAuth(dto.Ticket, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, loginResponse(result))
+}
+
+// GET /api/user/identities
+func (ctrl *OAuthController) ListIdentities(c *gin.Context) {
+	list, err := ctrl.oauthService.ListIdentities(c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("获取绑定信息失败"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().
+		Put("identities", list).
+		Put("providers", ctrl.oauthService.Providers()))
+}
+
+// POST /api/user/identity/unbind
+// 前端传参: { "provider": "github" }
+func (ctrl *OAuthController) Unbind(c *gin.Context) {
+	var dto struct {
+		Provider string `json:"provider"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	if err := ctrl.oauthService.Unbind(c.GetInt("userId"), dto.Provider); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "已解绑"))
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"encoding/json"
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 通行密钥 (Passkey / WebAuthn)
+type PasskeyController struct {
+	passkeyService service.PasskeyService
+	userService    service.UserService
+}
+
+func NewPasskeyController(passkeyService service.PasskeyService, userService service.UserService) *PasskeyController {
+	return &PasskeyController{passkeyService: passkeyService, userService: userService}
+}
+
+// POST /api/passkey/login/begin
+// 返回 navigator.credentials.get() 的参数 (options) 和 loginKey
+func (ctrl *PasskeyController) LoginBegin(c *gin.Context) {
+	options, loginKey, err := ctrl.passkeyService.BeginLogin()
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("操作失败，请稍后重试"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("options", options).Put("loginKey", loginKey))
+}
+
+// POST /api/passkey/login/finish
+// 前端传参: { "loginKey": "...", "credential": {浏览器返回的 PublicKeyCredential} }
+// 返回格式与 /api/login 相同
+func (ctrl *PasskeyController) LoginFinish(c *gin.Context) {
+	var dto struct {
+		LoginKey   string          `json:"loginKey"`
+		Credential json.RawMessage `json:"credential"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	result, err := ctrl.userService.LoginPasskey(dto.LoginKey, dto.Credential, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, loginResponse(result))
+}
+
+// POST /api/user/passkey/register/begin
+// 返回 navigator.credentials.create() 的参数
+func (ctrl *PasskeyController) RegisterBegin(c *gin.Context) {
+	options, err := ctrl.passkeyService.BeginRegistration(c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("options", options))
+}
+
+// POST /api/user/passkey/register/finish
+// 前端传参: { "name": "我的手机", "credential": {浏览器返回的 PublicKeyCredential} }
+func (ctrl *PasskeyController) RegisterFinish(c *gin.Context) {
+	var dto struct {
+		Name       string          `json:"name"`
+		Credential json.RawMessage `json:"credential"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil || len(dto.Credential) == 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	passkey, err := ctrl.passkeyService.FinishRegistration(c.GetInt("userId"), dto.Name, dto.Credential)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "通行密钥已添加").Put("passkey", passkey))
+}
+
+// GET /api/user/passkey/list
+func (ctrl *PasskeyController) List(c *gin.Context) {
+	list, err := ctrl.passkeyService.List(c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("获取通行密钥失败"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("passkeys", list))
+}
+
+// POST /api/user/passkey/rename
+// 前端传参: { "id": 1, "name": "新名称" }
+func (ctrl *PasskeyController) Rename(c *gin.Context) {
+	var dto struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	if err := ctrl.passkeyService.Rename(c.GetInt("userId"), dto.Id, dto.Name); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "修改成功"))
+}
+
+// POST /api/user/passkey/remove
+// 前端传参: { "id": 1 }
+func (ctrl *PasskeyController) Remove(c *gin.Context) {
+	var dto struct {
+		Id int `json:"id"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	if err := ctrl.passkeyService.Remove(c.GetInt("userId"), dto.Id); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "已删除"))
+}
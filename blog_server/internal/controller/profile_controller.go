@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"my-blog/internal/model"
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 个人主页 (公开) 与公开资料修改
+type ProfileController struct {
+	profileService service.ProfileService
+}
+
+func NewProfileController(profileService service.ProfileService) *ProfileController {
+	return &ProfileController{profileService: profileService}
+}
+
+// GET /api/user/:id/profile?page=1&rows=10
+// 公开资料 + 统计 (文章数、收到的点赞、评论数、加入时间) + 文章分页，不返回邮箱
+func (ctrl *ProfileController) GetProfile(c *gin.Context) {
+	userId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userId <= 0 {
+		c.JSON(http.StatusOK, utils.Error("ID必须是数字"))
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	rows, _ := strconv.Atoi(c.DefaultQuery("rows", "10"))
+	if page <= 0 {
+		page = 1
+	}
+	if rows <= 0 || rows > 50 {
+		rows = 10
+	}
+
+	profile, err := ctrl.profileService.GetProfile(userId, page, rows)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().
+		Put("user", profile.User).
+		Put("stats", profile.Stats).
+		Put("articles", profile.Articles).
+		Put("total", profile.ArticleTotal))
+}
+
+// POST /api/user/profile/update
+// 前端传参: { "nickname": "", "bio": "", "website": "https://...", "location": "", "cover": "", "socialLinks": [{"platform":"github","url":"https://github.com/xxx"}] }
+func (ctrl *ProfileController) UpdateProfile(c *gin.Context) {
+	var form model.ProfileForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数格式错误"))
+		return
+	}
+
+	user, err := ctrl.profileService.UpdateProfile(c.GetInt("userId"), &form)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("user", user).Put("msg", "资料已保存"))
+}
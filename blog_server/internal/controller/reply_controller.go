@@ -21,7 +21,8 @@ func NewReplyController(svc service.ReplyService) *ReplyController {
 // 严格对应: GetReplies
 func (ctrl *ReplyController) GetReplies(c *gin.Context) {
 	commentId, _ := strconv.Atoi(c.Query("commentId"))
-	replies, err := ctrl.replyService.GetReplies(commentId)
+	// [MODIFY] 登录用户看不到自己拉黑 / 屏蔽的用户的回复
+	replies, err := ctrl.replyService.GetReplies(commentId, c.GetInt("userId"))
 	if err != nil {
 		c.JSON(http.StatusOK, utils.Error("获取回复失败"))
 		return
@@ -76,3 +77,18 @@ func (ctrl *ReplyController) LikeReply(c *gin.Context) {
 	res.Msg = msg
 	c.JSON(http.StatusOK, res)
 }
+
+// [NEW] 删除回复 /api/reply/deleteById?id=
+// 只能删除自己的回复 (管理员除外)
+func (ctrl *ReplyController) DeleteReply(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Query("id"))
+	if id <= 0 {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+	if err := ctrl.replyService.DeleteReply(id, currentOperator(c)); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "删除成功"))
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"my-blog/internal/service"
+	"my-blog/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleController struct {
+	roleService service.RoleService
+}
+
+func NewRoleController(roleService service.RoleService) *RoleController {
+	return &RoleController{roleService: roleService}
+}
+
+// GET /api/admin/role/list
+func (ctrl *RoleController) List(c *gin.Context) {
+	roles, err := ctrl.roleService.GetAllRoles()
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("roles", roles))
+}
+
+// GET /api/admin/user/roles?userId=
+func (ctrl *RoleController) GetUserRoles(c *gin.Context) {
+	userId, _ := strconv.Atoi(c.Query("userId"))
+
+	roles, perms, err := ctrl.roleService.GetUserAuthorities(userId)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("roles", roles).Put("permissions", perms))
+}
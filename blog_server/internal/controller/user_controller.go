@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"my-blog/config"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"strings" // [NEW] 用于转小写
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/mojocn/base64Captcha"
 )
 
@@ -22,20 +24,12 @@ var store = base64Captcha.DefaultMemStore
 
 type UserController struct {
 	userService service.UserService
+	// [NEW] Token 刷新、吊销
+	tokenService service.TokenService
 }
 
-func NewUserController(userService service.UserService) *UserController {
-	return &UserController{userService: userService}
-}
-
-// ListUsers 对应 GET /api/users
-func (ctrl *UserController) ListUsers(c *gin.Context) {
-	users, err := ctrl.userService.GetAllUsers()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "data": users, "msg": "success"})
+func NewUserController(userService service.UserService, tokenService service.TokenService) *UserController {
+	return &UserController{userService: userService, tokenService: tokenService}
 }
 
 // GetUser 对应 GET /api/user/:id
@@ -52,27 +46,66 @@ func (ctrl *UserController) GetUser(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "用户不存在"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "data": user, "msg": "success"})
+	// [MODIFY] 公开接口，只返回公开资料 (不含邮箱等)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": user.Public(), "msg": "success"})
 }
 
 // [NEW] 获取当前用户信息
 // 前端刷新页面后，可能会调这个接口来维持登录状态
+// [MODIFY] 返回 Token 对应的真实用户及其角色
 func (ctrl *UserController) CurrentUser(c *gin.Context) {
-	// 同样，直接返回那个管理员用户
-	adminUser := model.User{
-		Id:       1,
-		Username: "admin",
-		Avatar:   "/api/images/6.png",
+	user, err := ctrl.userService.GetUserDetail(c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("用户不存在"))
+		return
 	}
+	service.FillAuthorities(user, c.GetStringSlice("roles"))
 
-	c.JSON(http.StatusOK, utils.Ok().Put("user", adminUser))
+	c.JSON(http.StatusOK, utils.Ok().Put("user", user))
 }
 
 // [NEW] 退出登录
+// [MODIFY] 服务端删除当前会话，Access Token 和 Refresh Token 一并失效
 func (ctrl *UserController) Logout(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	mapClaims, _ := claims.(jwt.MapClaims)
+	ctrl.tokenService.Logout(mapClaims)
+
 	c.JSON(http.StatusOK, utils.Ok().Put("msg", "退出成功"))
 }
 
+// [NEW] 退出所有设备 (/api/logoutAll)，个人访问令牌一并作废
+func (ctrl *UserController) LogoutAll(c *gin.Context) {
+	if err := ctrl.tokenService.RevokeAllCredentials(c.GetInt("userId")); err != nil {
+		c.JSON(http.StatusOK, utils.Error("操作失败"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "已退出所有设备，API Token 已全部作废"))
+}
+
+// [NEW] 刷新 Token (/api/token/refresh)
+// 前端在 Access Token 过期 (401) 时调用，旧 Refresh Token 会被轮换作废
+func (ctrl *UserController) RefreshToken(c *gin.Context) {
+	var dto struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数格式错误"))
+		return
+	}
+
+	pair, err := ctrl.tokenService.Refresh(dto.RefreshToken, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Ok().
+		Put("token", pair.AccessToken).
+		Put("refreshToken", pair.RefreshToken).
+		Put("expiresIn", pair.ExpiresIn))
+}
+
 // [NEW] 获取图形验证码 (/api/user/captcha)
 // [MODIFY] 获取图形验证码 (/api/user/captcha)
 func (ctrl *UserController) Captcha(c *gin.Context) {
@@ -172,6 +205,54 @@ func (ctrl *UserController) SendEmailCode(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.Ok().Put("msg", "验证码发送成功"))
 }
 
+// [NEW] 发送邮件登录链接 (/api/user/sendMagicLink)
+// 前端传参: { "email": "xxx", "captcha": "abcd", "captchaKey": "..." }
+func (ctrl *UserController) SendMagicLink(c *gin.Context) {
+	var dto struct {
+		Email      string `json:"email"`
+		Captcha    string `json:"captcha"`
+		CaptchaKey string `json:"captchaKey"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+	if dto.Email == "" {
+		c.JSON(http.StatusOK, utils.Error("邮箱不能为空"))
+		return
+	}
+	if dto.Captcha == "" || dto.CaptchaKey == "" {
+		c.JSON(http.StatusOK, utils.Error("请输入图形验证码"))
+		return
+	}
+
+	err := ctrl.userService.SendMagicLink(dto.Email, strings.ToLower(dto.Captcha), dto.CaptchaKey, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "如果该邮箱已注册，登录链接已发送，请查收"))
+}
+
+// [NEW] 邮件链接登录 (/api/login/magic)
+// 前端传参: { "token": "链接中的 token" }，返回格式与 /api/login 相同
+func (ctrl *UserController) LoginMagicLink(c *gin.Context) {
+	var dto struct {
+		Token string `json:"token"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	result, err := ctrl.userService.LoginMagicLink(dto.Token, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, loginResponse(result))
+}
+
 // [NEW] 注册接口 (/api/user/register)
 func (ctrl *UserController) Register(c *gin.Context) {
 	// 接收 JSON 参数
@@ -196,7 +277,7 @@ func (ctrl *UserController) Register(c *gin.Context) {
 	// 调用 Service
 	msg, err := ctrl.userService.Register(user, dto.Code)
 	if err != nil {
-		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		c.JSON(http.StatusOK, passwordErrorResult(err))
 		return
 	}
 
@@ -210,16 +291,23 @@ func (ctrl *UserController) Login(c *gin.Context) {
 	// 也可以兼容 JSON
 	username := c.PostForm("username")
 	password := c.PostForm("password")
+	// [NEW] 失败次数过多后需要图形验证码
+	captcha := c.PostForm("captcha")
+	captchaKey := c.PostForm("captchaKey")
 
 	// 如果 PostForm 没拿到，试试 JSON (兼容性)
 	if username == "" {
 		var dto struct {
-			Username string `json:"username"`
-			Password string `json:"password"`
+			Username   string `json:"username"`
+			Password   string `json:"password"`
+			Captcha    string `json:"captcha"`
+			CaptchaKey string `json:"captchaKey"`
 		}
 		c.ShouldBindJSON(&dto)
 		username = dto.Username
 		password = dto.Password
+		captcha = dto.Captcha
+		captchaKey = dto.CaptchaKey
 	}
 
 	if username == "" || password == "" {
@@ -227,38 +315,37 @@ func (ctrl *UserController) Login(c *gin.Context) {
 		return
 	}
 
-	user, token, err := ctrl.userService.Login(username, password)
+	result, err := ctrl.userService.Login(username, password, strings.ToLower(captcha), captchaKey, clientInfo(c))
 	if err != nil {
 		// 返回格式必须符合 Java 前端预期
-		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		// [NEW] needCaptcha=true 时前端需要展示图形验证码
+		c.JSON(http.StatusOK, utils.Error(err.Error()).
+			Put("needCaptcha", ctrl.userService.NeedCaptcha(username, c.ClientIP())))
 		return
 	}
 
-	// ---------------------------------------------------------
-	// [NEW] 核心修复：手动填充权限
-	// ---------------------------------------------------------
-	// 模拟 Spring Security 的 GrantedAuthority 结构
-	// 如果是 admin 用户，给 admin 权限；否则给 common 权限
-	role := "ROLE_common"
-	if user.Username == "admin" {
-		role = "ROLE_admin"
-	}
+	c.JSON(http.StatusOK, loginResponse(result))
+}
 
-	user.Authorities = []map[string]string{
-		{"authority": role}, // 结构必须是 {"authority": "ROLE_xxx"}
+// [NEW] 登录第二步 (/api/login/mfa)
+// 参数: mfaToken (第一步返回), code (6位验证码或恢复码)
+func (ctrl *UserController) LoginMfa(c *gin.Context) {
+	var dto struct {
+		MfaToken string `json:"mfaToken"`
+		Code     string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
 	}
-	// ---------------------------------------------------------
-
-	// 构造返回数据 (完全复刻 Java MyAuthenticationSuccessHandler)
-	res := utils.Ok()
-	res.Put("msg", "登录成功")
-	res.Put("user", user)   // 放入 User 对象
-	res.Put("token", token) // 额外给一个 Token (虽然 Java 前端可能只用 user)
 
-	// ⚠️ 关键：Java 前端可能依赖 authorites 字段
-	// 你可以在 user model 里加个 Authorities []string 字段并填充
+	result, err := ctrl.userService.LoginMfa(dto.MfaToken, dto.Code, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
 
-	c.JSON(http.StatusOK, res)
+	c.JSON(http.StatusOK, loginResponse(result))
 }
 
 // [NEW] 重置密码接口 (/api/user/resetPassword)
@@ -278,7 +365,7 @@ func (ctrl *UserController) ResetPassword(c *gin.Context) {
 
 	msg, err := ctrl.userService.ResetPassword(dto.Username, dto.Email, dto.Password, dto.Code)
 	if err != nil {
-		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		c.JSON(http.StatusOK, passwordErrorResult(err))
 		return
 	}
 
@@ -307,6 +394,44 @@ func (ctrl *UserController) UpdateUser(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
+// [NEW] 修改邮箱第一步：向新邮箱发送验证码 (/api/user/email/sendCode)
+// 前端传参: { "email": "new@xxx.com" }
+func (ctrl *UserController) SendChangeEmailCode(c *gin.Context) {
+	var dto struct {
+		Email string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.Email == "" {
+		c.JSON(http.StatusOK, utils.Error("请输入新邮箱"))
+		return
+	}
+
+	if err := ctrl.userService.SendChangeEmailCode(c.GetInt("userId"), dto.Email); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "验证码已发送到新邮箱"))
+}
+
+// [NEW] 修改邮箱第二步：提交验证码，确认后才真正修改 (/api/user/email/change)
+// 前端传参: { "email": "new@xxx.com", "code": "123456" }
+func (ctrl *UserController) ChangeEmail(c *gin.Context) {
+	var dto struct {
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.Email == "" || dto.Code == "" {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	user, err := ctrl.userService.ChangeEmail(c.GetInt("userId"), dto.Email, dto.Code)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "邮箱修改成功").Put("email", user.Email))
+}
+
 // [NEW] 修改密码 (/api/user/updatePassword)
 func (ctrl *UserController) UpdatePassword(c *gin.Context) {
 	// 前端通常传: oldPwd, newPwd
@@ -321,9 +446,83 @@ func (ctrl *UserController) UpdatePassword(c *gin.Context) {
 
 	userId := c.GetInt("userId")
 	if err := ctrl.userService.UpdatePassword(userId, dto.OldPwd, dto.NewPwd); err != nil {
-		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		c.JSON(http.StatusOK, passwordErrorResult(err))
 		return
 	}
 
 	c.JSON(http.StatusOK, utils.Ok().Put("msg", "密码修改成功"))
 }
+
+// [NEW] 密码强度要求 (/api/user/passwordPolicy)，注册、重置密码页面展示用
+func (ctrl *UserController) PasswordPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.Ok().Put("policy", config.PasswordPolicy()))
+}
+
+// [NEW] 登录设备列表 (/api/user/sessions)
+func (ctrl *UserController) ListSessions(c *gin.Context) {
+	sessions, err := ctrl.tokenService.ListSessions(c.GetInt("userId"), c.GetString("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusOK, utils.Error("获取登录设备失败"))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("sessions", sessions))
+}
+
+// [NEW] 下线某台设备 (/api/user/session/revoke)
+func (ctrl *UserController) RevokeSession(c *gin.Context) {
+	var dto struct {
+		SessionId string `json:"sessionId"`
+	}
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.SessionId == "" {
+		c.JSON(http.StatusOK, utils.Error("参数错误"))
+		return
+	}
+
+	if err := ctrl.tokenService.RevokeSession(c.GetInt("userId"), dto.SessionId); err != nil {
+		c.JSON(http.StatusOK, utils.Error(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.Ok().Put("msg", "设备已下线"))
+}
+
+// [NEW] 提取客户端信息 (IP、User-Agent)，用于记录登录设备
+// [NEW] 登录成功的返回数据 (完全复刻 Java MyAuthenticationSuccessHandler)
+// [NEW] 密码不符合策略时附带错误码 (map.code)，前端据此提示
+func passwordErrorResult(err error) *utils.Result {
+	res := utils.Error(err.Error())
+	var pwdErr *utils.PasswordError
+	if errors.As(err, &pwdErr) {
+		res.Put("code", pwdErr.Code)
+	}
+	return res
+}
+
+func loginResponse(result *service.LoginResult) *utils.Result {
+	// 开启了两步验证：前端展示验证码输入框，再调用 /api/login/mfa
+	if result.MfaToken != "" {
+		return utils.Ok().
+			Put("msg", "请输入两步验证码").
+			Put("mfaRequired", true).
+			Put("mfaToken", result.MfaToken)
+	}
+
+	res := utils.Ok()
+	res.Put("msg", "登录成功")
+	res.Put("user", result.User)                // 放入 User 对象
+	res.Put("token", result.Tokens.AccessToken) // 额外给一个 Token (虽然 Java 前端可能只用 user)
+	// [NEW] 短期 Access Token 过期后用 refreshToken 换新
+	res.Put("refreshToken", result.Tokens.RefreshToken)
+	res.Put("expiresIn", result.Tokens.ExpiresIn)
+	// [NEW] 角色要求两步验证但尚未开启时提示前端引导开启
+	if result.Tokens.MfaSetupRequired {
+		res.Put("mfaSetupRequired", true)
+	}
+	return res
+}
+
+func clientInfo(c *gin.Context) *model.ClientInfo {
+	return &model.ClientInfo{
+		Ip:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
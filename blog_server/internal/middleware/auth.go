@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"errors"
+	"my-blog/internal/model"
+	"my-blog/internal/service"
 	"my-blog/pkg/utils"
 	"net/http"
 	"strings"
@@ -9,43 +12,96 @@ import (
 )
 
 // [NEW] JWT 认证中间件
-func Auth() gin.HandlerFunc {
+// [MODIFY] 注入 TokenService，拒绝已吊销会话的 Token (退出登录、踢设备、修改密码后)
+// [MODIFY] 同时接受个人访问令牌 (blog_pat_ 开头)；accessTokenService 为 nil 时只接受登录 Token
+// 使用个人访问令牌的接口必须再挂 RequireScope 声明所需授权范围
+func Auth(tokenService service.TokenService, accessTokenService service.AccessTokenService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 1. 获取 Authorization Header
-		tokenStr := c.GetHeader("Authorization")
-		if tokenStr == "" {
-			// 尝试从 query 中获取 (可选兼容)
-			tokenStr = c.Query("token")
-		}
-
-		// 2. 简单处理 Bearer 前缀 (如果有)
-		if strings.HasPrefix(tokenStr, "Bearer ") {
-			tokenStr = tokenStr[7:]
-		}
-
-		if tokenStr == "" {
-			c.JSON(http.StatusUnauthorized, utils.Error("请先登录"))
+		if err := authenticate(c, tokenService, accessTokenService); err != nil {
+			c.JSON(http.StatusUnauthorized, utils.Error(err.Error()))
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
 
-		// 3. 解析 Token
-		claims, err := utils.ParseToken(tokenStr)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, utils.Error("登录已过期，请重新登录"))
-			c.Abort()
-			return
-		}
+// [NEW] 可选登录中间件：与 Auth 做同样的校验 (会话已吊销、账号被封禁的 Token 同样无效)
+// 但不拦截请求，未登录或校验失败时按游客处理 (Context 里没有 userId，c.GetInt("userId") 为 0)
+// 用于"是否点赞"、隐藏拉黑的用户的评论、作者查看自己的草稿等公开接口
+func OptionalAuth(tokenService service.TokenService, accessTokenService service.AccessTokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticate(c, tokenService, accessTokenService)
+		c.Next()
+	}
+}
+
+// --- Helper Functions ---
 
-		// 4. 将用户信息存入 Context
-		// 注意：JWT 解析出的数字默认是 float64
-		if userIdFloat, ok := claims["userId"].(float64); ok {
-			c.Set("userId", int(userIdFloat))
+// 校验请求携带的 Token，通过后把用户信息存入 Context
+func authenticate(c *gin.Context, tokenService service.TokenService, accessTokenService service.AccessTokenService) error {
+	// 1. 获取 Authorization Header
+	tokenStr := c.GetHeader("Authorization")
+	fromQuery := false
+	if tokenStr == "" {
+		// 尝试从 query 中获取 (可选兼容)
+		tokenStr = c.Query("token")
+		fromQuery = true
+	}
+
+	// 2. 简单处理 Bearer 前缀 (如果有)
+	if strings.HasPrefix(tokenStr, "Bearer ") {
+		tokenStr = tokenStr[7:]
+	}
+
+	if tokenStr == "" {
+		return errors.New("请先登录")
+	}
+
+	// [NEW] 个人访问令牌 (只允许放在 Header 中，避免出现在访问日志里)
+	if strings.HasPrefix(tokenStr, model.AccessTokenPrefix) {
+		if accessTokenService == nil || fromQuery {
+			return errors.New("该接口不支持使用 API Token")
 		}
-		if username, ok := claims["username"].(string); ok {
-			c.Set("username", username)
+		identity, err := accessTokenService.Authenticate(tokenStr, c.ClientIP())
+		if err != nil {
+			return err
 		}
+		c.Set("userId", identity.User.Id)
+		c.Set("username", identity.User.Username)
+		c.Set("accessTokenId", identity.Token.Id)
+		c.Set("scopes", identity.Token.ScopeList())
+		c.Set("roles", identity.Roles)
+		c.Set("permissions", identity.Permissions)
+		return nil
+	}
 
-		c.Next()
+	// 3. 解析 Token
+	claims, err := utils.ParseToken(tokenStr)
+	if err != nil {
+		return errors.New("登录已过期，请重新登录")
+	}
+
+	// [NEW] 检查所属会话是否还有效 (同时刷新最后活跃时间)
+	// [MODIFY] 账号被封禁时同样拒绝
+	if err := tokenService.ValidateSession(claims, c.ClientIP()); err != nil {
+		return err
+	}
+
+	// 4. 将用户信息存入 Context
+	c.Set("claims", claims)
+	// 注意：JWT 解析出的数字默认是 float64
+	if userIdFloat, ok := claims["userId"].(float64); ok {
+		c.Set("userId", int(userIdFloat))
+	}
+	if username, ok := claims["username"].(string); ok {
+		c.Set("username", username)
+	}
+	if sid, ok := claims["sid"].(string); ok {
+		c.Set("sessionId", sid)
 	}
+	// [NEW] 角色与权限 (供 RequireRole / RequirePermission 使用)
+	c.Set("roles", utils.ClaimStrings(claims, "roles"))
+	c.Set("permissions", utils.ClaimStrings(claims, "perms"))
+	return nil
 }
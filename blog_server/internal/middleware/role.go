@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"my-blog/pkg/utils"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// [NEW] 角色校验中间件 (必须挂在 Auth 之后)
+// 用户拥有任意一个指定角色即可放行
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		owned := c.GetStringSlice("roles")
+		for _, role := range roles {
+			if slices.Contains(owned, role) {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, utils.Error("权限不足"))
+		c.Abort()
+	}
+}
+
+// [NEW] 权限校验中间件 (必须挂在 Auth 之后)
+// 用户必须拥有全部指定权限才能放行
+func RequirePermission(perms ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		owned := c.GetStringSlice("permissions")
+		for _, perm := range perms {
+			if !slices.Contains(owned, perm) {
+				c.JSON(http.StatusForbidden, utils.Error("权限不足"))
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// [NEW] 个人访问令牌授权范围校验 (必须挂在 Auth 之后)
+// 登录 Token 不受限制；个人访问令牌必须拥有全部指定范围
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetInt("accessTokenId") == 0 {
+			c.Next()
+			return
+		}
+		owned := c.GetStringSlice("scopes")
+		for _, scope := range scopes {
+			if !slices.Contains(owned, scope) {
+				c.JSON(http.StatusForbidden, utils.Error("API Token 缺少授权范围: "+scope))
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// 个人访问令牌 (API Token) 的前缀，Auth 中间件据此区分登录 Token
+const AccessTokenPrefix = "blog_pat_"
+
+// 个人访问令牌的授权范围 (scope)
+const (
+	ScopeArticlesRead  = "articles:read"  // 读取我的文章
+	ScopeArticlesWrite = "articles:write" // 发布、编辑、删除文章，上传图片
+	ScopeCommentsRead  = "comments:read"  // 读取我的评论
+	ScopeCommentsWrite = "comments:write" // 发表评论、回复
+)
+
+// AccessToken 个人访问令牌，用于脚本、CI 调用接口
+// 对应 t_access_token 表，只保存令牌的 SHA-256
+type AccessToken struct {
+	Id         int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId     int        `gorm:"column:user_id" json:"userId"`
+	Name       string     `gorm:"column:name" json:"name"`
+	TokenHash  string     `gorm:"column:token_hash" json:"-"`
+	Prefix     string     `gorm:"column:prefix" json:"prefix"`        // 令牌开头几位，方便用户辨认 (如 blog_pat_AbCd)
+	Scopes     string     `gorm:"column:scopes" json:"scopes"`        // 逗号分隔
+	ExpiresAt  *time.Time `gorm:"column:expires_at" json:"expiresAt"` // 为空表示永不过期
+	LastUsed   *time.Time `gorm:"column:last_used" json:"lastUsed"`
+	LastUsedIp string     `gorm:"column:last_used_ip" json:"lastUsedIp"`
+	Created    time.Time  `gorm:"column:created" json:"created"`
+}
+
+func (AccessToken) TableName() string {
+	return "t_access_token"
+}
+
+// ScopeList 授权范围列表
+func (t *AccessToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+// Expired 是否已过期
+func (t *AccessToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && now.After(*t.ExpiresAt)
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// [NEW] 注销账号时对已发布内容的处理方式
+const (
+	DeleteModeAnonymize = "anonymize" // 保留已发布的文章、评论和回复，作者改为"已注销用户"；未发布的文章删除
+	DeleteModeRemove    = "remove"    // 连同文章 (及其下的评论)、评论、回复一起删除
+)
+
+// DeletedUserName 匿名化后显示的作者名
+const DeletedUserName = "已注销用户"
+
+// LikeRecord 点赞记录 (导出用)
+type LikeRecord struct {
+	TargetId int       `json:"targetId"` // 文章 / 评论 / 回复的 ID
+	Created  time.Time `json:"created"`
+}
+
+// UserLikes 用户的全部点赞
+type UserLikes struct {
+	Articles []LikeRecord `json:"articles"`
+	Comments []LikeRecord `json:"comments"`
+	Replies  []LikeRecord `json:"replies"`
+}
+
+// UserDataExport 个人数据导出 (我们保存的关于该用户的全部数据)
+// 密码、TOTP 密钥、令牌哈希等凭证不导出
+type UserDataExport struct {
+	ExportedAt    time.Time       `json:"exportedAt"`
+	Profile       *User           `json:"profile"`
+	Identities    []*UserIdentity `json:"identities"`   // 绑定的第三方账号
+	Passkeys      []*Passkey      `json:"passkeys"`     // 通行密钥
+	AccessTokens  []*AccessToken  `json:"accessTokens"` // API 令牌
+	Articles      []Article       `json:"articles"`
+	Comments      []Comment       `json:"comments"`
+	Replies       []Reply         `json:"replies"`
+	Likes         UserLikes       `json:"likes"`
+	Notifications []*Notification `json:"notifications"` // 收到的通知
+	Footprints    []OpLog         `json:"footprints"`    // 足迹 (t_op_log)
+	Following     []*UserFollow   `json:"following"`     // [NEW] 关注的作者
+	Blocks        []*UserBlock    `json:"blocks"`        // [NEW] 拉黑 / 屏蔽的用户
+}
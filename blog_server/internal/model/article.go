@@ -32,6 +32,37 @@ type Article struct {
 
 	// 辅助字段
 	CommentCount int `gorm:"-" json:"commentCount"`
+
+	// [NEW] 发布状态：draft 草稿 / published 已发布 / scheduled 定时发布 / archived 已归档
+	// 只有 published 的文章对作者以外的人可见
+	Status string `gorm:"column:status" json:"status"`
+	// [NEW] 定时发布时间 (status 为 scheduled 时必填)，发布后为实际发布时间
+	PublishAt *time.Time `gorm:"column:publish_at" json:"publishAt"`
+
+	// [NEW] 文章链接 /article/slug/:slug (默认由标题生成，中文转拼音，也可以自定义)
+	Slug string `gorm:"column:slug" json:"slug"`
+
+	// [NEW] 全文搜索时返回的高亮标题和摘要
+	Highlight *ArticleHighlight `gorm:"-" json:"highlight,omitempty"`
+}
+
+// [NEW] 搜索结果高亮：命中的词用 <em></em> 包起来，其余内容已做 HTML 转义
+type ArticleHighlight struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+// [NEW] 文章状态
+const (
+	ArticleStatusDraft     = "draft"
+	ArticleStatusPublished = "published"
+	ArticleStatusScheduled = "scheduled"
+	ArticleStatusArchived  = "archived"
+)
+
+// IsPublished 空状态按已发布处理 (兼容加字段之前的数据)
+func (a *Article) IsPublished() bool {
+	return a.Status == "" || a.Status == ArticleStatusPublished
 }
 
 // [NEW] 文章查询条件 (对应 Java 的 ArticleCondition)
@@ -43,6 +74,27 @@ type ArticleCondition struct {
 
 	// [NEW] 新增用户ID筛选 (用于"我的文章")
 	UserId int `json:"userId"`
+
+	// [NEW] 当前查看的用户：作者本人能看到自己的草稿、定时、归档文章，其他人只能看到已发布的
+	ViewerId int `json:"-"`
+	// [NEW] 按状态筛选 (仅对作者本人的文章生效)，为空时返回全部
+	Status string `json:"status"`
+
+	// [NEW] 全文搜索：在标题、标签、正文里搜 (Title / Content 只搜对应字段)
+	Keyword string `json:"keyword"`
+	// [NEW] 按发布时间筛选，格式 2006-01-02，都包含当天
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+
+	// 由 Service 填充：CategoryId 及其所有子分类，发布时间 [CreatedFrom, CreatedTo)
+	CategoryIds []int      `json:"-"`
+	CreatedFrom *time.Time `json:"-"`
+	CreatedTo   *time.Time `json:"-"`
+}
+
+// [NEW] 是否需要全文搜索
+func (c *ArticleCondition) HasKeyword() bool {
+	return c.Keyword != "" || c.Title != "" || c.Content != ""
 }
 
 // TableName 指定表名为 t_article
@@ -0,0 +1,17 @@
+package model
+
+// [NEW] 文章正文渲染结果 (Markdown -> 过滤后的 HTML)
+type RenderedArticle struct {
+	Html           string     `json:"html"`
+	Toc            []*TocItem `json:"toc"`
+	WordCount      int        `json:"wordCount"`      // 中日韩文字按字数，其他按单词数
+	ReadingMinutes int        `json:"readingMinutes"` // 预计阅读时间 (分钟，至少 1)
+}
+
+// TocItem 目录项，按标题级别嵌套
+type TocItem struct {
+	Level    int        `json:"level"` // 1-6，对应 h1-h6
+	Id       string     `json:"id"`    // 标题的锚点 (#id)
+	Text     string     `json:"text"`
+	Children []*TocItem `json:"children,omitempty"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// [NEW] ArticleRevision 文章的历史版本，对应 t_article_revision 表
+// 每次保存 (发布、编辑、恢复) 都记录一份保存后的快照，version 从 1 开始递增
+type ArticleRevision struct {
+	Id         int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ArticleId  int       `gorm:"column:article_id" json:"articleId"`
+	Version    int       `gorm:"column:version" json:"version"`
+	Title      string    `gorm:"column:title" json:"title"`
+	Content    string    `gorm:"column:content" json:"content,omitempty"` // 列表中不返回正文
+	Tags       string    `gorm:"column:tags" json:"tags"`
+	Categories string    `gorm:"column:categories" json:"categories"`
+	CategoryId int       `gorm:"column:category_id" json:"categoryId"`
+	EditorId   int       `gorm:"column:editor_id" json:"editorId"`
+	EditorName string    `gorm:"column:editor_name" json:"editorName"`
+	Remark     string    `gorm:"column:remark" json:"remark"` // 例如 "恢复自版本 3"
+	Created    time.Time `gorm:"column:created" json:"created"`
+}
+
+func (ArticleRevision) TableName() string {
+	return "t_article_revision"
+}
+
+// NewArticleRevision 根据保存后的文章生成一份快照 (version 由 Repository 分配)
+func NewArticleRevision(article *Article, op *Operator, remark string) *ArticleRevision {
+	return &ArticleRevision{
+		ArticleId:  article.Id,
+		Title:      article.Title,
+		Content:    article.Content,
+		Tags:       article.Tags,
+		Categories: article.Categories,
+		CategoryId: article.CategoryId,
+		EditorId:   op.UserId,
+		EditorName: op.Username,
+		Remark:     remark,
+		Created:    time.Now(),
+	}
+}
+
+// ArticleRevisionDiff 两个版本之间的差异 (unified diff 格式)
+type ArticleRevisionDiff struct {
+	ArticleId int    `json:"articleId"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Diff      string `json:"diff"` // 两个版本相同时为空
+}
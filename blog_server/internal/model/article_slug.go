@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// [NEW] ArticleSlug 文章以前用过的 slug (标题或自定义链接修改后保留，旧链接 301 跳转到新的)
+// 对应 t_article_slug 表，当前的 slug 保存在 t_article.slug
+type ArticleSlug struct {
+	Id        int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ArticleId int       `gorm:"column:article_id" json:"articleId"`
+	Slug      string    `gorm:"column:slug" json:"slug"`
+	Created   time.Time `gorm:"column:created" json:"created"`
+}
+
+func (ArticleSlug) TableName() string {
+	return "t_article_slug"
+}
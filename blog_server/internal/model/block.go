@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// [NEW] 拉黑 / 屏蔽
+// 两种方式都会对屏蔽者隐藏对方的评论和回复，并且不再收到对方触发的通知；
+// 拉黑还会禁止对方回复自己，并解除双方的关注关系
+const (
+	BlockTypeBlock = "block" // 拉黑
+	BlockTypeMute  = "mute"  // 屏蔽 (对方无感知)
+)
+
+// UserBlock 对应 t_user_block 表 (blocker 拉黑 / 屏蔽了 blocked)
+type UserBlock struct {
+	Id        int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	BlockerId int       `gorm:"column:blocker_id" json:"blockerId"`
+	BlockedId int       `gorm:"column:blocked_id" json:"blockedId"`
+	Type      string    `gorm:"column:type" json:"type"` // block / mute
+	Created   time.Time `gorm:"column:created" json:"created"`
+}
+
+func (UserBlock) TableName() string {
+	return "t_user_block"
+}
+
+// BlockedUser 黑名单 / 屏蔽列表中的一项
+type BlockedUser struct {
+	*PublicUser
+	Type      string    `json:"type"`
+	BlockedAt time.Time `json:"blockedAt"`
+}
@@ -26,8 +26,8 @@ type Comment struct {
 	Location string `gorm:"column:location" json:"location"`
 
 	// --- 虚拟字段 ---
-	User      *User    `gorm:"-" json:"user"`
-	ReplyList []*Reply `gorm:"-" json:"replyList"`
+	User      *PublicUser `gorm:"-" json:"user"` // [MODIFY] 只带公开资料，不含邮箱
+	ReplyList []*Reply    `gorm:"-" json:"replyList"`
 
 	// --- [NEW] 新增字段 ---
 	// gorm:"-" 表示这个字段不映射到数据库表列，只用于后端临时存储传给前端
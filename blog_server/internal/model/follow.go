@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// UserFollow 关注关系，对应 t_user_follow 表 (follower 关注了 followee)
+type UserFollow struct {
+	Id         int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	FollowerId int       `gorm:"column:follower_id" json:"followerId"` // 粉丝
+	FolloweeId int       `gorm:"column:followee_id" json:"followeeId"` // 被关注的作者
+	Created    time.Time `gorm:"column:created" json:"created"`
+}
+
+func (UserFollow) TableName() string {
+	return "t_user_follow"
+}
+
+// FollowUser 粉丝 / 关注列表中的一项
+type FollowUser struct {
+	*PublicUser
+	FollowedAt time.Time `json:"followedAt"` // 关注时间
+}
+
+// FeedCursor 关注动态的游标 (上一页最后一篇文章)，按 (created, id) 倒序翻页
+type FeedCursor struct {
+	Created time.Time
+	Id      int
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// LoginLockout 因连续输错密码被临时锁定的账号
+// 存储在 Redis (login_lock:<username>)，不对应数据库表
+type LoginLockout struct {
+	Username string    `json:"username"`
+	Ip       string    `json:"ip"`       // 触发锁定的最后一次请求 IP
+	Failures int       `json:"failures"` // 锁定时累计失败次数
+	LockedAt time.Time `json:"lockedAt"`
+	Until    time.Time `json:"until"`
+}
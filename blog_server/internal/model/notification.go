@@ -22,11 +22,14 @@ type Notification struct {
 	CommentId int `gorm:"column:comment_id" json:"commentId"`
 
 	Content string    `gorm:"column:content" json:"content"`
-	Type    string    `gorm:"column:type" json:"type"`     // COMMENT, REPLY, LIKE
+	Type    string    `gorm:"column:type" json:"type"`     // COMMENT, REPLY, LIKE, FOLLOW_POST
 	Status  int       `gorm:"column:status" json:"status"` // 0:未读 1:已读
 	Created time.Time `gorm:"column:created" json:"created"`
 }
 
+// [NEW] 关注的作者发表了新文章
+const NotifyTypeFollowPost = "FOLLOW_POST"
+
 func (Notification) TableName() string {
 	return "t_notification"
 }
@@ -0,0 +1,32 @@
+package model
+
+import "slices"
+
+// [NEW] Operator 当前操作的用户 (由 Controller 从 middleware.Auth 写入的上下文构造)
+// 文章、评论、回复的编辑和删除只允许作者本人或管理员；评论、回复还允许有评论管理权限的用户删除
+type Operator struct {
+	UserId      int
+	Username    string
+	Roles       []string
+	Permissions []string // [NEW] 权限编码 (JWT 的 perms，或个人访问令牌所属用户的权限)
+}
+
+// IsAdmin 管理员可以编辑、删除任何人的内容
+func (o *Operator) IsAdmin() bool {
+	return slices.Contains(o.Roles, RoleAdmin)
+}
+
+// CanManage 是否可以编辑 / 删除 ownerId 发表的内容
+func (o *Operator) CanManage(ownerId int) bool {
+	return o.IsAdmin() || (o.UserId > 0 && o.UserId == ownerId)
+}
+
+// [NEW] HasPermission 是否拥有某个权限
+func (o *Operator) HasPermission(perm string) bool {
+	return slices.Contains(o.Permissions, perm)
+}
+
+// [NEW] CanManageComment 是否可以删除 ownerId 发表的评论 / 回复 (评论管理员可以删除任何人的)
+func (o *Operator) CanManageComment(ownerId int) bool {
+	return o.CanManage(ownerId) || o.HasPermission(PermCommentManage)
+}
@@ -0,0 +1,35 @@
+package model
+
+import "testing"
+
+func TestOperatorPermissions(t *testing.T) {
+	admin := &Operator{UserId: 1, Roles: []string{RoleAdmin}}
+	moderator := &Operator{UserId: 2, Roles: []string{RoleReader}, Permissions: []string{PermCommentManage}}
+	author := &Operator{UserId: 3, Roles: []string{RoleAuthor}, Permissions: []string{PermArticleWrite, PermArticleDelete}}
+	guest := &Operator{}
+
+	tests := []struct {
+		name          string
+		op            *Operator
+		ownerId       int
+		canManage     bool
+		canDeleteComm bool
+	}{
+		{"admin, other's content", admin, 9, true, true},
+		{"comment manager, other's content", moderator, 9, false, true},
+		{"comment manager, own content", moderator, 2, true, true},
+		{"author, own content", author, 3, true, true},
+		{"author, other's content", author, 9, false, false},
+		{"guest", guest, 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.op.CanManage(tt.ownerId); got != tt.canManage {
+				t.Errorf("CanManage(%d) = %v, want %v", tt.ownerId, got, tt.canManage)
+			}
+			if got := tt.op.CanManageComment(tt.ownerId); got != tt.canDeleteComm {
+				t.Errorf("CanManageComment(%d) = %v, want %v", tt.ownerId, got, tt.canDeleteComm)
+			}
+		})
+	}
+}
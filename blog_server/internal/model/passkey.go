@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// Passkey 用户绑定的 FIDO2 通行密钥 (WebAuthn 凭证)
+// 对应 t_passkey 表，一个用户可以绑定多个 (手机、电脑、安全钥匙)
+type Passkey struct {
+	Id           int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId       int        `gorm:"column:user_id" json:"userId"`
+	Name         string     `gorm:"column:name" json:"name"`                  // 用户自定义的名称，如 "我的 iPhone"
+	CredentialId string     `gorm:"column:credential_id" json:"credentialId"` // 凭证 ID (base64url)，登录时据此查找
+	Credential   string     `gorm:"column:credential" json:"-"`               // webauthn.Credential 序列化后的 JSON (公钥、签名计数等)
+	Created      time.Time  `gorm:"column:created" json:"created"`
+	LastUsed     *time.Time `gorm:"column:last_used" json:"lastUsed"`
+}
+
+func (Passkey) TableName() string {
+	return "t_passkey"
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// PasswordHistory 用过的密码 (bcrypt 哈希)，用于禁止重复使用最近的密码
+// 对应 t_password_history 表，每个用户只保留最近 N 条
+type PasswordHistory struct {
+	Id       int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId   int       `gorm:"column:user_id" json:"userId"`
+	Password string    `gorm:"column:password" json:"-"`
+	Created  time.Time `gorm:"column:created" json:"created"`
+}
+
+func (PasswordHistory) TableName() string {
+	return "t_password_history"
+}
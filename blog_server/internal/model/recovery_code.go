@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// RecoveryCode 两步验证的一次性恢复码 (手机丢失时代替 TOTP 验证码)
+// 对应 t_user_recovery_code 表，只保存 SHA-256 哈希
+type RecoveryCode struct {
+	Id       int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId   int        `gorm:"column:user_id" json:"userId"`
+	CodeHash string     `gorm:"column:code_hash" json:"-"`
+	Used     int        `gorm:"column:used" json:"used"` // 1:已使用
+	UsedAt   *time.Time `gorm:"column:used_at" json:"usedAt"`
+	Created  time.Time  `gorm:"column:created" json:"created"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "t_user_recovery_code"
+}
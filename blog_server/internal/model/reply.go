@@ -22,8 +22,9 @@ type Reply struct {
 	Likes        int       `gorm:"column:likes;default:0" json:"likes"`
 
 	// --- 虚拟字段 ---
-	User       *User `gorm:"-" json:"user"`
-	TargetUser *User `gorm:"-" json:"targetUser"`
+	// [MODIFY] 只带公开资料，不含邮箱
+	User       *PublicUser `gorm:"-" json:"user"`
+	TargetUser *PublicUser `gorm:"-" json:"targetUser"`
 
 	// 兼容前端直接读取 username (虽然 User 对象里也有，但前端可能有 legacy 代码)
 	Username   string `gorm:"-" json:"username"`
@@ -0,0 +1,65 @@
+package model
+
+// 角色编码 (对应 t_role.code)
+const (
+	RoleAdmin  = "admin"  // 站长/管理员：拥有全部权限
+	RoleAuthor = "author" // 作者：可以发布、管理文章
+	RoleReader = "reader" // 读者：只能浏览、评论、点赞 (注册默认角色)
+)
+
+// 权限编码 (对应 t_permission.code)
+const (
+	PermArticleWrite   = "article:write"   // 发布/编辑文章
+	PermArticleDelete  = "article:delete"  // 删除文章
+	PermCategoryManage = "category:manage" // 分类管理
+	PermCommentManage  = "comment:manage"  // 评论管理
+	PermUserManage     = "user:manage"     // 用户与角色管理
+)
+
+// Role 对应 t_role 表
+type Role struct {
+	Id          int    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code        string `gorm:"column:code" json:"code"`
+	Name        string `gorm:"column:name" json:"name"`
+	Description string `gorm:"column:description" json:"description"`
+
+	// 虚拟字段：该角色拥有的权限编码
+	Permissions []string `gorm:"-" json:"permissions"`
+}
+
+func (Role) TableName() string {
+	return "t_role"
+}
+
+// Permission 对应 t_permission 表
+type Permission struct {
+	Id   int    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code string `gorm:"column:code" json:"code"`
+	Name string `gorm:"column:name" json:"name"`
+}
+
+func (Permission) TableName() string {
+	return "t_permission"
+}
+
+// UserRole 对应 t_user_role 表 (用户-角色 多对多)
+type UserRole struct {
+	Id     int `gorm:"primaryKey;autoIncrement"`
+	UserId int `gorm:"column:user_id"`
+	RoleId int `gorm:"column:role_id"`
+}
+
+func (UserRole) TableName() string {
+	return "t_user_role"
+}
+
+// RolePermission 对应 t_role_permission 表 (角色-权限 多对多)
+type RolePermission struct {
+	Id           int `gorm:"primaryKey;autoIncrement"`
+	RoleId       int `gorm:"column:role_id"`
+	PermissionId int `gorm:"column:permission_id"`
+}
+
+func (RolePermission) TableName() string {
+	return "t_role_permission"
+}
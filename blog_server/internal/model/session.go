@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Session 登录会话 (一次登录 = 一个会话 = 一台设备)
+// 存储在 Redis (session:<id>)，不对应数据库表
+type Session struct {
+	Id        string    `json:"id"`
+	UserId    int       `json:"userId"`
+	Device    string    `json:"device"` // 由 User-Agent 解析，如 "Chrome / Windows"
+	UserAgent string    `json:"userAgent"`
+	Ip        string    `json:"ip"`
+	Created   time.Time `json:"created"`
+	LastSeen  time.Time `json:"lastSeen"`
+
+	// 虚拟字段：是否为发起请求的当前会话
+	Current bool `json:"current"`
+}
+
+// ClientInfo 发起登录/请求的客户端信息 (Controller 从 gin.Context 中提取)
+type ClientInfo struct {
+	Ip        string
+	UserAgent string
+}
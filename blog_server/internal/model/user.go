@@ -11,8 +11,19 @@ type User struct {
 	Avatar   string    `gorm:"column:avatar" json:"avatar"`
 	Created  time.Time `gorm:"column:created" json:"created"`
 	Valid    int       `gorm:"column:valid" json:"valid"` // tinyint(1) 通常映射为 int 或 bool
-	// [NEW] 新增权限字段 (为了骗过前端的路由生成器)
-	// 对应 Java List<GrantedAuthority>，序列化后通常是对象数组
+	// [NEW] 两步验证 (TOTP)
+	TotpSecret  string `gorm:"column:totp_secret" json:"-"`
+	TotpEnabled int    `gorm:"column:totp_enabled" json:"totpEnabled"` // 1:已开启
+	// [NEW] 公开资料 (见 user_profile.go)
+	Nickname    string       `gorm:"column:nickname" json:"nickname"`
+	Bio         string       `gorm:"column:bio" json:"bio"` // 个人简介
+	Website     string       `gorm:"column:website" json:"website"`
+	Location    string       `gorm:"column:location" json:"location"`
+	Cover       string       `gorm:"column:cover" json:"cover"` // 个人主页封面图
+	SocialLinks []SocialLink `gorm:"column:social_links;serializer:json" json:"socialLinks"`
+	// [MODIFY] 角色字段，来自 t_user_role (登录时由 UserService 填充)
+	Roles []string `gorm:"-" json:"roles"`
+	// 对应 Java List<GrantedAuthority>，序列化后是 [{"authority": "ROLE_admin"}]
 	Authorities []map[string]string `gorm:"-" json:"authorities"`
 }
 
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// 账号状态 (对应 t_user.valid)
+const (
+	UserBanned = 0 // 已封禁：不能登录，已登录的会话全部失效
+	UserValid  = 1 // 正常
+)
+
+// UserCondition 后台用户搜索条件 (字段为空表示不过滤)
+type UserCondition struct {
+	Username    string     `json:"username"`    // 模糊匹配
+	Email       string     `json:"email"`       // 模糊匹配
+	CreatedFrom *time.Time `json:"createdFrom"` // 注册时间范围
+	CreatedTo   *time.Time `json:"createdTo"`
+	Valid       *int       `json:"valid"` // 0:已封禁 1:正常
+	Role        string     `json:"role"`  // 角色编码
+}
+
+// UserActivity 后台查看的用户活跃度汇总
+type UserActivity struct {
+	Articles      int64      `json:"articles"`      // 发表的文章
+	Comments      int64      `json:"comments"`      // 评论
+	Replies       int64      `json:"replies"`       // 回复
+	ArticleLikes  int64      `json:"articleLikes"`  // 点赞的文章
+	CommentLikes  int64      `json:"commentLikes"`  // 点赞的评论和回复
+	LikesReceived int64      `json:"likesReceived"` // 文章、评论、回复收到的点赞
+	LastActive    *time.Time `json:"lastActive"`    // 最近一次操作记录 (t_op_log)
+}
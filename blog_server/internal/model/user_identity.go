@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// UserIdentity 第三方账号与本站用户的绑定关系 (GitHub、OIDC 等)
+// 对应 t_user_identity 表，(provider, subject) 唯一
+type UserIdentity struct {
+	Id        int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId    int        `gorm:"column:user_id" json:"userId"`
+	Provider  string     `gorm:"column:provider" json:"provider"` // 对应配置中的 name
+	Subject   string     `gorm:"column:subject" json:"-"`         // 第三方的用户唯一 ID
+	Email     string     `gorm:"column:email" json:"email"`
+	Nickname  string     `gorm:"column:nickname" json:"nickname"` // 第三方的用户名，仅展示用
+	Created   time.Time  `gorm:"column:created" json:"created"`
+	LastLogin *time.Time `gorm:"column:last_login" json:"lastLogin"`
+}
+
+func (UserIdentity) TableName() string {
+	return "t_user_identity"
+}
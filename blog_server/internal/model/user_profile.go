@@ -0,0 +1,73 @@
+package model
+
+import "time"
+
+// SocialLinkPlatforms 支持的社交平台 (前端据此显示图标)
+var SocialLinkPlatforms = []string{"github", "gitee", "weibo", "zhihu", "bilibili", "juejin", "csdn", "twitter", "linkedin", "other"}
+
+// SocialLink 社交账号链接，存为 t_user.social_links (JSON)
+type SocialLink struct {
+	Platform string `json:"platform"`
+	Url      string `json:"url"`
+}
+
+// ProfileForm 修改公开资料 (整体覆盖，空字符串表示清空)
+type ProfileForm struct {
+	Nickname    string       `json:"nickname"`
+	Bio         string       `json:"bio"`
+	Website     string       `json:"website"`
+	Location    string       `json:"location"`
+	Cover       string       `json:"cover"`
+	SocialLinks []SocialLink `json:"socialLinks"`
+}
+
+// PublicUser 公开的用户信息，不含邮箱、账号状态等隐私字段
+type PublicUser struct {
+	Id          int          `json:"id"`
+	Username    string       `json:"username"`
+	Nickname    string       `json:"nickname"`
+	Avatar      string       `json:"avatar"`
+	Cover       string       `json:"cover"`
+	Bio         string       `json:"bio"`
+	Website     string       `json:"website"`
+	Location    string       `json:"location"`
+	SocialLinks []SocialLink `json:"socialLinks"`
+	Created     time.Time    `json:"created"` // 加入时间
+}
+
+// Public 转换为可以公开的用户信息
+func (u *User) Public() *PublicUser {
+	links := u.SocialLinks
+	if links == nil {
+		links = []SocialLink{}
+	}
+	return &PublicUser{
+		Id:          u.Id,
+		Username:    u.Username,
+		Nickname:    u.Nickname,
+		Avatar:      u.Avatar,
+		Cover:       u.Cover,
+		Bio:         u.Bio,
+		Website:     u.Website,
+		Location:    u.Location,
+		SocialLinks: links,
+		Created:     u.Created,
+	}
+}
+
+// UserProfileStats 个人主页的统计数据
+type UserProfileStats struct {
+	Articles      int64 `json:"articles"`      // 发表的文章
+	LikesReceived int64 `json:"likesReceived"` // 文章、评论、回复收到的点赞
+	Comments      int64 `json:"comments"`      // 发表的评论和回复
+	Followers     int64 `json:"followers"`     // [NEW] 粉丝数
+	Following     int64 `json:"following"`     // [NEW] 关注数
+}
+
+// UserProfile 个人主页 (GET /api/user/:id/profile)
+type UserProfile struct {
+	User         *PublicUser       `json:"user"`
+	Stats        *UserProfileStats `json:"stats"`
+	Articles     []Article         `json:"articles"` // 文章 (分页)
+	ArticleTotal int64             `json:"articleTotal"`
+}
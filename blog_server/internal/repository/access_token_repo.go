@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"my-blog/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AccessTokenRepository interface {
+	Create(token *model.AccessToken) error
+	FindById(id int) (*model.AccessToken, error)
+	FindByHash(hash string) (*model.AccessToken, error)
+	FindByUserId(userId int) ([]*model.AccessToken, error)
+	CountByUserId(userId int) (int64, error)
+	UpdateLastUsed(id int, lastUsed time.Time, ip string) error
+	Delete(id int) error
+	DeleteByUserId(userId int) error
+}
+
+type accessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessTokenRepository(db *gorm.DB) AccessTokenRepository {
+	return &accessTokenRepository{db: db}
+}
+
+func (r *accessTokenRepository) Create(token *model.AccessToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *accessTokenRepository) FindById(id int) (*model.AccessToken, error) {
+	var token model.AccessToken
+	if err := r.db.First(&token, id).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *accessTokenRepository) FindByHash(hash string) (*model.AccessToken, error) {
+	var token model.AccessToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *accessTokenRepository) FindByUserId(userId int) ([]*model.AccessToken, error) {
+	var list []*model.AccessToken
+	err := r.db.Where("user_id = ?", userId).Order("created desc").Find(&list).Error
+	return list, err
+}
+
+func (r *accessTokenRepository) CountByUserId(userId int) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.AccessToken{}).Where("user_id = ?", userId).Count(&count).Error
+	return count, err
+}
+
+func (r *accessTokenRepository) UpdateLastUsed(id int, lastUsed time.Time, ip string) error {
+	return r.db.Model(&model.AccessToken{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used":    lastUsed,
+		"last_used_ip": ip,
+	}).Error
+}
+
+func (r *accessTokenRepository) Delete(id int) error {
+	return r.db.Delete(&model.AccessToken{}, id).Error
+}
+
+func (r *accessTokenRepository) DeleteByUserId(userId int) error {
+	return r.db.Where("user_id = ?", userId).Delete(&model.AccessToken{}).Error
+}
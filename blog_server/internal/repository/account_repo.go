@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"my-blog/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// [NEW] 账号级别的数据操作 (个人数据导出、注销账号)
+// 涉及的表分散在各个 Repository 中，这里在同一个事务里依次调用，保证数据一致：
+// 要么全部导出/删除成功，要么什么都不改
+type AccountRepository interface {
+	Export(userId int) (*model.UserDataExport, error)
+	// mode: model.DeleteModeAnonymize / model.DeleteModeRemove
+	Delete(userId int, mode string) error
+}
+
+type accountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) AccountRepository {
+	return &accountRepository{db: db}
+}
+
+// 同一个事务里的各个 Repository
+type accountTx struct {
+	users         UserRepository
+	roles         RoleRepository
+	articles      ArticleRepository
+	comments      CommentRepository
+	replies       ReplyRepository
+	notifications NotificationRepository
+	opLogs        OpLogRepository
+	recoveryCodes RecoveryCodeRepository
+	passkeys      PasskeyRepository
+	identities    UserIdentityRepository
+	accessTokens  AccessTokenRepository
+	pwdHistory    PasswordHistoryRepository
+	follows       FollowRepository
+	blocks        BlockRepository
+	revisions     ArticleRevisionRepository
+}
+
+func (r *accountRepository) transaction(fn func(repos *accountTx) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&accountTx{
+			users:         NewUserRepository(tx),
+			roles:         NewRoleRepository(tx),
+			articles:      NewArticleRepository(tx),
+			comments:      NewCommentRepository(tx),
+			replies:       NewReplyRepository(tx),
+			notifications: NewNotificationRepository(tx),
+			opLogs:        NewOpLogRepository(tx),
+			recoveryCodes: NewRecoveryCodeRepository(tx),
+			passkeys:      NewPasskeyRepository(tx),
+			identities:    NewUserIdentityRepository(tx),
+			accessTokens:  NewAccessTokenRepository(tx),
+			pwdHistory:    NewPasswordHistoryRepository(tx),
+			follows:       NewFollowRepository(tx),
+			blocks:        NewBlockRepository(tx),
+			revisions:     NewArticleRevisionRepository(tx),
+		})
+	})
+}
+
+func (r *accountRepository) Export(userId int) (*model.UserDataExport, error) {
+	data := &model.UserDataExport{ExportedAt: time.Now()}
+	err := r.transaction(func(repos *accountTx) error {
+		var err error
+		if data.Profile, err = repos.users.FindById(userId); err != nil {
+			return err
+		}
+		if data.Identities, err = repos.identities.FindByUserId(userId); err != nil {
+			return err
+		}
+		if data.Passkeys, err = repos.passkeys.FindByUserId(userId); err != nil {
+			return err
+		}
+		if data.AccessTokens, err = repos.accessTokens.FindByUserId(userId); err != nil {
+			return err
+		}
+		if data.Articles, err = repos.articles.FindAllByUserId(userId); err != nil {
+			return err
+		}
+		if data.Comments, err = repos.comments.FindAllByUserId(userId); err != nil {
+			return err
+		}
+		if data.Replies, err = repos.replies.FindAllByUserId(userId); err != nil {
+			return err
+		}
+		if data.Likes.Articles, err = repos.articles.FindLikesByUserId(userId); err != nil {
+			return err
+		}
+		if data.Likes.Comments, err = repos.comments.FindLikesByUserId(userId); err != nil {
+			return err
+		}
+		if data.Likes.Replies, err = repos.replies.FindLikesByUserId(userId); err != nil {
+			return err
+		}
+		if data.Notifications, err = repos.notifications.FindAllByReceiverId(userId); err != nil {
+			return err
+		}
+		if data.Footprints, err = repos.opLogs.FindAllByUserId(userId); err != nil {
+			return err
+		}
+		if data.Following, err = repos.follows.FindFollowingByUserId(userId); err != nil {
+			return err
+		}
+		data.Blocks, err = repos.blocks.FindAllByBlocker(userId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (r *accountRepository) Delete(userId int, mode string) error {
+	return r.transaction(func(repos *accountTx) error {
+		// 1. 发布的内容
+		var err error
+		if mode == model.DeleteModeRemove {
+			err = deleteContent(repos, userId)
+		} else {
+			err = anonymizeContent(repos, userId)
+		}
+		if err != nil {
+			return err
+		}
+
+		// 2. 个人数据 (两种方式都删除)
+		steps := []func(int) error{
+			repos.notifications.DeleteByReceiverId,
+			repos.opLogs.DeleteByUserId,
+			repos.recoveryCodes.DeleteByUserId,
+			repos.passkeys.DeleteByUserId,
+			repos.identities.DeleteByUserId,
+			repos.accessTokens.DeleteByUserId,
+			repos.pwdHistory.DeleteByUserId,
+			repos.follows.DeleteByUserId,
+			repos.blocks.DeleteByUserId,
+			repos.roles.DeleteUserRoles,
+			repos.users.Delete,
+		}
+		for _, step := range steps {
+			if err := step(userId); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// --- Helper Functions ---
+
+// 匿名化：已发布的内容保留，作者改为"已注销用户"；点赞记录删除但点赞数保留
+// 草稿、定时、归档的文章注销后没人能再编辑，不能留给"已注销用户"，直接删除
+func anonymizeContent(repos *accountTx, userId int) error {
+	if err := deleteUnpublishedArticles(repos, userId); err != nil {
+		return err
+	}
+	steps := []func() error{
+		func() error { return repos.articles.DeleteLikesByUserId(userId, false) },
+		func() error { return repos.comments.DeleteLikesByUserId(userId, false) },
+		func() error { return repos.replies.DeleteLikesByUserId(userId, false) },
+		func() error { return repos.articles.AnonymizeByUserId(userId, model.DeletedUserName) },
+		func() error { return repos.comments.AnonymizeByUserId(userId, model.DeletedUserName) },
+		func() error { return repos.replies.AnonymizeByUserId(userId, model.DeletedUserName) },
+		func() error { return repos.notifications.AnonymizeSender(userId, model.DeletedUserName) },
+		func() error { return repos.revisions.AnonymizeEditor(userId, model.DeletedUserName) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 删除用户未发布的文章，连同下面的评论、回复、通知 (历史版本、slug 由 DeleteByIds 删除)
+func deleteUnpublishedArticles(repos *accountTx, userId int) error {
+	articles, err := repos.articles.FindAllByUserId(userId)
+	if err != nil {
+		return err
+	}
+	var articleIds []int
+	for _, a := range articles {
+		if !a.IsPublished() {
+			articleIds = append(articleIds, a.Id)
+		}
+	}
+	if len(articleIds) == 0 {
+		return nil
+	}
+	// 归档的文章发布过，可能有别人的评论
+	commentIds, err := repos.comments.FindIdsByArticleIds(articleIds)
+	if err != nil {
+		return err
+	}
+
+	steps := []func() error{
+		func() error { return repos.replies.DeleteByCommentIds(commentIds) },
+		func() error { return repos.comments.DeleteByIds(commentIds) },
+		func() error { return repos.notifications.DeleteByArticleIds(articleIds) },
+		func() error { return repos.notifications.DeleteByCommentIds(commentIds) },
+		func() error { return repos.articles.DeleteByIds(articleIds) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 彻底删除：文章 (连同别人在下面的评论)、评论 (连同下面的回复)、回复、点赞 (扣减点赞数)
+func deleteContent(repos *accountTx, userId int) error {
+	// 先撤销点赞，再删除内容 (自己内容上的点赞会随内容一起删除)
+	if err := repos.articles.DeleteLikesByUserId(userId, true); err != nil {
+		return err
+	}
+	if err := repos.comments.DeleteLikesByUserId(userId, true); err != nil {
+		return err
+	}
+	if err := repos.replies.DeleteLikesByUserId(userId, true); err != nil {
+		return err
+	}
+
+	articles, err := repos.articles.FindAllByUserId(userId)
+	if err != nil {
+		return err
+	}
+	articleIds := make([]int, 0, len(articles))
+	for _, a := range articles {
+		articleIds = append(articleIds, a.Id)
+	}
+	commentIds, err := repos.comments.FindIdsByUserOrArticles(userId, articleIds)
+	if err != nil {
+		return err
+	}
+
+	steps := []func() error{
+		func() error { return repos.replies.DeleteByCommentIds(commentIds) },
+		func() error { return repos.replies.DeleteByUserId(userId) },
+		// 别人回复该用户的回复保留，目标改为"已注销用户"
+		func() error { return repos.replies.AnonymizeByUserId(userId, model.DeletedUserName) },
+		func() error { return repos.comments.DeleteByIds(commentIds) },
+		func() error { return repos.articles.DeleteByIds(articleIds) },
+		func() error { return repos.notifications.DeleteBySenderId(userId) },
+		func() error { return repos.notifications.DeleteByArticleIds(articleIds) },
+		func() error { return repos.notifications.DeleteByCommentIds(commentIds) },
+		// 自己文章的历史版本随文章删除，编辑过的别人的文章的版本保留
+		func() error { return repos.revisions.AnonymizeEditor(userId, model.DeletedUserName) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"my-blog/internal/model"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -45,6 +46,36 @@ type ArticleRepository interface {
 
 	// [NEW] 根据分类ID删除文章 (用于删除分类模式2: 销毁文章)
 	DeleteByCategoryId(categoryId int) error
+
+	// [NEW] 个人数据导出 / 注销账号
+	FindAllByUserId(userId int) ([]model.Article, error)
+	FindLikesByUserId(userId int) ([]model.LikeRecord, error)
+	// updateCount 为 true 时同时扣减文章的点赞数
+	DeleteLikesByUserId(userId int, updateCount bool) error
+	AnonymizeByUserId(userId int, author string) error
+	// 删除文章及其点赞、统计数据、历史版本、旧 slug (评论由 CommentRepository 删除)
+	DeleteByIds(ids []int) error
+
+	// [NEW] 关注动态：关注的作者发表的文章，按 (created, id) 倒序
+	// before 为上一页最后一篇 (nil 表示第一页)，使用游标翻页避免新文章插入导致重复/遗漏
+	FindFeed(followerId int, before *model.FeedCursor, limit int) ([]model.Article, error)
+
+	// [NEW] 定时发布：查出发布时间已到的定时文章
+	FindDueScheduled(now time.Time, limit int) ([]model.Article, error)
+	// 把定时文章改为已发布 (发布时间作为创建时间)
+	// 只有状态仍为 scheduled 时才会更新，返回 false 表示已被其他实例发布或作者改了状态
+	PublishScheduled(id int) (bool, error)
+
+	// [NEW] slug
+	FindBySlug(slug string) (*model.Article, error)
+	// 还没有 slug 的文章 (加 slug 之前发布的)
+	FindWithoutSlug(limit int) ([]model.Article, error)
+	UpdateSlug(id int, slug string) error
+
+	// [NEW] 全文搜索：按 ID 查已发布的文章 (带点赞数、阅读数，顺序不保证)
+	FindPublishedByIds(ids []int) ([]model.Article, error)
+	// [NEW] 全文搜索：ids 中仍然存在且已发布的文章 ID (只查 ID，用于剔除索引里过期的结果)
+	FindPublishedIds(ids []int) ([]int, error)
 }
 
 // 2. 结构体实现
@@ -57,11 +88,16 @@ func NewArticleRepository(db *gorm.DB) ArticleRepository {
 	return &articleRepository{db: db}
 }
 
+// [NEW] 查询条件：只查已发布的文章 (草稿、定时、归档只有作者本人能看到)
+func publishedArticles(db *gorm.DB) *gorm.DB {
+	return db.Where("t_article.status = ?", model.ArticleStatusPublished)
+}
+
 // 4. 具体实现
 func (r *articleRepository) FindAll() ([]model.Article, error) {
 	var articles []model.Article
 	// 相当于 select * from t_article order by created desc
-	result := r.db.Order("created desc").Find(&articles)
+	result := r.db.Scopes(publishedArticles).Order("created desc").Find(&articles)
 	return articles, result.Error
 }
 
@@ -88,7 +124,8 @@ func (r *articleRepository) GetPage(page, pageSize int, sort string) ([]model.Ar
 	// 使用 Table + Join 以便获取 hits 和 likes，并支持按 hits 排序
 	query := r.db.Table("t_article").
 		Select("t_article.*, t_statistic.likes, t_statistic.hits AS views").
-		Joins("LEFT JOIN t_statistic ON t_article.id = t_statistic.article_id")
+		Joins("LEFT JOIN t_statistic ON t_article.id = t_statistic.article_id").
+		Scopes(publishedArticles)
 
 	// 处理排序逻辑
 	if sort == "hot" {
@@ -145,6 +182,7 @@ func (r *articleRepository) GetLikeRanking(limit int) ([]model.Article, error) {
 	err := r.db.Table("t_article").
 		Select("t_article.*, t_statistic.likes, t_statistic.hits").
 		Joins("LEFT JOIN t_statistic ON t_article.id = t_statistic.article_id").
+		Scopes(publishedArticles).
 		Order("t_statistic.likes DESC"). // 按点赞倒序
 		Limit(limit).
 		Scan(&articles).Error // Scan 会自动把查出来的 likes 填入 Article 结构体的 Likes 字段(因为字段名匹配)
@@ -213,6 +251,7 @@ func (r *articleRepository) GetReadRanking(limit int) ([]model.Article, error) {
 	err := r.db.Table("t_article").
 		Select("t_article.*, t_statistic.likes, t_statistic.hits AS views").
 		Joins("LEFT JOIN t_statistic ON t_article.id = t_statistic.article_id").
+		Scopes(publishedArticles).
 		Order("t_statistic.hits DESC").
 		Limit(limit).
 		Scan(&articles).Error
@@ -237,6 +276,7 @@ func (r *articleRepository) GetMyLikedArticles(userId, page, pageSize int) ([]mo
 		Joins("JOIN t_article_like ON t_article_like.article_id = t_article.id").
 		Joins("LEFT JOIN t_statistic ON t_article.id = t_statistic.article_id").
 		Where("t_article_like.user_id = ?", userId).
+		Scopes(publishedArticles).
 		Order("t_article_like.created desc")
 
 	if err := query.Count(&total).Error; err != nil {
@@ -270,6 +310,39 @@ func (r *articleRepository) Search(page, pageSize int, condition *model.ArticleC
 		if condition.UserId > 0 {
 			query = query.Where("t_article.user_id = ?", condition.UserId)
 		}
+		// [NEW] 搜索索引还没建好时退化为 LIKE 查询
+		if condition.Keyword != "" {
+			like := "%" + condition.Keyword + "%"
+			query = query.Where("t_article.title LIKE ? OR t_article.content LIKE ? OR t_article.tags LIKE ?", like, like, like)
+		}
+		if condition.Title != "" {
+			query = query.Where("t_article.title LIKE ?", "%"+condition.Title+"%")
+		}
+		if condition.Content != "" {
+			query = query.Where("t_article.content LIKE ?", "%"+condition.Content+"%")
+		}
+		// [NEW] 分类 (包含子分类)、发布时间
+		if len(condition.CategoryIds) > 0 {
+			query = query.Where("t_article.category_id IN ?", condition.CategoryIds)
+		} else if condition.CategoryId > 0 {
+			query = query.Where("t_article.category_id = ?", condition.CategoryId)
+		}
+		if condition.CreatedFrom != nil {
+			query = query.Where("t_article.created >= ?", *condition.CreatedFrom)
+		}
+		if condition.CreatedTo != nil {
+			query = query.Where("t_article.created < ?", *condition.CreatedTo)
+		}
+		// [NEW] 查自己的文章时可以看到所有状态，并可按状态筛选；其他情况只返回已发布的
+		if condition.UserId > 0 && condition.UserId == condition.ViewerId {
+			if condition.Status != "" {
+				query = query.Where("t_article.status = ?", condition.Status)
+			}
+		} else {
+			query = query.Scopes(publishedArticles)
+		}
+	} else {
+		query = query.Scopes(publishedArticles)
 	}
 
 	// 3. 先查总数
@@ -292,6 +365,7 @@ func (r *articleRepository) FindByCategoryId(categoryId int) ([]model.Article, e
 	// 这里的 Find 会自动映射所有字段
 	err := r.db.Model(&model.Article{}).
 		Where("category_id = ?", categoryId).
+		Scopes(publishedArticles).
 		Order("created desc").
 		Find(&articles).Error
 	return articles, err
@@ -306,5 +380,171 @@ func (r *articleRepository) UpdateCategoryId(oldCategoryId, newCategoryId int) e
 
 // [NEW] 删除某分类下的所有文章
 func (r *articleRepository) DeleteByCategoryId(categoryId int) error {
+	// [NEW] 先删除这些文章的历史版本、旧 slug
+	ids := r.db.Model(&model.Article{}).Select("id").Where("category_id = ?", categoryId)
+	if err := r.db.Where("article_id IN (?)", ids).Delete(&model.ArticleRevision{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("article_id IN (?)", ids).Delete(&model.ArticleSlug{}).Error; err != nil {
+		return err
+	}
 	return r.db.Where("category_id = ?", categoryId).Delete(&model.Article{}).Error
 }
+
+// [NEW] 实现 FindAllByUserId
+func (r *articleRepository) FindAllByUserId(userId int) ([]model.Article, error) {
+	var articles []model.Article
+	err := r.db.Where("user_id = ?", userId).Order("created asc").Find(&articles).Error
+	return articles, err
+}
+
+// [NEW] 实现 FindLikesByUserId
+func (r *articleRepository) FindLikesByUserId(userId int) ([]model.LikeRecord, error) {
+	var likes []model.LikeRecord
+	err := r.db.Model(&model.ArticleLike{}).
+		Select("article_id AS target_id, created").
+		Where("user_id = ?", userId).
+		Order("created asc").
+		Scan(&likes).Error
+	return likes, err
+}
+
+// [NEW] 实现 DeleteLikesByUserId
+func (r *articleRepository) DeleteLikesByUserId(userId int, updateCount bool) error {
+	if updateCount {
+		liked := r.db.Model(&model.ArticleLike{}).Select("article_id").Where("user_id = ?", userId)
+		err := r.db.Model(&model.Statistic{}).
+			Where("article_id IN (?)", liked).
+			UpdateColumn("likes", gorm.Expr("GREATEST(likes - 1, 0)")).Error
+		if err != nil {
+			return err
+		}
+	}
+	return r.db.Where("user_id = ?", userId).Delete(&model.ArticleLike{}).Error
+}
+
+// [NEW] 实现 AnonymizeByUserId
+func (r *articleRepository) AnonymizeByUserId(userId int, author string) error {
+	return r.db.Model(&model.Article{}).Where("user_id = ?", userId).Updates(map[string]interface{}{
+		"user_id":  0,
+		"author":   author,
+		"location": "",
+	}).Error
+}
+
+// [NEW] 实现 DeleteByIds
+func (r *articleRepository) DeleteByIds(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.Where("article_id IN ?", ids).Delete(&model.ArticleLike{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("article_id IN ?", ids).Delete(&model.Statistic{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("article_id IN ?", ids).Delete(&model.ArticleRevision{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("article_id IN ?", ids).Delete(&model.ArticleSlug{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("id IN ?", ids).Delete(&model.Article{}).Error
+}
+
+// [NEW] 实现 FindFeed
+func (r *articleRepository) FindFeed(followerId int, before *model.FeedCursor, limit int) ([]model.Article, error) {
+	query := r.db.Table("t_article").
+		Joins("LEFT JOIN t_statistic ON t_article.id = t_statistic.article_id").
+		Where("t_article.user_id IN (?)", r.db.Model(&model.UserFollow{}).
+			Select("followee_id").
+			Where("follower_id = ?", followerId)).
+		Scopes(publishedArticles)
+	if before != nil {
+		query = query.Where("t_article.created < ? OR (t_article.created = ? AND t_article.id < ?)",
+			before.Created, before.Created, before.Id)
+	}
+
+	var articles []model.Article
+	err := query.Select("t_article.*, t_statistic.likes, t_statistic.hits AS views").
+		Order("t_article.created desc, t_article.id desc").
+		Limit(limit).
+		Find(&articles).Error
+	return articles, err
+}
+
+// [NEW] 实现 FindDueScheduled
+func (r *articleRepository) FindDueScheduled(now time.Time, limit int) ([]model.Article, error) {
+	var articles []model.Article
+	err := r.db.Where("status = ? AND publish_at <= ?", model.ArticleStatusScheduled, now).
+		Order("publish_at asc").
+		Limit(limit).
+		Find(&articles).Error
+	return articles, err
+}
+
+// [NEW] 实现 PublishScheduled
+func (r *articleRepository) PublishScheduled(id int) (bool, error) {
+	result := r.db.Model(&model.Article{}).
+		Where("id = ? AND status = ?", id, model.ArticleStatusScheduled).
+		Updates(map[string]interface{}{
+			"status":  model.ArticleStatusPublished,
+			"created": gorm.Expr("publish_at"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// [NEW] 实现 FindBySlug (与 FindById 一样带上点赞数、阅读数)
+func (r *articleRepository) FindBySlug(slug string) (*model.Article, error) {
+	var article model.Article
+	err := r.db.Table("t_article").
+		Select("t_article.*, IFNULL(s.likes, 0) as likes, IFNULL(s.hits, 0) as views").
+		Joins("LEFT JOIN t_statistic s ON s.article_id = t_article.id").
+		Where("t_article.slug = ?", slug).
+		First(&article).Error
+	return &article, err
+}
+
+// [NEW] 实现 FindWithoutSlug
+func (r *articleRepository) FindWithoutSlug(limit int) ([]model.Article, error) {
+	var articles []model.Article
+	err := r.db.Select("id, title").
+		Where("slug IS NULL OR slug = ''").
+		Order("id asc").
+		Limit(limit).
+		Find(&articles).Error
+	return articles, err
+}
+
+// [NEW] 实现 UpdateSlug
+func (r *articleRepository) UpdateSlug(id int, slug string) error {
+	return r.db.Model(&model.Article{}).Where("id = ?", id).Update("slug", slug).Error
+}
+
+// [NEW] 实现 FindPublishedByIds
+func (r *articleRepository) FindPublishedByIds(ids []int) ([]model.Article, error) {
+	var articles []model.Article
+	if len(ids) == 0 {
+		return articles, nil
+	}
+	err := r.db.Table("t_article").
+		Select("t_article.*, IFNULL(s.likes, 0) as likes, IFNULL(s.hits, 0) as views").
+		Joins("LEFT JOIN t_statistic s ON s.article_id = t_article.id").
+		Where("t_article.id IN ?", ids).
+		Scopes(publishedArticles).
+		Find(&articles).Error
+	return articles, err
+}
+
+// [NEW] 实现 FindPublishedIds
+func (r *articleRepository) FindPublishedIds(ids []int) ([]int, error) {
+	var found []int
+	if len(ids) == 0 {
+		return found, nil
+	}
+	err := r.db.Model(&model.Article{}).
+		Where("t_article.id IN ?", ids).
+		Scopes(publishedArticles).
+		Pluck("t_article.id", &found).Error
+	return found, err
+}
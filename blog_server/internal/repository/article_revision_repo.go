@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"my-blog/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ArticleRevisionRepository interface {
+	// 新增一个版本，version 自动取该文章当前最大版本 + 1 (事务)
+	Create(revision *model.ArticleRevision) error
+	// 版本列表 (新的在前，不含正文)
+	FindByArticleId(articleId, page, pageSize int) ([]model.ArticleRevision, int64, error)
+	FindByVersion(articleId, version int) (*model.ArticleRevision, error)
+	// 把文章内容改为 revision (slug 同时改为 slug)，并把 revision 记录为一个新版本 (事务)
+	Restore(revision *model.ArticleRevision, slug string) error
+	DeleteByArticleIds(articleIds []int) error
+	// [NEW] 注销账号：编辑者改为"已注销用户"
+	AnonymizeEditor(userId int, editorName string) error
+}
+
+type articleRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewArticleRevisionRepository(db *gorm.DB) ArticleRevisionRepository {
+	return &articleRevisionRepository{db: db}
+}
+
+func (r *articleRevisionRepository) Create(revision *model.ArticleRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createRevision(tx, revision)
+	})
+}
+
+func (r *articleRevisionRepository) FindByArticleId(articleId, page, pageSize int) ([]model.ArticleRevision, int64, error) {
+	query := r.db.Model(&model.ArticleRevision{}).Where("article_id = ?", articleId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var revisions []model.ArticleRevision
+	err := query.Omit("content").
+		Order("version desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&revisions).Error
+	return revisions, total, err
+}
+
+func (r *articleRevisionRepository) FindByVersion(articleId, version int) (*model.ArticleRevision, error) {
+	var revision model.ArticleRevision
+	err := r.db.Where("article_id = ? AND version = ?", articleId, version).First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (r *articleRevisionRepository) Restore(revision *model.ArticleRevision, slug string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 用 map 更新，空标签等零值也要写回去
+		err := tx.Model(&model.Article{}).Where("id = ?", revision.ArticleId).Updates(map[string]interface{}{
+			"title":       revision.Title,
+			"content":     revision.Content,
+			"tags":        revision.Tags,
+			"categories":  revision.Categories,
+			"category_id": revision.CategoryId,
+			"slug":        slug,
+			"modified":    revision.Created,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return createRevision(tx, revision)
+	})
+}
+
+func (r *articleRevisionRepository) DeleteByArticleIds(articleIds []int) error {
+	if len(articleIds) == 0 {
+		return nil
+	}
+	return r.db.Where("article_id IN ?", articleIds).Delete(&model.ArticleRevision{}).Error
+}
+
+func (r *articleRevisionRepository) AnonymizeEditor(userId int, editorName string) error {
+	return r.db.Model(&model.ArticleRevision{}).Where("editor_id = ?", userId).Updates(map[string]interface{}{
+		"editor_id":   0,
+		"editor_name": editorName,
+	}).Error
+}
+
+// --- Helper Functions ---
+
+// 锁住该文章已有的版本再取最大值，避免同时保存时分配到相同的 version
+func createRevision(tx *gorm.DB, revision *model.ArticleRevision) error {
+	var latest int
+	err := tx.Model(&model.ArticleRevision{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("article_id = ?", revision.ArticleId).
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+	revision.Version = latest + 1
+	return tx.Create(revision).Error
+}
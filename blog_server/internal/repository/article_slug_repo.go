@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"errors"
+	"my-blog/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ArticleSlugRepository interface {
+	// slug 是否已被其他文章使用 (包括其他文章以前用过的)
+	IsTaken(slug string, exceptArticleId int) (bool, error)
+	// 记录旧的 slug，并删除 newSlug 的旧记录 (改回以前用过的 slug 时) (事务)
+	Rename(articleId int, oldSlug, newSlug string) error
+	// 根据旧 slug 查文章 ID，没有时返回 0
+	FindArticleIdByOldSlug(slug string) (int, error)
+	DeleteByArticleIds(articleIds []int) error
+}
+
+type articleSlugRepository struct {
+	db *gorm.DB
+}
+
+func NewArticleSlugRepository(db *gorm.DB) ArticleSlugRepository {
+	return &articleSlugRepository{db: db}
+}
+
+func (r *articleSlugRepository) IsTaken(slug string, exceptArticleId int) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Article{}).Where("slug = ? AND id <> ?", slug, exceptArticleId).Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+	err = r.db.Model(&model.ArticleSlug{}).Where("slug = ? AND article_id <> ?", slug, exceptArticleId).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *articleSlugRepository) Rename(articleId int, oldSlug, newSlug string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("article_id = ? AND slug = ?", articleId, newSlug).Delete(&model.ArticleSlug{}).Error
+		if err != nil || oldSlug == "" {
+			return err
+		}
+		err = tx.Create(&model.ArticleSlug{ArticleId: articleId, Slug: oldSlug, Created: time.Now()}).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil
+		}
+		return err
+	})
+}
+
+func (r *articleSlugRepository) FindArticleIdByOldSlug(slug string) (int, error) {
+	var old model.ArticleSlug
+	err := r.db.Where("slug = ?", slug).First(&old).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return old.ArticleId, err
+}
+
+func (r *articleSlugRepository) DeleteByArticleIds(articleIds []int) error {
+	if len(articleIds) == 0 {
+		return nil
+	}
+	return r.db.Where("article_id IN ?", articleIds).Delete(&model.ArticleSlug{}).Error
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"errors"
+	"my-blog/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BlockRepository interface {
+	// 新增或修改 (已屏蔽再拉黑时改为拉黑)；拉黑时同时解除双方的关注关系 (事务)
+	Save(blockerId, blockedId int, blockType string) error
+	Delete(blockerId, blockedId int) error
+	// blocker 对 blocked 的处理方式，没有时返回 ""
+	FindType(blockerId, blockedId int) (string, error)
+	// blockType 为空时返回全部
+	FindByBlocker(blockerId int, blockType string, page, pageSize int) ([]*model.BlockedUser, int64, error)
+	// [NEW] 个人数据导出 / 注销账号
+	FindAllByBlocker(blockerId int) ([]*model.UserBlock, error)
+	DeleteByUserId(userId int) error
+}
+
+type blockRepository struct {
+	db *gorm.DB
+}
+
+func NewBlockRepository(db *gorm.DB) BlockRepository {
+	return &blockRepository{db: db}
+}
+
+// HiddenFor 查询条件：排除 viewerId 拉黑 / 屏蔽的用户发表的内容 (column 为内容表的作者列)
+// viewerId 为 0 (游客) 时不过滤
+func HiddenFor(viewerId int, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerId <= 0 {
+			return db
+		}
+		return db.Where(column+" NOT IN (?)", db.Session(&gorm.Session{NewDB: true}).
+			Model(&model.UserBlock{}).
+			Select("blocked_id").
+			Where("blocker_id = ?", viewerId))
+	}
+}
+
+func (r *blockRepository) Save(blockerId, blockedId int, blockType string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		block := &model.UserBlock{BlockerId: blockerId, BlockedId: blockedId, Type: blockType, Created: time.Now()}
+		err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.AssignmentColumns([]string{"type", "created"}),
+		}).Create(block).Error
+		if err != nil {
+			return err
+		}
+		if blockType != model.BlockTypeBlock {
+			return nil
+		}
+		return tx.Where("(follower_id = ? AND followee_id = ?) OR (follower_id = ? AND followee_id = ?)",
+			blockerId, blockedId, blockedId, blockerId).Delete(&model.UserFollow{}).Error
+	})
+}
+
+func (r *blockRepository) Delete(blockerId, blockedId int) error {
+	return r.db.Where("blocker_id = ? AND blocked_id = ?", blockerId, blockedId).Delete(&model.UserBlock{}).Error
+}
+
+func (r *blockRepository) FindType(blockerId, blockedId int) (string, error) {
+	var block model.UserBlock
+	err := r.db.Where("blocker_id = ? AND blocked_id = ?", blockerId, blockedId).First(&block).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	return block.Type, err
+}
+
+func (r *blockRepository) FindByBlocker(blockerId int, blockType string, page, pageSize int) ([]*model.BlockedUser, int64, error) {
+	query := r.db.Table("t_user_block b").
+		Joins("JOIN t_user u ON u.id = b.blocked_id").
+		Where("b.blocker_id = ?", blockerId)
+	if blockType != "" {
+		query = query.Where("b.type = ?", blockType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		model.User
+		BlockType string
+		BlockedAt time.Time
+	}
+	err := query.Select("u.*, b.type AS block_type, b.created AS blocked_at").
+		Order("b.created desc, b.id desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]*model.BlockedUser, 0, len(rows))
+	for i := range rows {
+		users = append(users, &model.BlockedUser{
+			PublicUser: rows[i].User.Public(),
+			Type:       rows[i].BlockType,
+			BlockedAt:  rows[i].BlockedAt,
+		})
+	}
+	return users, total, nil
+}
+
+func (r *blockRepository) FindAllByBlocker(blockerId int) ([]*model.UserBlock, error) {
+	var blocks []*model.UserBlock
+	err := r.db.Where("blocker_id = ?", blockerId).Order("created asc").Find(&blocks).Error
+	return blocks, err
+}
+
+func (r *blockRepository) DeleteByUserId(userId int) error {
+	return r.db.Where("blocker_id = ? OR blocked_id = ?", userId, userId).Delete(&model.UserBlock{}).Error
+}
@@ -8,7 +8,8 @@ import (
 
 type CommentRepository interface {
 	// 获取文章的一页评论
-	GetPageByArticleId(articleId int, page int, pageSize int) ([]model.Comment, int64, error)
+	// [MODIFY] viewerId: 当前用户，不返回其拉黑 / 屏蔽的用户的评论 (游客传 0)
+	GetPageByArticleId(articleId, viewerId int, page int, pageSize int) ([]model.Comment, int64, error)
 	Create(comment *model.Comment) error
 
 	// [NEW] 评论点赞相关
@@ -19,7 +20,7 @@ type CommentRepository interface {
 
 	// [NEW] 新增：分页获取评论
 	// 返回值：评论列表, 总数, 错误
-	GetPage(articleId, page, rows int) ([]*model.Comment, int64, error)
+	GetPage(articleId, viewerId, page, rows int) ([]*model.Comment, int64, error)
 
 	// [NEW] 更新文章的评论数
 	UpdateArticleCommentCount(articleId int, step int) error
@@ -32,6 +33,19 @@ type CommentRepository interface {
 
 	// [NEW] 获取我点赞的评论
 	GetMyLikedComments(userId, page, pageSize int) ([]model.Comment, int64, error)
+
+	// [NEW] 个人数据导出 / 注销账号
+	FindAllByUserId(userId int) ([]model.Comment, error)
+	FindLikesByUserId(userId int) ([]model.LikeRecord, error)
+	// updateCount 为 true 时同时扣减评论的点赞数
+	DeleteLikesByUserId(userId int, updateCount bool) error
+	AnonymizeByUserId(userId int, author string) error
+	// 查询用户写的评论 + 指定文章下的全部评论
+	FindIdsByUserOrArticles(userId int, articleIds []int) ([]int, error)
+	// 查询指定文章下的全部评论
+	FindIdsByArticleIds(articleIds []int) ([]int, error)
+	// 删除评论及其点赞，并扣减文章的评论数 (回复由 ReplyRepository 删除)
+	DeleteByIds(ids []int) error
 }
 
 type commentRepository struct {
@@ -43,13 +57,14 @@ func NewCommentRepository(db *gorm.DB) CommentRepository {
 }
 
 // 获取评论列表 (只获取根评论，不包含回复)
-func (r *commentRepository) GetPageByArticleId(articleId int, page int, pageSize int) ([]model.Comment, int64, error) {
+func (r *commentRepository) GetPageByArticleId(articleId, viewerId int, page int, pageSize int) ([]model.Comment, int64, error) {
 	var comments []model.Comment
 	var total int64
 	offset := (page - 1) * pageSize
 
 	// 🔴 修复点：status = 'approved' (对应 Java/数据库逻辑)，而不是 1
-	query := r.db.Model(&model.Comment{}).Where("article_id = ? AND status = ?", articleId, "approved")
+	query := r.db.Model(&model.Comment{}).Where("article_id = ? AND status = ?", articleId, "approved").
+		Scopes(HiddenFor(viewerId, "user_id"))
 
 	query.Count(&total)
 
@@ -83,7 +98,7 @@ func (r *commentRepository) UpdateCommentLikesCount(commentId int, step int) err
 }
 
 // [NEW] 实现 GetPage
-func (r *commentRepository) GetPage(articleId, page, rows int) ([]*model.Comment, int64, error) {
+func (r *commentRepository) GetPage(articleId, viewerId, page, rows int) ([]*model.Comment, int64, error) {
 	var comments []*model.Comment
 	var total int64
 
@@ -91,7 +106,8 @@ func (r *commentRepository) GetPage(articleId, page, rows int) ([]*model.Comment
 	offset := (page - 1) * rows
 
 	// 2. 基础查询构建器 (只查该文章的评论)
-	query := r.db.Model(&model.Comment{}).Where("article_id = ?", articleId)
+	query := r.db.Model(&model.Comment{}).Where("article_id = ?", articleId).
+		Scopes(HiddenFor(viewerId, "user_id")) // [NEW] 隐藏拉黑 / 屏蔽的用户
 
 	// 3. 查总数
 	if err := query.Count(&total).Error; err != nil {
@@ -185,3 +201,100 @@ func (r *commentRepository) GetMyLikedComments(userId, page, pageSize int) ([]mo
 	err := query.Limit(pageSize).Offset(offset).Find(&comments).Error
 	return comments, total, err
 }
+
+// [NEW] 实现 FindAllByUserId
+func (r *commentRepository) FindAllByUserId(userId int) ([]model.Comment, error) {
+	var comments []model.Comment
+	err := r.db.Where("user_id = ?", userId).Order("created asc").Find(&comments).Error
+	return comments, err
+}
+
+// [NEW] 实现 FindLikesByUserId
+func (r *commentRepository) FindLikesByUserId(userId int) ([]model.LikeRecord, error) {
+	var likes []model.LikeRecord
+	err := r.db.Model(&model.CommentLike{}).
+		Select("comment_id AS target_id, created").
+		Where("user_id = ?", userId).
+		Order("created asc").
+		Scan(&likes).Error
+	return likes, err
+}
+
+// [NEW] 实现 DeleteLikesByUserId
+func (r *commentRepository) DeleteLikesByUserId(userId int, updateCount bool) error {
+	if updateCount {
+		liked := r.db.Model(&model.CommentLike{}).Select("comment_id").Where("user_id = ?", userId)
+		err := r.db.Model(&model.Comment{}).
+			Where("id IN (?)", liked).
+			UpdateColumn("likes", gorm.Expr("GREATEST(likes - 1, 0)")).Error
+		if err != nil {
+			return err
+		}
+	}
+	return r.db.Where("user_id = ?", userId).Delete(&model.CommentLike{}).Error
+}
+
+// [NEW] 实现 AnonymizeByUserId (同时清除 IP 和属地)
+func (r *commentRepository) AnonymizeByUserId(userId int, author string) error {
+	return r.db.Model(&model.Comment{}).Where("user_id = ?", userId).Updates(map[string]interface{}{
+		"user_id":  0,
+		"author":   author,
+		"ip":       "",
+		"location": "",
+	}).Error
+}
+
+// [NEW] 实现 FindIdsByUserOrArticles
+func (r *commentRepository) FindIdsByUserOrArticles(userId int, articleIds []int) ([]int, error) {
+	query := r.db.Model(&model.Comment{}).Where("user_id = ?", userId)
+	if len(articleIds) > 0 {
+		query = query.Or("article_id IN ?", articleIds)
+	}
+	var ids []int
+	err := query.Pluck("id", &ids).Error
+	return ids, err
+}
+
+// [NEW] 实现 FindIdsByArticleIds
+func (r *commentRepository) FindIdsByArticleIds(articleIds []int) ([]int, error) {
+	if len(articleIds) == 0 {
+		return nil, nil
+	}
+	var ids []int
+	err := r.db.Model(&model.Comment{}).Where("article_id IN ?", articleIds).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// [NEW] 实现 DeleteByIds
+func (r *commentRepository) DeleteByIds(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.Where("comment_id IN ?", ids).Delete(&model.CommentLike{}).Error; err != nil {
+		return err
+	}
+
+	// 按文章扣减评论数
+	var counts []struct {
+		ArticleId int
+		Total     int
+	}
+	err := r.db.Model(&model.Comment{}).
+		Select("article_id, COUNT(*) AS total").
+		Where("id IN ?", ids).
+		Group("article_id").
+		Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+	for _, c := range counts {
+		err := r.db.Table("t_statistic").
+			Where("article_id = ?", c.ArticleId).
+			UpdateColumn("comments_num", gorm.Expr("GREATEST(comments_num - ?, 0)", c.Total)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.db.Where("id IN ?", ids).Delete(&model.Comment{}).Error
+}
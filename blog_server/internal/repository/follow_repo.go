@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"errors"
+	"my-blog/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type FollowRepository interface {
+	// 重复关注不报错
+	Follow(followerId, followeeId int) error
+	Unfollow(followerId, followeeId int) error
+	IsFollowing(followerId, followeeId int) (bool, error)
+	// 粉丝列表 / 关注列表 (按关注时间倒序，已封禁的用户不显示)
+	FindFollowers(userId, page, pageSize int) ([]*model.FollowUser, int64, error)
+	FindFollowing(userId, page, pageSize int) ([]*model.FollowUser, int64, error)
+	// 全部粉丝 ID (发布文章时通知)，不含屏蔽了该用户的粉丝
+	FindFollowerIds(userId int) ([]int, error)
+	CountFollowers(userId int) (int64, error)
+	CountFollowing(userId int) (int64, error)
+	// [NEW] 个人数据导出 / 注销账号
+	FindFollowingByUserId(userId int) ([]*model.UserFollow, error)
+	// 删除该用户关注别人和被别人关注的记录
+	DeleteByUserId(userId int) error
+}
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) FollowRepository {
+	return &followRepository{db: db}
+}
+
+func (r *followRepository) Follow(followerId, followeeId int) error {
+	follow := &model.UserFollow{FollowerId: followerId, FolloweeId: followeeId, Created: time.Now()}
+	err := r.db.Create(follow).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil
+	}
+	return err
+}
+
+func (r *followRepository) Unfollow(followerId, followeeId int) error {
+	return r.db.Where("follower_id = ? AND followee_id = ?", followerId, followeeId).Delete(&model.UserFollow{}).Error
+}
+
+func (r *followRepository) IsFollowing(followerId, followeeId int) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.UserFollow{}).
+		Where("follower_id = ? AND followee_id = ?", followerId, followeeId).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *followRepository) FindFollowers(userId, page, pageSize int) ([]*model.FollowUser, int64, error) {
+	return r.findUsers("followee_id", "follower_id", userId, page, pageSize)
+}
+
+func (r *followRepository) FindFollowing(userId, page, pageSize int) ([]*model.FollowUser, int64, error) {
+	return r.findUsers("follower_id", "followee_id", userId, page, pageSize)
+}
+
+func (r *followRepository) FindFollowerIds(userId int) ([]int, error) {
+	var ids []int
+	err := r.db.Model(&model.UserFollow{}).
+		Where("followee_id = ?", userId).
+		Where("follower_id NOT IN (?)", r.db.Model(&model.UserBlock{}).Select("blocker_id").Where("blocked_id = ?", userId)).
+		Pluck("follower_id", &ids).Error
+	return ids, err
+}
+
+func (r *followRepository) CountFollowers(userId int) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.UserFollow{}).Where("followee_id = ?", userId).Count(&count).Error
+	return count, err
+}
+
+func (r *followRepository) CountFollowing(userId int) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.UserFollow{}).Where("follower_id = ?", userId).Count(&count).Error
+	return count, err
+}
+
+func (r *followRepository) FindFollowingByUserId(userId int) ([]*model.UserFollow, error) {
+	var follows []*model.UserFollow
+	err := r.db.Where("follower_id = ?", userId).Order("created asc").Find(&follows).Error
+	return follows, err
+}
+
+func (r *followRepository) DeleteByUserId(userId int) error {
+	return r.db.Where("follower_id = ? OR followee_id = ?", userId, userId).Delete(&model.UserFollow{}).Error
+}
+
+// --- Helper Functions ---
+
+// whereCol = userId 的关注记录，连表查出 userCol 对应的用户
+func (r *followRepository) findUsers(whereCol, userCol string, userId, page, pageSize int) ([]*model.FollowUser, int64, error) {
+	query := r.db.Table("t_user_follow f").
+		Joins("JOIN t_user u ON u.id = f."+userCol).
+		Where("f."+whereCol+" = ? AND u.valid = ?", userId, model.UserValid)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		model.User
+		FollowedAt time.Time
+	}
+	err := query.Select("u.*, f.created AS followed_at").
+		Order("f.created desc, f.id desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]*model.FollowUser, 0, len(rows))
+	for i := range rows {
+		users = append(users, &model.FollowUser{PublicUser: rows[i].User.Public(), FollowedAt: rows[i].FollowedAt})
+	}
+	return users, total, nil
+}
@@ -8,6 +8,8 @@ import (
 
 type NotificationRepository interface {
 	Create(notify *model.Notification) error
+	// [NEW] 批量创建 (给全部粉丝发通知)
+	CreateBatch(list []*model.Notification) error
 	// [NEW] 分页获取我的通知
 	GetPage(receiverId, page, rows int) ([]*model.Notification, int64, error)
 	// [NEW] 获取未读数量
@@ -16,6 +18,15 @@ type NotificationRepository interface {
 	UpdateStatus(id int, status int) error
 	// [NEW] 标记所有为已读
 	MarkAllRead(receiverId int) error
+
+	// [NEW] 个人数据导出 / 注销账号
+	FindAllByReceiverId(receiverId int) ([]*model.Notification, error)
+	DeleteByReceiverId(receiverId int) error
+	DeleteBySenderId(senderId int) error
+	AnonymizeSender(senderId int, name string) error
+	// 删除指向已删除文章 / 评论的通知 (避免点开后 404)
+	DeleteByArticleIds(articleIds []int) error
+	DeleteByCommentIds(commentIds []int) error
 }
 
 type notificationRepository struct {
@@ -30,6 +41,14 @@ func (r *notificationRepository) Create(notify *model.Notification) error {
 	return r.db.Create(notify).Error
 }
 
+// [NEW] 分批插入，避免粉丝很多时单条 SQL 过大
+func (r *notificationRepository) CreateBatch(list []*model.Notification) error {
+	if len(list) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(list, 500).Error
+}
+
 // 分页获取
 func (r *notificationRepository) GetPage(receiverId, page, rows int) ([]*model.Notification, int64, error) {
 	var list []*model.Notification
@@ -75,3 +94,44 @@ func (r *notificationRepository) MarkAllRead(receiverId int) error {
 		Where("receiver_id = ? AND status = ?", receiverId, 0).
 		Update("status", 1).Error
 }
+
+// [NEW] 实现 FindAllByReceiverId
+func (r *notificationRepository) FindAllByReceiverId(receiverId int) ([]*model.Notification, error) {
+	var list []*model.Notification
+	err := r.db.Where("receiver_id = ?", receiverId).Order("created asc").Find(&list).Error
+	return list, err
+}
+
+// [NEW] 实现 DeleteByReceiverId
+func (r *notificationRepository) DeleteByReceiverId(receiverId int) error {
+	return r.db.Where("receiver_id = ?", receiverId).Delete(&model.Notification{}).Error
+}
+
+// [NEW] 实现 DeleteBySenderId
+func (r *notificationRepository) DeleteBySenderId(senderId int) error {
+	return r.db.Where("sender_id = ?", senderId).Delete(&model.Notification{}).Error
+}
+
+// [NEW] 实现 AnonymizeSender
+func (r *notificationRepository) AnonymizeSender(senderId int, name string) error {
+	return r.db.Model(&model.Notification{}).Where("sender_id = ?", senderId).Updates(map[string]interface{}{
+		"sender_id":   0,
+		"sender_name": name,
+	}).Error
+}
+
+// [NEW] 实现 DeleteByArticleIds
+func (r *notificationRepository) DeleteByArticleIds(articleIds []int) error {
+	if len(articleIds) == 0 {
+		return nil
+	}
+	return r.db.Where("article_id IN ?", articleIds).Delete(&model.Notification{}).Error
+}
+
+// [NEW] 实现 DeleteByCommentIds
+func (r *notificationRepository) DeleteByCommentIds(commentIds []int) error {
+	if len(commentIds) == 0 {
+		return nil
+	}
+	return r.db.Where("comment_id IN ?", commentIds).Delete(&model.Notification{}).Error
+}
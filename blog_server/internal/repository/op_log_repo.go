@@ -9,6 +9,9 @@ import (
 type OpLogRepository interface {
 	FindAll(userId int, page, pageSize int) ([]model.OpLog, int64, error)
 	Create(opLog *model.OpLog) error
+	// [NEW] 个人数据导出 / 注销账号
+	FindAllByUserId(userId int) ([]model.OpLog, error)
+	DeleteByUserId(userId int) error
 }
 
 type opLogRepository struct {
@@ -43,3 +46,15 @@ func (r *opLogRepository) FindAll(userId int, page, pageSize int) ([]model.OpLog
 func (r *opLogRepository) Create(opLog *model.OpLog) error {
 	return r.db.Create(opLog).Error
 }
+
+// [NEW] 实现 FindAllByUserId
+func (r *opLogRepository) FindAllByUserId(userId int) ([]model.OpLog, error) {
+	var logs []model.OpLog
+	err := r.db.Where("user_id = ?", userId).Order("created asc").Find(&logs).Error
+	return logs, err
+}
+
+// [NEW] 实现 DeleteByUserId
+func (r *opLogRepository) DeleteByUserId(userId int) error {
+	return r.db.Where("user_id = ?", userId).Delete(&model.OpLog{}).Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"my-blog/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasskeyRepository interface {
+	Create(passkey *model.Passkey) error
+	FindById(id int) (*model.Passkey, error)
+	FindByUserId(userId int) ([]*model.Passkey, error)
+	FindByCredentialId(credentialId string) (*model.Passkey, error)
+	// 登录成功后更新凭证 (签名计数) 和最后使用时间
+	UpdateCredential(id int, credential string, lastUsed time.Time) error
+	UpdateName(id int, name string) error
+	Delete(id int) error
+	DeleteByUserId(userId int) error
+}
+
+type passkeyRepository struct {
+	db *gorm.DB
+}
+
+func NewPasskeyRepository(db *gorm.DB) PasskeyRepository {
+	return &passkeyRepository{db: db}
+}
+
+func (r *passkeyRepository) Create(passkey *model.Passkey) error {
+	return r.db.Create(passkey).Error
+}
+
+func (r *passkeyRepository) FindById(id int) (*model.Passkey, error) {
+	var passkey model.Passkey
+	if err := r.db.First(&passkey, id).Error; err != nil {
+		return nil, err
+	}
+	return &passkey, nil
+}
+
+func (r *passkeyRepository) FindByUserId(userId int) ([]*model.Passkey, error) {
+	var list []*model.Passkey
+	err := r.db.Where("user_id = ?", userId).Order("created asc").Find(&list).Error
+	return list, err
+}
+
+func (r *passkeyRepository) FindByCredentialId(credentialId string) (*model.Passkey, error) {
+	var passkey model.Passkey
+	if err := r.db.Where("credential_id = ?", credentialId).First(&passkey).Error; err != nil {
+		return nil, err
+	}
+	return &passkey, nil
+}
+
+func (r *passkeyRepository) UpdateCredential(id int, credential string, lastUsed time.Time) error {
+	return r.db.Model(&model.Passkey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"credential": credential,
+		"last_used":  lastUsed,
+	}).Error
+}
+
+func (r *passkeyRepository) UpdateName(id int, name string) error {
+	return r.db.Model(&model.Passkey{}).Where("id = ?", id).Update("name", name).Error
+}
+
+func (r *passkeyRepository) Delete(id int) error {
+	return r.db.Delete(&model.Passkey{}, id).Error
+}
+
+func (r *passkeyRepository) DeleteByUserId(userId int) error {
+	return r.db.Where("user_id = ?", userId).Delete(&model.Passkey{}).Error
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"my-blog/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepository interface {
+	// 记录一次密码，并只保留最近 keep 条 (事务)
+	Add(userId int, password string, keep int) error
+	// 最近 limit 次的密码哈希 (新的在前)
+	FindRecent(userId int, limit int) ([]string, error)
+	DeleteByUserId(userId int) error
+}
+
+type passwordHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+func (r *passwordHistoryRepository) Add(userId int, password string, keep int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		history := &model.PasswordHistory{UserId: userId, Password: password, Created: time.Now()}
+		if err := tx.Create(history).Error; err != nil {
+			return err
+		}
+
+		// 删除最近 keep 条之外的
+		var keepIds []int
+		err := tx.Model(&model.PasswordHistory{}).
+			Where("user_id = ?", userId).
+			Order("id desc").
+			Limit(keep).
+			Pluck("id", &keepIds).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND id NOT IN ?", userId, keepIds).Delete(&model.PasswordHistory{}).Error
+	})
+}
+
+func (r *passwordHistoryRepository) FindRecent(userId int, limit int) ([]string, error) {
+	var hashes []string
+	err := r.db.Model(&model.PasswordHistory{}).
+		Where("user_id = ?", userId).
+		Order("id desc").
+		Limit(limit).
+		Pluck("password", &hashes).Error
+	return hashes, err
+}
+
+func (r *passwordHistoryRepository) DeleteByUserId(userId int) error {
+	return r.db.Where("user_id = ?", userId).Delete(&model.PasswordHistory{}).Error
+}
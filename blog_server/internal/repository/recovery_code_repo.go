@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"my-blog/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository interface {
+	// 覆盖式重新生成 (事务：删旧插新)
+	ReplaceForUser(userId int, hashes []string) error
+	// 查找未使用的恢复码
+	FindUnused(userId int, hash string) (*model.RecoveryCode, error)
+	MarkUsed(id int) error
+	CountUnused(userId int) (int64, error)
+	DeleteByUserId(userId int) error
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepository{db: db}
+}
+
+func (r *recoveryCodeRepository) ReplaceForUser(userId int, hashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, hash := range hashes {
+			code := &model.RecoveryCode{UserId: userId, CodeHash: hash, Created: now}
+			if err := tx.Create(code).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *recoveryCodeRepository) FindUnused(userId int, hash string) (*model.RecoveryCode, error) {
+	var code model.RecoveryCode
+	err := r.db.Where("user_id = ? AND code_hash = ? AND used = ?", userId, hash, 0).First(&code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// 标记已使用 (带 used = 0 条件，防止并发重复使用)
+func (r *recoveryCodeRepository) MarkUsed(id int) error {
+	result := r.db.Model(&model.RecoveryCode{}).
+		Where("id = ? AND used = ?", id, 0).
+		Updates(map[string]interface{}{"used": 1, "used_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *recoveryCodeRepository) CountUnused(userId int) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.RecoveryCode{}).Where("user_id = ? AND used = ?", userId, 0).Count(&count).Error
+	return count, err
+}
+
+func (r *recoveryCodeRepository) DeleteByUserId(userId int) error {
+	return r.db.Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error
+}
@@ -7,7 +7,8 @@ import (
 )
 
 type ReplyRepository interface {
-	GetRepliesByCommentId(commentId int) ([]model.Reply, error)
+	// [MODIFY] viewerId: 当前用户，不返回其拉黑 / 屏蔽的用户的回复 (游客传 0)
+	GetRepliesByCommentId(commentId, viewerId int) ([]model.Reply, error)
 	CreateReply(reply *model.Reply) error
 	DeleteByCommentId(commentId int) error
 
@@ -16,6 +17,21 @@ type ReplyRepository interface {
 	AddReplyLike(like *model.ReplyLike) error
 	DeleteReplyLike(userId, replyId int) error
 	UpdateReplyLikesCount(replyId int, step int) error
+
+	// [NEW] 个人数据导出 / 注销账号
+	FindAllByUserId(userId int) ([]model.Reply, error)
+	FindLikesByUserId(userId int) ([]model.LikeRecord, error)
+	// updateCount 为 true 时同时扣减回复的点赞数
+	DeleteLikesByUserId(userId int, updateCount bool) error
+	// 匿名化用户写的回复，以及别人回复该用户时的目标用户
+	AnonymizeByUserId(userId int, author string) error
+	// 删除回复及其点赞
+	DeleteByUserId(userId int) error
+	DeleteByCommentIds(commentIds []int) error
+
+	// [NEW] 删除单条回复 (作者本人或管理员)
+	FindById(id int) (*model.Reply, error)
+	DeleteById(id int) error
 }
 
 type replyRepository struct {
@@ -26,9 +42,11 @@ func NewReplyRepository(db *gorm.DB) ReplyRepository {
 	return &replyRepository{db: db}
 }
 
-func (r *replyRepository) GetRepliesByCommentId(commentId int) ([]model.Reply, error) {
+func (r *replyRepository) GetRepliesByCommentId(commentId, viewerId int) ([]model.Reply, error) {
 	var replies []model.Reply
-	err := r.db.Where("comment_id = ?", commentId).Order("created asc").Find(&replies).Error
+	err := r.db.Where("comment_id = ?", commentId).
+		Scopes(HiddenFor(viewerId, "user_id")).
+		Order("created asc").Find(&replies).Error
 	return replies, err
 }
 
@@ -56,3 +74,90 @@ func (r *replyRepository) UpdateReplyLikesCount(replyId int, step int) error {
 	return r.db.Model(&model.Reply{}).Where("id = ?", replyId).
 		UpdateColumn("likes", gorm.Expr("likes + ?", step)).Error
 }
+
+// [NEW] 实现 FindAllByUserId
+func (r *replyRepository) FindAllByUserId(userId int) ([]model.Reply, error) {
+	var replies []model.Reply
+	err := r.db.Where("user_id = ?", userId).Order("created asc").Find(&replies).Error
+	return replies, err
+}
+
+// [NEW] 实现 FindLikesByUserId
+func (r *replyRepository) FindLikesByUserId(userId int) ([]model.LikeRecord, error) {
+	var likes []model.LikeRecord
+	err := r.db.Model(&model.ReplyLike{}).
+		Select("reply_id AS target_id, created").
+		Where("user_id = ?", userId).
+		Order("created asc").
+		Scan(&likes).Error
+	return likes, err
+}
+
+// [NEW] 实现 DeleteLikesByUserId
+func (r *replyRepository) DeleteLikesByUserId(userId int, updateCount bool) error {
+	if updateCount {
+		liked := r.db.Model(&model.ReplyLike{}).Select("reply_id").Where("user_id = ?", userId)
+		err := r.db.Model(&model.Reply{}).
+			Where("id IN (?)", liked).
+			UpdateColumn("likes", gorm.Expr("GREATEST(likes - 1, 0)")).Error
+		if err != nil {
+			return err
+		}
+	}
+	return r.db.Where("user_id = ?", userId).Delete(&model.ReplyLike{}).Error
+}
+
+// [NEW] 实现 AnonymizeByUserId
+func (r *replyRepository) AnonymizeByUserId(userId int, author string) error {
+	err := r.db.Model(&model.Reply{}).Where("user_id = ?", userId).Updates(map[string]interface{}{
+		"user_id":  0,
+		"author":   author,
+		"ip":       "",
+		"location": "",
+	}).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Model(&model.Reply{}).Where("to_uid = ?", userId).Updates(map[string]interface{}{
+		"to_uid":        0,
+		"target_author": author,
+	}).Error
+}
+
+// [NEW] 实现 DeleteByUserId
+func (r *replyRepository) DeleteByUserId(userId int) error {
+	replies := r.db.Model(&model.Reply{}).Select("id").Where("user_id = ?", userId)
+	if err := r.db.Where("reply_id IN (?)", replies).Delete(&model.ReplyLike{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("user_id = ?", userId).Delete(&model.Reply{}).Error
+}
+
+// [NEW] 实现 DeleteByCommentIds
+func (r *replyRepository) DeleteByCommentIds(commentIds []int) error {
+	if len(commentIds) == 0 {
+		return nil
+	}
+	replies := r.db.Model(&model.Reply{}).Select("id").Where("comment_id IN ?", commentIds)
+	if err := r.db.Where("reply_id IN (?)", replies).Delete(&model.ReplyLike{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("comment_id IN ?", commentIds).Delete(&model.Reply{}).Error
+}
+
+// [NEW] 实现 FindById
+func (r *replyRepository) FindById(id int) (*model.Reply, error) {
+	var reply model.Reply
+	if err := r.db.First(&reply, id).Error; err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// [NEW] 实现 DeleteById (连同点赞)
+func (r *replyRepository) DeleteById(id int) error {
+	if err := r.db.Where("reply_id = ?", id).Delete(&model.ReplyLike{}).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(&model.Reply{}, id).Error
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"my-blog/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	FindAll() ([]*model.Role, error)
+	FindByCodes(codes []string) ([]*model.Role, error)
+	// 查询用户拥有的角色 (按 id 升序，admin 永远排在最前)
+	FindByUserId(userId int) ([]*model.Role, error)
+	// 查询一组角色拥有的权限编码 (已去重)
+	FindPermissionCodes(roleIds []int) ([]string, error)
+	// 覆盖式设置用户角色 (事务)
+	SetUserRoles(userId int, roleIds []int) error
+	// [NEW] 注销账号时删除用户的全部角色
+	DeleteUserRoles(userId int) error
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) FindAll() ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.Order("id asc").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) FindByCodes(codes []string) ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.Where("code IN ?", codes).Order("id asc").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) FindByUserId(userId int) ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.Model(&model.Role{}).
+		Joins("JOIN t_user_role ON t_user_role.role_id = t_role.id").
+		Where("t_user_role.user_id = ?", userId).
+		Order("t_role.id asc").
+		Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) FindPermissionCodes(roleIds []int) ([]string, error) {
+	var codes []string
+	if len(roleIds) == 0 {
+		return codes, nil
+	}
+	err := r.db.Model(&model.Permission{}).
+		Distinct("t_permission.code").
+		Joins("JOIN t_role_permission ON t_role_permission.permission_id = t_permission.id").
+		Where("t_role_permission.role_id IN ?", roleIds).
+		Pluck("t_permission.code", &codes).Error
+	return codes, err
+}
+
+func (r *roleRepository) SetUserRoles(userId int, roleIds []int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+		for _, roleId := range roleIds {
+			if err := tx.Create(&model.UserRole{UserId: userId, RoleId: roleId}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// [NEW] 实现 DeleteUserRoles
+func (r *roleRepository) DeleteUserRoles(userId int) error {
+	return r.db.Where("user_id = ?", userId).Delete(&model.UserRole{}).Error
+}
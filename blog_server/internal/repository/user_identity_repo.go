@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"my-blog/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	Create(identity *model.UserIdentity) error
+	FindByProviderSubject(provider, subject string) (*model.UserIdentity, error)
+	FindByUserId(userId int) ([]*model.UserIdentity, error)
+	UpdateLastLogin(id int, t time.Time) error
+	Delete(userId int, provider string) error
+	DeleteByUserId(userId int) error
+}
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) Create(identity *model.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *userIdentityRepository) FindByProviderSubject(provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepository) FindByUserId(userId int) ([]*model.UserIdentity, error) {
+	var list []*model.UserIdentity
+	err := r.db.Where("user_id = ?", userId).Order("created asc").Find(&list).Error
+	return list, err
+}
+
+func (r *userIdentityRepository) UpdateLastLogin(id int, t time.Time) error {
+	return r.db.Model(&model.UserIdentity{}).Where("id = ?", id).Update("last_login", t).Error
+}
+
+func (r *userIdentityRepository) Delete(userId int, provider string) error {
+	result := r.db.Where("user_id = ? AND provider = ?", userId, provider).Delete(&model.UserIdentity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *userIdentityRepository) DeleteByUserId(userId int) error {
+	return r.db.Where("user_id = ?", userId).Delete(&model.UserIdentity{}).Error
+}
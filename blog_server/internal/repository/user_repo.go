@@ -17,6 +17,20 @@ type UserRepository interface {
 
 	// [NEW] 更新用户
 	Update(user *model.User) error
+	// [NEW] 更新两步验证状态
+	UpdateTotp(userId int, secret string, enabled int) error
+	// [NEW] 只更新邮箱 (唯一索引冲突时返回 gorm.ErrDuplicatedKey)
+	UpdateEmail(userId int, email string) error
+	// [NEW] 后台用户管理
+	Search(cond *model.UserCondition, page, pageSize int) ([]*model.User, int64, error)
+	UpdateValid(userId, valid int) error
+	UpdatePassword(userId int, password string) error
+	CountActivity(userId int) (*model.UserActivity, error)
+	// [NEW] 个人主页
+	UpdateProfile(userId int, form *model.ProfileForm) error
+	CountProfileStats(userId int) (*model.UserProfileStats, error)
+	// [NEW] 注销账号 (关联数据由 AccountRepository 统一删除)
+	Delete(userId int) error
 }
 
 // 结构体实现
@@ -76,3 +90,167 @@ func (r *userRepository) FindByEmail(email string) (*model.User, error) {
 func (r *userRepository) Update(user *model.User) error {
 	return r.db.Save(user).Error
 }
+
+// [NEW] 更新两步验证状态 (只更新这两列)
+func (r *userRepository) UpdateTotp(userId int, secret string, enabled int) error {
+	return r.db.Model(&model.User{}).Where("id = ?", userId).Updates(map[string]interface{}{
+		"totp_secret":  secret,
+		"totp_enabled": enabled,
+	}).Error
+}
+
+// [NEW] 实现 UpdateEmail
+func (r *userRepository) UpdateEmail(userId int, email string) error {
+	return r.db.Model(&model.User{}).Where("id = ?", userId).Update("email", email).Error
+}
+
+// [NEW] 实现 Search (分页，按注册时间倒序)
+func (r *userRepository) Search(cond *model.UserCondition, page, pageSize int) ([]*model.User, int64, error) {
+	query := r.db.Model(&model.User{})
+	if cond != nil {
+		if cond.Username != "" {
+			query = query.Where("username LIKE ?", "%"+cond.Username+"%")
+		}
+		if cond.Email != "" {
+			query = query.Where("email LIKE ?", "%"+cond.Email+"%")
+		}
+		if cond.CreatedFrom != nil {
+			query = query.Where("created >= ?", *cond.CreatedFrom)
+		}
+		if cond.CreatedTo != nil {
+			query = query.Where("created <= ?", *cond.CreatedTo)
+		}
+		if cond.Valid != nil {
+			query = query.Where("valid = ?", *cond.Valid)
+		}
+		if cond.Role != "" {
+			query = query.Where("id IN (?)", r.db.Table("t_user_role").
+				Select("t_user_role.user_id").
+				Joins("JOIN t_role ON t_role.id = t_user_role.role_id").
+				Where("t_role.code = ?", cond.Role))
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []*model.User
+	err := query.Order("created desc, id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&users).Error
+	return users, total, err
+}
+
+// [NEW] 实现 UpdateValid
+func (r *userRepository) UpdateValid(userId, valid int) error {
+	return r.db.Model(&model.User{}).Where("id = ?", userId).Update("valid", valid).Error
+}
+
+// [NEW] 实现 UpdatePassword (传入的是加密后的密码)
+func (r *userRepository) UpdatePassword(userId int, password string) error {
+	return r.db.Model(&model.User{}).Where("id = ?", userId).Update("password", password).Error
+}
+
+// [NEW] 实现 CountActivity
+func (r *userRepository) CountActivity(userId int) (*model.UserActivity, error) {
+	a := &model.UserActivity{}
+	counts := []struct {
+		table string
+		dest  *int64
+	}{
+		{"t_article", &a.Articles},
+		{"t_comment", &a.Comments},
+		{"t_reply", &a.Replies},
+		{"t_article_like", &a.ArticleLikes},
+	}
+	for _, c := range counts {
+		if err := r.db.Table(c.table).Where("user_id = ?", userId).Count(c.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var commentLikes, replyLikes int64
+	if err := r.db.Table("t_comment_like").Where("user_id = ?", userId).Count(&commentLikes).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Table("t_reply_like").Where("user_id = ?", userId).Count(&replyLikes).Error; err != nil {
+		return nil, err
+	}
+	a.CommentLikes = commentLikes + replyLikes
+
+	received, err := r.countLikesReceived(userId)
+	if err != nil {
+		return nil, err
+	}
+	a.LikesReceived = received
+
+	var lastLog model.OpLog
+	if err := r.db.Where("user_id = ?", userId).Order("created desc").Limit(1).Find(&lastLog).Error; err != nil {
+		return nil, err
+	}
+	if lastLog.Id > 0 {
+		a.LastActive = &lastLog.Created
+	}
+	return a, nil
+}
+
+// [NEW] 实现 Delete
+func (r *userRepository) Delete(userId int) error {
+	return r.db.Delete(&model.User{}, userId).Error
+}
+
+// [NEW] 实现 UpdateProfile (只更新公开资料这几列，空值也会写入)
+func (r *userRepository) UpdateProfile(userId int, form *model.ProfileForm) error {
+	return r.db.Model(&model.User{Id: userId}).
+		Select("nickname", "bio", "website", "location", "cover", "social_links").
+		Updates(&model.User{
+			Nickname:    form.Nickname,
+			Bio:         form.Bio,
+			Website:     form.Website,
+			Location:    form.Location,
+			Cover:       form.Cover,
+			SocialLinks: form.SocialLinks,
+		}).Error
+}
+
+// [NEW] 实现 CountProfileStats
+func (r *userRepository) CountProfileStats(userId int) (*model.UserProfileStats, error) {
+	stats := &model.UserProfileStats{}
+	if err := r.db.Table("t_article").Where("user_id = ?", userId).Scopes(publishedArticles).Count(&stats.Articles).Error; err != nil {
+		return nil, err
+	}
+
+	var comments, replies int64
+	if err := r.db.Table("t_comment").Where("user_id = ?", userId).Count(&comments).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Table("t_reply").Where("user_id = ?", userId).Count(&replies).Error; err != nil {
+		return nil, err
+	}
+	stats.Comments = comments + replies
+
+	if err := r.db.Table("t_user_follow").Where("followee_id = ?", userId).Count(&stats.Followers).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Table("t_user_follow").Where("follower_id = ?", userId).Count(&stats.Following).Error; err != nil {
+		return nil, err
+	}
+
+	received, err := r.countLikesReceived(userId)
+	if err != nil {
+		return nil, err
+	}
+	stats.LikesReceived = received
+	return stats, nil
+}
+
+// --- Helper Functions ---
+
+// 收到的点赞：自己文章 (t_statistic)、评论和回复上的 likes 之和
+func (r *userRepository) countLikesReceived(userId int) (int64, error) {
+	var received struct{ Total int64 }
+	err := r.db.Raw(`SELECT
+		(SELECT COALESCE(SUM(s.likes), 0) FROM t_statistic s JOIN t_article a ON a.id = s.article_id WHERE a.user_id = ?) +
+		(SELECT COALESCE(SUM(likes), 0) FROM t_comment WHERE user_id = ?) +
+		(SELECT COALESCE(SUM(likes), 0) FROM t_reply WHERE user_id = ?) AS total`, userId, userId, userId).Scan(&received).Error
+	return received.Total, err
+}
@@ -1,11 +1,14 @@
 package router
 
 import (
+	"log"
 	"my-blog/config"
 	"my-blog/internal/controller"
 	"my-blog/internal/middleware"
+	"my-blog/internal/model"
 	"my-blog/internal/repository"
 	"my-blog/internal/service"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -44,6 +47,12 @@ func InitRouter() *gin.Engine {
 
 	// 初始化 Redis (这一步别忘了！)
 	config.InitRedis()
+	// [NEW] 加载 JWT 签名密钥 (之后每分钟自动重新加载)
+	config.InitJwtKeys()
+	// [NEW] 加载泄露密码库
+	config.InitPasswordPolicy()
+	// [NEW] 加载搜索分词的用户词典
+	config.InitSearchDict()
 
 	// --- Repository 层 (数据访问) ---
 	userRepo := repository.NewUserRepository(db)
@@ -57,31 +66,86 @@ func InitRouter() *gin.Engine {
 	opLogRepo := repository.NewOpLogRepository(db) // [NEW]
 	// [NEW]
 	categoryRepo := repository.NewCategoryRepository(db)
+	roleRepo := repository.NewRoleRepository(db) // [NEW] 角色权限
+	// [NEW] 两步验证恢复码
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	passkeyRepo := repository.NewPasskeyRepository(db) // [NEW] 通行密钥
+	// [NEW] 第三方账号绑定
+	identityRepo := repository.NewUserIdentityRepository(db)
+	accessTokenRepo := repository.NewAccessTokenRepository(db) // [NEW] 个人访问令牌
+	// [NEW] 数据导出、注销账号 (在一个事务里操作上面所有的 Repo)
+	accountRepo := repository.NewAccountRepository(db)
+	passwordHistoryRepo := repository.NewPasswordHistoryRepository(db) // [NEW] 历史密码
+	followRepo := repository.NewFollowRepository(db)                   // [NEW] 关注
+	blockRepo := repository.NewBlockRepository(db)                     // [NEW] 拉黑 / 屏蔽
+	revisionRepo := repository.NewArticleRevisionRepository(db)        // [NEW] 文章历史版本
+	slugRepo := repository.NewArticleSlugRepository(db)                // [NEW] 文章旧 slug
 
 	// --- Service 层 (业务逻辑) ---
 	// [NEW] Service (新增 MailService)
 	mailSvc := service.NewMailService()
-	// [MODIFY] UserService 注入 MailService
-	userSvc := service.NewUserService(userRepo, mailSvc)
+	// [NEW] RoleService (UserService 签发 Token 时需要)
+	roleSvc := service.NewRoleService(roleRepo, userRepo)
+	// [NEW] TokenService (签发、刷新、吊销)
+	tokenSvc := service.NewTokenService(userRepo, roleSvc, accessTokenRepo)
+	// [NEW] 登录防爆破 (失败计数、验证码、锁定)
+	loginGuardSvc := service.NewLoginGuardService(mailSvc)
+	// [NEW] 两步验证 (TOTP + 恢复码)，验证码错误计入登录失败次数
+	mfaSvc := service.NewMfaService(userRepo, recoveryCodeRepo, roleSvc, loginGuardSvc)
+	// [NEW] 通行密钥 (WebAuthn)
+	passkeySvc := service.NewPasskeyService(passkeyRepo, userRepo)
+	// [NEW] 第三方登录 (OAuth2 / OIDC)
+	oauthSvc := service.NewOAuthService(userRepo, identityRepo, roleSvc)
+	// [NEW] 个人访问令牌 (API Token)
+	accessTokenSvc := service.NewAccessTokenService(accessTokenRepo, userRepo, roleSvc)
+	// [NEW] 后台用户管理 (封禁、重置密码、修改角色)
+	adminUserSvc := service.NewAdminUserService(userRepo, roleSvc, tokenSvc, mailSvc)
+	// [NEW] 个人数据导出、注销账号
+	accountSvc := service.NewAccountService(accountRepo, userRepo, roleSvc, tokenSvc, mailSvc)
+	// [NEW] 个人主页
+	profileSvc := service.NewProfileService(userRepo, articleRepo)
+	followSvc := service.NewFollowService(followRepo, userRepo, articleRepo, blockRepo) // [NEW] 关注
+	blockSvc := service.NewBlockService(blockRepo, userRepo)                            // [NEW] 拉黑 / 屏蔽
+	// [NEW] 密码策略 (强度、泄露密码库、历史密码)
+	passwordSvc := service.NewPasswordService(passwordHistoryRepo)
+	// [MODIFY] UserService 注入 MailService、RoleService、TokenService、LoginGuardService、PasswordService 以及各种登录方式
+	userSvc := service.NewUserService(userRepo, mailSvc, roleSvc, tokenSvc, loginGuardSvc, mfaSvc, passkeySvc, oauthSvc, passwordSvc)
+	renderSvc := service.NewRenderService() // [NEW] 正文渲染 (Markdown -> HTML、目录、阅读时间)
+	// [NEW] 全文搜索：启动时建索引，之后每 10 分钟全量重建一次
+	searchSvc := service.NewSearchService(articleRepo, tagRepo)
+	service.StartSearchIndexer(searchSvc, 10*time.Minute)
 	// [NEW] ArticleService 现在需要注入两个 Repo (Article + Tag)
 	// 🔴 [MODIFIED] 这里必须传入 notifyRepo
 	//原来: articleSvc := service.NewArticleService(articleRepo, tagRepo, notifyRepo, commentRepo)
-	articleSvc := service.NewArticleService(articleRepo, tagRepo, notifyRepo, commentRepo, categoryRepo)
+	articleSvc := service.NewArticleService(articleRepo, tagRepo, notifyRepo, commentRepo, categoryRepo, followRepo, blockRepo, revisionRepo, renderSvc, slugRepo, searchSvc)
+	// [NEW] 后台定时发布 (每 30 秒检查一次)
+	service.StartArticleScheduler(articleSvc, 30*time.Second)
+	// [NEW] 给以前的文章补上 slug
+	go func() {
+		if n, err := articleSvc.FillMissingSlugs(); err != nil {
+			log.Printf("⚠️ 生成文章 slug 失败: %v", err)
+		} else if n > 0 {
+			log.Printf("✅ 已为 %d 篇文章生成 slug", n)
+		}
+	}()
 	// [NEW] 注意这里注入了 userRepo，因为 Service 里要查用户头像
 	// CommentService: 需要 ReplyRepo 用于级联删除
-	commentSvc := service.NewCommentService(commentRepo, userRepo, notifyRepo, articleRepo, replyRepo)
+	commentSvc := service.NewCommentService(commentRepo, userRepo, notifyRepo, articleRepo, replyRepo, blockRepo)
 	// [NEW] 通知 Service
 	notifySvc := service.NewNotificationService(notifyRepo)
 	// ReplyService: 独立
-	replySvc := service.NewReplyService(replyRepo, userRepo, commentRepo, notifyRepo, articleRepo)
+	replySvc := service.NewReplyService(replyRepo, userRepo, commentRepo, notifyRepo, articleRepo, blockRepo)
 	opLogSvc := service.NewOpLogService(opLogRepo) // [NEW]
 	// [NEW] 注入 ArticleRepo 以便级联操作文章
-	categorySvc := service.NewCategoryService(categoryRepo, articleRepo)
+	categorySvc := service.NewCategoryService(categoryRepo, articleRepo, searchSvc)
+	revisionSvc := service.NewArticleRevisionService(revisionRepo, articleRepo, slugRepo, searchSvc) // [NEW] 文章历史版本
 
 	// --- Controller 层 (接口入口) ---
-	userCtrl := controller.NewUserController(userSvc)
+	userCtrl := controller.NewUserController(userSvc, tokenSvc)
 	// [MODIFIED] ArticleController 现在需要注入 commentSvc 了！！！
 	articleCtrl := controller.NewArticleController(articleSvc, commentSvc)
+	revisionCtrl := controller.NewArticleRevisionController(revisionSvc) // [NEW]
+	highlightCtrl := controller.NewHighlightController()                 // [NEW] 代码高亮主题
 	fileCtrl := new(controller.FileController)
 	// [NEW]
 	commentCtrl := controller.NewCommentController(commentSvc)
@@ -91,11 +155,30 @@ func InitRouter() *gin.Engine {
 	opLogCtrl := controller.NewOpLogController(opLogSvc) // [NEW]
 	// [NEW]
 	categoryCtrl := controller.NewCategoryController(categorySvc)
+	roleCtrl := controller.NewRoleController(roleSvc)                   // [NEW]
+	lockoutCtrl := controller.NewLockoutController(loginGuardSvc)       // [NEW]
+	mfaCtrl := controller.NewMfaController(mfaSvc, userSvc, tokenSvc)   // [NEW]
+	passkeyCtrl := controller.NewPasskeyController(passkeySvc, userSvc) // [NEW]
+	oauthCtrl := controller.NewOAuthController(oauthSvc, userSvc)       // [NEW]
+	// [NEW] 个人访问令牌
+	accessTokenCtrl := controller.NewAccessTokenController(accessTokenSvc)
+	// [NEW] 后台用户管理
+	adminUserCtrl := controller.NewAdminUserController(adminUserSvc)
+	// [NEW] 个人数据导出、注销账号
+	accountCtrl := controller.NewAccountController(accountSvc)
+	profileCtrl := controller.NewProfileController(profileSvc) // [NEW]
+	followCtrl := controller.NewFollowController(followSvc)    // [NEW]
+	blockCtrl := controller.NewBlockController(blockSvc)       // [NEW]
 
 	// ==========================================
 	// 4. 路由注册
 	// ==========================================
+	// [NEW] JWT 公钥 (JWKS)，供其他服务校验 Token
+	r.GET("/.well-known/jwks.json", controller.Jwks)
+
 	apiGroup := r.Group("/api")
+	// [NEW] 公开接口中识别当前用户 (已退出、被吊销、被封禁的 Token 按游客处理)
+	optionalAuth := middleware.OptionalAuth(tokenSvc, nil)
 	{
 		// ----------------------------------
 		// 用户模块 (User)
@@ -103,10 +186,28 @@ func InitRouter() *gin.Engine {
 		// 登录 (替换原来的假登录)
 		// 注意：Spring Security 默认拦截 /api/login，所以这里必须匹配
 		apiGroup.POST("/login", userCtrl.Login)
+		// [NEW] 登录第二步 (开启了两步验证的账号)
+		apiGroup.POST("/login/mfa", userCtrl.LoginMfa)
+		// [NEW] 邮件登录链接
+		apiGroup.POST("/user/sendMagicLink", userCtrl.SendMagicLink)
+		apiGroup.POST("/login/magic", userCtrl.LoginMagicLink)
+		// [NEW] 通行密钥登录 (免密码)
+		apiGroup.POST("/passkey/login/begin", passkeyCtrl.LoginBegin)
+		apiGroup.POST("/passkey/login/finish", passkeyCtrl.LoginFinish)
+		// [NEW] 第三方登录
+		apiGroup.GET("/oauth/providers", oauthCtrl.Providers)
+		apiGroup.GET("/oauth/:provider/authUrl", oauthCtrl.AuthURL)
+		apiGroup.GET("/oauth/:provider/callback", oauthCtrl.Callback)
+		apiGroup.POST("/oauth/login", oauthCtrl.Login)
+		// [NEW] 刷新 Token (Refresh Token 轮换)
+		apiGroup.POST("/token/refresh", userCtrl.RefreshToken)
 		// apiGroup.POST("/logout", userCtrl.Logout) // 退出
 		// apiGroup.GET("/user/currentUser", userCtrl.CurrentUser) // 获取当前用户
-		apiGroup.GET("/users", userCtrl.ListUsers)  // 用户列表
-		apiGroup.GET("/user/:id", userCtrl.GetUser) // 用户详情
+		// [MODIFY] 用户列表移到后台 (/admin/user/list)，仅管理员可见
+		apiGroup.GET("/user/:id", userCtrl.GetUser)               // 用户详情 (公开资料，不含邮箱)
+		apiGroup.GET("/user/:id/profile", profileCtrl.GetProfile) // [NEW] 个人主页
+		apiGroup.GET("/user/:id/followers", followCtrl.Followers) // [NEW] 粉丝列表
+		apiGroup.GET("/user/:id/following", followCtrl.Following) // [NEW] 关注列表
 
 		// 用户相关
 		apiGroup.GET("/user/captcha", userCtrl.Captcha)              // 图形验证码
@@ -114,6 +215,7 @@ func InitRouter() *gin.Engine {
 		apiGroup.POST("/user/register", userCtrl.Register)           // 注册
 		// [NEW] 重置密码
 		apiGroup.POST("/user/resetPassword", userCtrl.ResetPassword)
+		apiGroup.GET("/user/passwordPolicy", userCtrl.PasswordPolicy) // [NEW] 密码强度要求
 		// ----------------------------------
 		// 文件模块 (File)
 		// ----------------------------------
@@ -136,7 +238,7 @@ func InitRouter() *gin.Engine {
 		apiGroup.POST("/article/articleSearch", articleCtrl.ArticleSearch)
 
 		// [NEW] 二合一接口 (修复 404)
-		apiGroup.POST("/article/getArticleAndFirstPageCommentByArticleId", articleCtrl.GetArticleAndFirstPageCommentByArticleId)
+		apiGroup.POST("/article/getArticleAndFirstPageCommentByArticleId", optionalAuth, articleCtrl.GetArticleAndFirstPageCommentByArticleId)
 
 		// 2. 文章操作接口
 		apiGroup.POST("/article/getAPageOfArticle", articleCtrl.GetPage) // 分页查询
@@ -145,42 +247,92 @@ func InitRouter() *gin.Engine {
 
 		// 3. 通用详情与列表接口
 		// (这些放在最后，防止 "getAllTags" 被当成 id 解析)
-		apiGroup.GET("/articles", articleCtrl.List)      // 普通列表
-		apiGroup.GET("/article/:id", articleCtrl.Detail) // 文章详情
+		// [NEW] 按 slug 查文章 (旧链接 301 跳转)
+		apiGroup.GET("/article/slug/:slug", optionalAuth, articleCtrl.DetailBySlug)
+		// [NEW] 代码高亮主题 CSS
+		apiGroup.GET("/article/highlight.css", highlightCtrl.CSS)
+		apiGroup.GET("/article/highlight/styles", highlightCtrl.Styles)
+		apiGroup.GET("/articles", articleCtrl.List)                    // 普通列表
+		apiGroup.GET("/article/:id", optionalAuth, articleCtrl.Detail) // 文章详情
 
 		// 💬 Comment
-		apiGroup.POST("/comment/getAPageCommentByArticleId", commentCtrl.GetComments)
+		apiGroup.POST("/comment/getAPageCommentByArticleId", optionalAuth, commentCtrl.GetComments)
 		// apiGroup.POST("/comment/insert", commentCtrl.InsertComment)
 		// apiGroup.POST("/comment/likeComment", commentCtrl.LikeComment)
 
 		// 🗣️ Reply (注意：现在路由指向 replyCtrl，并且函数名严格对应 Controller 里的命名)
-		apiGroup.GET("/reply/getReplies", replyCtrl.GetReplies)
+		apiGroup.GET("/reply/getReplies", optionalAuth, replyCtrl.GetReplies)
 		// apiGroup.POST("/reply/insert", replyCtrl.InsertReply)  // 严格对应 InsertReply
 		// apiGroup.POST("/reply/likeReply", replyCtrl.LikeReply) // 严格对应 LikeReply
 		// [NEW] 注册文章点赞接口
 		// apiGroup.POST("/article/likeArticle", articleCtrl.LikeArticle)
 
 		// --- [NEW] 需要登录的接口组 ---
+		// [MODIFY] 只接受登录 Token
 		authGroup := apiGroup.Group("")
-		authGroup.Use(middleware.Auth())
+		authGroup.Use(middleware.Auth(tokenSvc, nil))
+		// [NEW] 同时接受个人访问令牌 (脚本、CI) 的接口组，每个接口都要用 RequireScope 声明授权范围
+		tokenGroup := apiGroup.Group("")
+		tokenGroup.Use(middleware.Auth(tokenSvc, accessTokenSvc))
 		{
 			// User
 			// [MODIFY] 修复路由名称，且移入 Auth 组以获取真实 UserID
 			authGroup.GET("/user/currentUser", userCtrl.CurrentUser)
 			authGroup.POST("/logout", userCtrl.Logout)
+			authGroup.POST("/logoutAll", userCtrl.LogoutAll) // [NEW] 退出所有设备
+			// [NEW] 登录设备管理
+			authGroup.GET("/user/sessions", userCtrl.ListSessions)
+			authGroup.POST("/user/session/revoke", userCtrl.RevokeSession)
+			// [NEW] 两步验证
+			authGroup.GET("/user/mfa/status", mfaCtrl.Status)
+			authGroup.POST("/user/mfa/setup", mfaCtrl.Setup)
+			authGroup.POST("/user/mfa/enable", mfaCtrl.Enable)
+			authGroup.POST("/user/mfa/disable", mfaCtrl.Disable)
+			authGroup.POST("/user/mfa/recoveryCodes", mfaCtrl.RegenerateRecoveryCodes)
+			// [NEW] 通行密钥管理
+			authGroup.POST("/user/passkey/register/begin", passkeyCtrl.RegisterBegin)
+			authGroup.POST("/user/passkey/register/finish", passkeyCtrl.RegisterFinish)
+			authGroup.GET("/user/passkey/list", passkeyCtrl.List)
+			authGroup.POST("/user/passkey/rename", passkeyCtrl.Rename)
+			authGroup.POST("/user/passkey/remove", passkeyCtrl.Remove)
+			// [NEW] 第三方账号绑定
+			authGroup.GET("/user/identities", oauthCtrl.ListIdentities)
+			authGroup.GET("/user/oauth/:provider/bindUrl", oauthCtrl.BindURL)
+			authGroup.POST("/user/identity/unbind", oauthCtrl.Unbind)
+			// [NEW] 个人访问令牌管理 (只能用登录 Token 操作)
+			authGroup.GET("/user/token/scopes", accessTokenCtrl.Scopes)
+			authGroup.GET("/user/token/list", accessTokenCtrl.List)
+			authGroup.POST("/user/token/create", accessTokenCtrl.Create)
+			authGroup.POST("/user/token/revoke", accessTokenCtrl.Revoke)
 
 			// Article (写操作)
-			authGroup.POST("/article/publishArticle", articleCtrl.Publish)
-			authGroup.POST("/article/deleteById", articleCtrl.Delete)
+			// [MODIFY] 读者账号不允许发布和删除文章
+			// [MODIFY] 支持个人访问令牌 (articles:write)
+			// [MODIFY] 编辑、删除只允许作者本人或管理员 (见 ArticleService)
+			articlesWrite := middleware.RequireScope(model.ScopeArticlesWrite)
+			tokenGroup.POST("/article/publishArticle", articlesWrite, middleware.RequirePermission(model.PermArticleWrite), articleCtrl.Publish)
+			tokenGroup.POST("/article/deleteById", articlesWrite, middleware.RequirePermission(model.PermArticleDelete), articleCtrl.Delete)
+			// [NEW] 写文章时根据内容推荐标签
+			tokenGroup.POST("/article/tag/suggest", articlesWrite, middleware.RequirePermission(model.PermArticleWrite), articleCtrl.SuggestTags)
 			authGroup.POST("/article/likeArticle", articleCtrl.LikeArticle) // 点赞
+			// [NEW] 历史版本 (作者本人或管理员，见 ArticleRevisionService)
+			articlesRead := middleware.RequireScope(model.ScopeArticlesRead)
+			tokenGroup.GET("/article/revision/list", articlesRead, revisionCtrl.List)
+			tokenGroup.GET("/article/revision/detail", articlesRead, revisionCtrl.Detail)
+			tokenGroup.GET("/article/revision/diff", articlesRead, revisionCtrl.Diff)
+			tokenGroup.POST("/article/revision/restore", articlesWrite, middleware.RequirePermission(model.PermArticleWrite), revisionCtrl.Restore)
 
 			// File
-			authGroup.POST("/file/upload", fileCtrl.Upload)
+			tokenGroup.POST("/file/upload", articlesWrite, fileCtrl.Upload)
 
 			// Comment & Reply
-			authGroup.POST("/comment/insert", commentCtrl.InsertComment)
+			commentsWrite := middleware.RequireScope(model.ScopeCommentsWrite)
+			tokenGroup.POST("/comment/insert", commentsWrite, commentCtrl.InsertComment)
 			authGroup.POST("/comment/likeComment", commentCtrl.LikeComment) // 点赞
-			authGroup.POST("/reply/insert", replyCtrl.InsertReply)
+			tokenGroup.POST("/reply/insert", commentsWrite, replyCtrl.InsertReply)
+			// [NEW] 删除评论 / 回复 (作者本人、管理员或有 comment:manage 权限的用户)
+			tokenGroup.POST("/comment/deleteById", commentsWrite, commentCtrl.DeleteComment)
+			tokenGroup.POST("/reply/deleteById", commentsWrite, replyCtrl.DeleteReply)
 			authGroup.POST("/reply/likeReply", replyCtrl.LikeReply) // 点赞
 
 			// 🔔 通知模块
@@ -202,10 +354,28 @@ func InitRouter() *gin.Engine {
 			// 1. 用户个人中心操作
 			authGroup.POST("/user/updateUser", userCtrl.UpdateUser)
 			authGroup.POST("/user/updatePassword", userCtrl.UpdatePassword)
+			authGroup.POST("/user/email/sendCode", userCtrl.SendChangeEmailCode) // [NEW] 修改邮箱 (验证新邮箱)
+			authGroup.POST("/user/email/change", userCtrl.ChangeEmail)
+			authGroup.POST("/user/profile/update", profileCtrl.UpdateProfile) // [NEW] 修改公开资料
+			// [NEW] 个人数据导出、注销账号
+			authGroup.GET("/user/account/export", accountCtrl.Export)
+			authGroup.POST("/user/account/sendDeleteCode", accountCtrl.SendDeleteCode)
+			authGroup.POST("/user/account/delete", accountCtrl.Delete)
+			// [NEW] 关注作者
+			authGroup.POST("/user/follow", followCtrl.Follow)
+			authGroup.POST("/user/unfollow", followCtrl.Unfollow)
+			authGroup.GET("/user/follow/status", followCtrl.Status)
+			// [NEW] 拉黑 / 屏蔽
+			authGroup.POST("/user/block", blockCtrl.Block)
+			authGroup.POST("/user/unblock", blockCtrl.Unblock)
+			authGroup.GET("/user/block/list", blockCtrl.List)
+			authGroup.GET("/user/block/status", blockCtrl.Status)
+			// [NEW] 关注动态 (游标翻页)
+			tokenGroup.GET("/user/feed", middleware.RequireScope(model.ScopeArticlesRead), followCtrl.Feed)
 
 			// 1. 我的文章 (POST)
 			// 原路径: /article/getAPageOfArticle (错) -> 修正为: /article/getMyArticles
-			authGroup.POST("/article/getMyArticles", articleCtrl.GetMyArticles)
+			tokenGroup.POST("/article/getMyArticles", middleware.RequireScope(model.ScopeArticlesRead), articleCtrl.GetMyArticles)
 
 			// 2. 我点赞的文章 (POST)
 			// 原路径: /article/getAPageOfMyLike (错) -> 修正为: /article/getMyLikedArticles
@@ -213,7 +383,7 @@ func InitRouter() *gin.Engine {
 
 			// 3. 我的评论 (POST)
 			// 原路径: /comment/getAPageOfMyComment (错) -> 修正为: /comment/getMyComments
-			authGroup.POST("/comment/getMyComments", commentCtrl.GetMyComments)
+			tokenGroup.POST("/comment/getMyComments", middleware.RequireScope(model.ScopeCommentsRead), commentCtrl.GetMyComments)
 
 			// 4. 我点赞的评论 (POST)
 			// 新增路径
@@ -227,10 +397,34 @@ func InitRouter() *gin.Engine {
 			// [NEW] Category Management (分类管理)
 			authGroup.GET("/category/getTree", categoryCtrl.GetTree)
 			authGroup.GET("/category/getResources", categoryCtrl.GetResources)
-			authGroup.POST("/category/add", categoryCtrl.Add)
-			authGroup.POST("/category/update", categoryCtrl.Update)
-			authGroup.POST("/category/updateBatch", categoryCtrl.UpdateBatch)
-			authGroup.POST("/category/delete", categoryCtrl.Delete)
+			// [MODIFY] 分类的写操作需要 category:manage 权限
+			categoryManage := middleware.RequirePermission(model.PermCategoryManage)
+			authGroup.POST("/category/add", categoryManage, categoryCtrl.Add)
+			authGroup.POST("/category/update", categoryManage, categoryCtrl.Update)
+			authGroup.POST("/category/updateBatch", categoryManage, categoryCtrl.UpdateBatch)
+			authGroup.POST("/category/delete", categoryManage, categoryCtrl.Delete)
+
+			// [NEW] 管理员接口 (角色分配)
+			adminGroup := authGroup.Group("/admin")
+			adminGroup.Use(middleware.RequireRole(model.RoleAdmin))
+			userManage := middleware.RequirePermission(model.PermUserManage)
+			{
+				adminGroup.GET("/role/list", roleCtrl.List)
+				adminGroup.GET("/user/roles", roleCtrl.GetUserRoles)
+				// [MODIFY] 修改角色：移除角色时强制该用户重新登录
+				adminGroup.POST("/user/assignRoles", userManage, adminUserCtrl.ChangeRoles)
+				// [NEW] 用户管理 (分页搜索、详情、封禁、强制重置密码)
+				adminGroup.POST("/user/list", adminUserCtrl.List)
+				adminGroup.GET("/user/detail", adminUserCtrl.Detail)
+				adminGroup.POST("/user/ban", userManage, adminUserCtrl.Ban)
+				adminGroup.POST("/user/unban", userManage, adminUserCtrl.Unban)
+				adminGroup.POST("/user/resetPassword", userManage, adminUserCtrl.ResetPassword)
+				// [NEW] 登录锁定
+				adminGroup.GET("/lockout/list", lockoutCtrl.List)
+				adminGroup.POST("/lockout/clear", userManage, lockoutCtrl.Clear)
+				// [NEW] 重置用户的两步验证
+				adminGroup.POST("/user/mfa/reset", userManage, mfaCtrl.Reset)
+			}
 		}
 	}
 
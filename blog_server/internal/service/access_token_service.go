@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	maxAccessTokensPerUser = 20          // 每个用户最多创建的令牌数
+	maxAccessTokenDays     = 365         // 最长有效期 (天)
+	accessTokenTouchGap    = time.Minute // 最后使用时间的更新间隔，避免每个请求都写库
+)
+
+// AccessTokenScope 可选的授权范围 (个人中心展示用)
+type AccessTokenScope struct {
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+}
+
+var accessTokenScopes = []AccessTokenScope{
+	{model.ScopeArticlesRead, "读取我的文章"},
+	{model.ScopeArticlesWrite, "发布、编辑、删除文章，上传图片"},
+	{model.ScopeCommentsRead, "读取我的评论"},
+	{model.ScopeCommentsWrite, "发表评论和回复"},
+}
+
+// AccessTokenIdentity 个人访问令牌认证通过后的身份
+type AccessTokenIdentity struct {
+	User        *model.User
+	Token       *model.AccessToken
+	Roles       []string
+	Permissions []string
+}
+
+type AccessTokenService interface {
+	Scopes() []AccessTokenScope
+	// 创建令牌，返回记录和明文令牌 (明文只展示这一次)
+	// expiresInDays 为 0 表示永不过期
+	Create(userId int, name string, scopes []string, expiresInDays int) (*model.AccessToken, string, error)
+	List(userId int) ([]*model.AccessToken, error)
+	Revoke(userId, id int) error
+	// 校验令牌 (Auth 中间件调用)，同时记录最后使用时间和 IP
+	Authenticate(token, ip string) (*AccessTokenIdentity, error)
+}
+
+type accessTokenService struct {
+	accessTokenRepo repository.AccessTokenRepository
+	userRepo        repository.UserRepository
+	roleService     RoleService
+}
+
+func NewAccessTokenService(
+	accessTokenRepo repository.AccessTokenRepository,
+	userRepo repository.UserRepository,
+	roleService RoleService,
+) AccessTokenService {
+	return &accessTokenService{
+		accessTokenRepo: accessTokenRepo,
+		userRepo:        userRepo,
+		roleService:     roleService,
+	}
+}
+
+func (s *accessTokenService) Scopes() []AccessTokenScope {
+	return accessTokenScopes
+}
+
+func (s *accessTokenService) Create(userId int, name string, scopes []string, expiresInDays int) (*model.AccessToken, string, error) {
+	// 1. 参数校验
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.New("请填写令牌名称")
+	}
+	if len([]rune(name)) > 50 {
+		return nil, "", errors.New("令牌名称不能超过50个字符")
+	}
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if expiresInDays < 0 || expiresInDays > maxAccessTokenDays {
+		return nil, "", errors.New("有效期不合法")
+	}
+	if count, _ := s.accessTokenRepo.CountByUserId(userId); count >= maxAccessTokensPerUser {
+		return nil, "", errors.New("令牌数量已达上限，请先删除不用的令牌")
+	}
+
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, "", errors.New("用户不存在")
+	}
+	// 令牌可以绕过两步验证，要求两步验证的角色必须先开启
+	roles, _, _ := s.roleService.GetUserAuthorities(userId)
+	if mfaRequiredFor(roles) && user.TotpEnabled != 1 {
+		return nil, "", errors.New("请先开启两步验证")
+	}
+
+	// 2. 生成令牌 (库中只存哈希)
+	random, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	plain := model.AccessTokenPrefix + random
+	now := time.Now()
+	token := &model.AccessToken{
+		UserId:    userId,
+		Name:      name,
+		TokenHash: hashToken(plain),
+		Prefix:    plain[:len(model.AccessTokenPrefix)+4],
+		Scopes:    strings.Join(scopes, ","),
+		Created:   now,
+	}
+	if expiresInDays > 0 {
+		expiresAt := now.AddDate(0, 0, expiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := s.accessTokenRepo.Create(token); err != nil {
+		return nil, "", errors.New("创建令牌失败")
+	}
+	return token, plain, nil
+}
+
+func (s *accessTokenService) List(userId int) ([]*model.AccessToken, error) {
+	return s.accessTokenRepo.FindByUserId(userId)
+}
+
+func (s *accessTokenService) Revoke(userId, id int) error {
+	token, err := s.accessTokenRepo.FindById(id)
+	if err != nil || token.UserId != userId {
+		return errors.New("令牌不存在")
+	}
+	return s.accessTokenRepo.Delete(id)
+}
+
+func (s *accessTokenService) Authenticate(plain, ip string) (*AccessTokenIdentity, error) {
+	token, err := s.accessTokenRepo.FindByHash(hashToken(plain))
+	if err != nil {
+		return nil, errors.New("API Token 无效")
+	}
+	now := time.Now()
+	if token.Expired(now) {
+		return nil, errors.New("API Token 已过期")
+	}
+
+	user, err := s.userRepo.FindById(token.UserId)
+	if err != nil || user.Valid != 1 {
+		return nil, errors.New("API Token 无效")
+	}
+	roles, perms, err := s.roleService.GetUserAuthorities(user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.LastUsed == nil || now.Sub(*token.LastUsed) >= accessTokenTouchGap || token.LastUsedIp != ip {
+		s.accessTokenRepo.UpdateLastUsed(token.Id, now, ip)
+	}
+
+	return &AccessTokenIdentity{
+		User:        user,
+		Token:       token,
+		Roles:       roles,
+		Permissions: perms,
+	}, nil
+}
+
+// --- Helper Functions ---
+
+// 校验并去重授权范围 (按预定义顺序排列)
+func normalizeScopes(scopes []string) ([]string, error) {
+	for _, scope := range scopes {
+		if !slices.ContainsFunc(accessTokenScopes, func(s AccessTokenScope) bool { return s.Scope == scope }) {
+			return nil, errors.New("不支持的授权范围: " + scope)
+		}
+	}
+	var list []string
+	for _, s := range accessTokenScopes {
+		if slices.Contains(scopes, s.Scope) {
+			list = append(list, s.Scope)
+		}
+	}
+	if len(list) == 0 {
+		return nil, errors.New("请至少选择一个授权范围")
+	}
+	return list, nil
+}
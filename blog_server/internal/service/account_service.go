@@ -0,0 +1,242 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"my-blog/config"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// [NEW] 个人数据导出、注销账号
+type AccountService interface {
+	// 导出我们保存的关于该用户的全部数据，format: json / zip
+	// 返回下载文件名和文件内容
+	Export(userId int, format string) (string, []byte, error)
+	// 注销账号前向账号邮箱发送验证码
+	SendDeleteCode(userId int) error
+	// 注销账号：有邮箱的账号校验邮箱验证码，没有邮箱的 (第三方登录注册) 校验密码
+	// mode: model.DeleteModeAnonymize / model.DeleteModeRemove
+	Delete(userId int, mode, code, password string) error
+}
+
+type accountService struct {
+	accountRepo  repository.AccountRepository
+	userRepo     repository.UserRepository
+	roleService  RoleService
+	tokenService TokenService
+	mailService  MailService
+}
+
+func NewAccountService(
+	accountRepo repository.AccountRepository,
+	userRepo repository.UserRepository,
+	roleService RoleService,
+	tokenService TokenService,
+	mailService MailService,
+) AccountService {
+	return &accountService{
+		accountRepo:  accountRepo,
+		userRepo:     userRepo,
+		roleService:  roleService,
+		tokenService: tokenService,
+		mailService:  mailService,
+	}
+}
+
+// Redis Key
+const (
+	exportLimitKey        = "export_limit:"         // + userId -> 1分钟内不能重复导出
+	deleteAccountKey      = "delete_account:"       // + userId -> Hash {code, attempts}
+	deleteAccountLimitKey = "delete_account_limit:" // + userId -> 1分钟内不能重复发送
+)
+
+const deleteAccountAttempts = 5 // 验证码最多尝试次数
+
+func (s *accountService) Export(userId int, format string) (string, []byte, error) {
+	if format != "json" && format != "zip" {
+		return "", nil, errors.New("不支持的导出格式")
+	}
+	uid := strconv.Itoa(userId)
+	if !config.RDB.SetNX(config.Ctx, exportLimitKey+uid, 1, time.Minute).Val() {
+		return "", nil, errors.New("导出过于频繁，请 1 分钟后再试")
+	}
+
+	data, err := s.accountRepo.Export(userId)
+	if err != nil {
+		config.RDB.Del(config.Ctx, exportLimitKey+uid)
+		return "", nil, errors.New("导出失败")
+	}
+	roles, _, _ := s.roleService.GetUserAuthorities(userId)
+	FillAuthorities(data.Profile, roles)
+
+	name := fmt.Sprintf("my-blog-%d-%s", userId, data.ExportedAt.Format("20060102150405"))
+	if format == "json" {
+		content, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return "", nil, err
+		}
+		return name + ".json", content, nil
+	}
+
+	content, err := exportZip(data)
+	if err != nil {
+		return "", nil, err
+	}
+	return name + ".zip", content, nil
+}
+
+func (s *accountService) SendDeleteCode(userId int) error {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+	if user.Email == "" {
+		return errors.New("账号未绑定邮箱，请使用密码确认")
+	}
+
+	uid := strconv.Itoa(userId)
+	if !config.RDB.SetNX(config.Ctx, deleteAccountLimitKey+uid, 1, time.Minute).Val() {
+		return errors.New("验证码已发送，请勿频繁操作")
+	}
+
+	code := s.mailService.GenerateCode()
+	key := deleteAccountKey + uid
+	config.RDB.Del(config.Ctx, key)
+	config.RDB.HSet(config.Ctx, key, "code", code, "attempts", 0)
+	config.RDB.Expire(config.Ctx, key, 10*time.Minute)
+
+	err = s.mailService.SendMail(user.Email,
+		"【你的博客名】注销账号验证码",
+		fmt.Sprintf("您好 <b>%s</b>，您正在注销账号，验证码是：<b>%s</b>。有效时间为10分钟。<br>"+
+			"<b>账号注销后无法恢复。</b>如果不是您本人操作，请立即修改密码。", html.EscapeString(user.Username), code))
+	if err != nil {
+		config.RDB.Del(config.Ctx, key)
+		return errors.New("邮件发送失败")
+	}
+	return nil
+}
+
+func (s *accountService) Delete(userId int, mode, code, password string) error {
+	if mode != model.DeleteModeAnonymize && mode != model.DeleteModeRemove {
+		return errors.New("请选择如何处理已发布的内容")
+	}
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	// 1. 管理员必须先把管理员角色交给别人，避免站点没有管理员
+	roles, _, _ := s.roleService.GetUserAuthorities(userId)
+	if slices.Contains(roles, model.RoleAdmin) {
+		return errors.New("管理员账号不能注销，请先撤销管理员角色")
+	}
+
+	// 2. 确认是本人操作
+	key := deleteAccountKey + strconv.Itoa(userId)
+	if user.Email != "" {
+		data, err := config.RDB.HGetAll(config.Ctx, key).Result()
+		if err != nil || len(data) == 0 {
+			return errors.New("验证码已过期，请重新获取")
+		}
+		if data["code"] != strings.TrimSpace(code) {
+			if config.RDB.HIncrBy(config.Ctx, key, "attempts", 1).Val() >= deleteAccountAttempts {
+				config.RDB.Del(config.Ctx, key)
+				return errors.New("验证码错误次数过多，请重新获取")
+			}
+			return errors.New("验证码错误")
+		}
+	} else if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return errors.New("密码错误")
+	}
+
+	// 3. 删除数据 (事务)
+	if err := s.accountRepo.Delete(userId, mode); err != nil {
+		log.Printf("❌ 注销账号失败 (userId=%d): %v", userId, err)
+		return errors.New("注销失败，请稍后再试")
+	}
+	config.RDB.Del(config.Ctx, key)
+
+	// 4. 全部设备下线
+	s.tokenService.RevokeAll(userId)
+
+	if user.Email != "" {
+		err := s.mailService.SendMail(user.Email,
+			"【你的博客名】账号已注销",
+			fmt.Sprintf("您好 <b>%s</b>，您的账号已于 %s 注销，我们保存的个人数据已全部删除。感谢您的陪伴。",
+				html.EscapeString(user.Username), time.Now().Format("2006-01-02 15:04:05")))
+		if err != nil {
+			log.Printf("⚠️ 注销通知发送失败 (userId=%d): %v", userId, err)
+		}
+	}
+	return nil
+}
+
+// --- Helper Functions ---
+
+// ZIP 结构：每类数据一个 JSON 文件，文章另外导出为 Markdown
+func exportZip(data *model.UserDataExport) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	files := []struct {
+		name string
+		v    interface{}
+	}{
+		{"profile.json", data.Profile},
+		{"security.json", map[string]interface{}{
+			"identities":   data.Identities,
+			"passkeys":     data.Passkeys,
+			"accessTokens": data.AccessTokens,
+		}},
+		{"articles.json", data.Articles},
+		{"comments.json", data.Comments},
+		{"replies.json", data.Replies},
+		{"likes.json", data.Likes},
+		{"notifications.json", data.Notifications},
+		{"footprints.json", data.Footprints},
+		{"following.json", data.Following},
+		{"blocks.json", data.Blocks},
+	}
+	for _, f := range files {
+		content, err := json.MarshalIndent(f.v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := writeZipFile(zw, f.name, data.ExportedAt, content); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, a := range data.Articles {
+		content := fmt.Sprintf("# %s\n\n%s\n", a.Title, a.Content)
+		if err := writeZipFile(zw, fmt.Sprintf("articles/%d.md", a.Id), a.Created, []byte(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, modified time.Time, content []byte) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
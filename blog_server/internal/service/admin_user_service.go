@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"slices"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AdminUserDetail 后台查看单个用户
+type AdminUserDetail struct {
+	User     *model.User         `json:"user"`
+	Activity *model.UserActivity `json:"activity"`
+	Sessions []*model.Session    `json:"sessions"` // 当前登录的设备
+}
+
+// [NEW] 后台用户管理 (仅管理员)
+type AdminUserService interface {
+	// 分页搜索，返回的用户带角色
+	Search(cond *model.UserCondition, page, pageSize int) ([]*model.User, int64, error)
+	Detail(userId int) (*AdminUserDetail, error)
+	// 封禁：不能登录，已登录的设备立即下线
+	Ban(operatorId, userId int) error
+	Unban(userId int) error
+	// 强制重置密码：原密码作废、全部设备下线，邮件通知用户通过"忘记密码"设置新密码
+	ForcePasswordReset(operatorId, userId int) error
+	// 修改角色：有角色被移除时让该用户重新登录，立即生效
+	ChangeRoles(operatorId, userId int, roles []string) error
+}
+
+type adminUserService struct {
+	userRepo     repository.UserRepository
+	roleService  RoleService
+	tokenService TokenService
+	mailService  MailService
+}
+
+func NewAdminUserService(
+	userRepo repository.UserRepository,
+	roleService RoleService,
+	tokenService TokenService,
+	mailService MailService,
+) AdminUserService {
+	return &adminUserService{
+		userRepo:     userRepo,
+		roleService:  roleService,
+		tokenService: tokenService,
+		mailService:  mailService,
+	}
+}
+
+func (s *adminUserService) Search(cond *model.UserCondition, page, pageSize int) ([]*model.User, int64, error) {
+	users, total, err := s.userRepo.Search(cond, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, user := range users {
+		roles, _, _ := s.roleService.GetUserAuthorities(user.Id)
+		FillAuthorities(user, roles)
+	}
+	return users, total, nil
+}
+
+func (s *adminUserService) Detail(userId int) (*AdminUserDetail, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	roles, _, _ := s.roleService.GetUserAuthorities(userId)
+	FillAuthorities(user, roles)
+
+	activity, err := s.userRepo.CountActivity(userId)
+	if err != nil {
+		return nil, err
+	}
+	sessions, _ := s.tokenService.ListSessions(userId, "")
+	return &AdminUserDetail{User: user, Activity: activity, Sessions: sessions}, nil
+}
+
+func (s *adminUserService) Ban(operatorId, userId int) error {
+	user, err := s.findOther(operatorId, userId)
+	if err != nil {
+		return err
+	}
+	// 管理员之间不能互相封禁，需先撤销其管理员角色
+	roles, _, _ := s.roleService.GetUserAuthorities(userId)
+	if slices.Contains(roles, model.RoleAdmin) {
+		return errors.New("不能封禁管理员，请先撤销其管理员角色")
+	}
+	if user.Valid == model.UserBanned {
+		return errors.New("该用户已被封禁")
+	}
+
+	if err := s.userRepo.UpdateValid(userId, model.UserBanned); err != nil {
+		return errors.New("封禁失败")
+	}
+	return s.tokenService.SetBanned(userId, true)
+}
+
+func (s *adminUserService) Unban(userId int) error {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+	if user.Valid == model.UserValid {
+		return errors.New("该用户未被封禁")
+	}
+
+	if err := s.userRepo.UpdateValid(userId, model.UserValid); err != nil {
+		return errors.New("解封失败")
+	}
+	return s.tokenService.SetBanned(userId, false)
+}
+
+func (s *adminUserService) ForcePasswordReset(operatorId, userId int) error {
+	user, err := s.findOther(operatorId, userId)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return errors.New("该用户未绑定邮箱，重置后将无法找回密码")
+	}
+
+	// 1. 换成随机密码 (谁都不知道)，只能通过邮箱验证码设置新密码
+	password, err := randomToken()
+	if err != nil {
+		return err
+	}
+	hashedPwd, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err := s.userRepo.UpdatePassword(userId, string(hashedPwd)); err != nil {
+		return errors.New("重置密码失败")
+	}
+
+	// 2. 全部设备下线，个人访问令牌作废
+	s.tokenService.RevokeAllCredentials(userId)
+
+	// 3. 通知用户
+	err = s.mailService.SendMail(user.Email,
+		"【你的博客名】密码已被重置",
+		fmt.Sprintf("您好 <b>%s</b>，出于安全原因，管理员已重置您的账号密码，所有设备均已退出登录，API Token 已全部作废。<br>"+
+			"请在登录页点击「忘记密码」，通过本邮箱设置新密码。", html.EscapeString(user.Username)))
+	if err != nil {
+		log.Printf("⚠️ 密码重置通知发送失败 (userId=%d): %v", userId, err)
+	}
+	return nil
+}
+
+func (s *adminUserService) ChangeRoles(operatorId, userId int, roles []string) error {
+	if _, err := s.findOther(operatorId, userId); err != nil {
+		return err
+	}
+	oldRoles, _, _ := s.roleService.GetUserAuthorities(userId)
+	if err := s.roleService.AssignRoles(userId, roles); err != nil {
+		return err
+	}
+
+	// 角色写在 Access Token 里：被移除角色时让其重新登录，新增角色等下次刷新 Token 时生效
+	for _, role := range oldRoles {
+		if !slices.Contains(roles, role) {
+			return s.tokenService.RevokeAll(userId)
+		}
+	}
+	return nil
+}
+
+// --- Helper Functions ---
+
+// 查询目标用户 (不能对自己操作，防止管理员把自己锁在外面)
+func (s *adminUserService) findOther(operatorId, userId int) (*model.User, error) {
+	if operatorId == userId {
+		return nil, errors.New("不能对自己执行该操作")
+	}
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	return user, nil
+}
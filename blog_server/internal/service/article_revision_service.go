@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"my-blog/pkg/utils"
+	"time"
+)
+
+// diff 每处修改前后保留的行数
+const revisionDiffContext = 3
+
+// [NEW] 文章历史版本：列表、查看、比较、恢复
+// 只有作者本人或管理员可以操作
+type ArticleRevisionService interface {
+	List(articleId int, op *model.Operator, page, pageSize int) ([]model.ArticleRevision, int64, error)
+	Get(articleId, version int, op *model.Operator) (*model.ArticleRevision, error)
+	// from、to 为版本号，from 大于 to 时比较的是"回退"的变化
+	Diff(articleId, from, to int, op *model.Operator) (*model.ArticleRevisionDiff, error)
+	// 恢复成某个版本，恢复后记录为一个新版本 (原来的版本都保留)
+	Restore(articleId, version int, op *model.Operator) (*model.ArticleRevision, error)
+}
+
+type articleRevisionService struct {
+	revisionRepo repository.ArticleRevisionRepository
+	articleRepo  repository.ArticleRepository
+	slugRepo     repository.ArticleSlugRepository // [NEW] 恢复的标题不同时重新生成 slug
+	searchSvc    SearchService                    // [NEW] 恢复后更新搜索索引
+}
+
+func NewArticleRevisionService(revisionRepo repository.ArticleRevisionRepository, articleRepo repository.ArticleRepository, slugRepo repository.ArticleSlugRepository, searchSvc SearchService) ArticleRevisionService {
+	return &articleRevisionService{revisionRepo: revisionRepo, articleRepo: articleRepo, slugRepo: slugRepo, searchSvc: searchSvc}
+}
+
+func (s *articleRevisionService) List(articleId int, op *model.Operator, page, pageSize int) ([]model.ArticleRevision, int64, error) {
+	if _, err := s.checkArticle(articleId, op); err != nil {
+		return nil, 0, err
+	}
+	revisions, total, err := s.revisionRepo.FindByArticleId(articleId, page, pageSize)
+	if err != nil {
+		return nil, 0, errors.New("查询历史版本失败")
+	}
+	if revisions == nil {
+		revisions = []model.ArticleRevision{}
+	}
+	return revisions, total, nil
+}
+
+func (s *articleRevisionService) Get(articleId, version int, op *model.Operator) (*model.ArticleRevision, error) {
+	if _, err := s.checkArticle(articleId, op); err != nil {
+		return nil, err
+	}
+	return s.findVersion(articleId, version)
+}
+
+func (s *articleRevisionService) Diff(articleId, from, to int, op *model.Operator) (*model.ArticleRevisionDiff, error) {
+	if _, err := s.checkArticle(articleId, op); err != nil {
+		return nil, err
+	}
+	fromRev, err := s.findVersion(articleId, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := s.findVersion(articleId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := utils.UnifiedDiff(
+		fmt.Sprintf("v%d", from), fmt.Sprintf("v%d", to),
+		revisionText(fromRev), revisionText(toRev),
+		revisionDiffContext,
+	)
+	return &model.ArticleRevisionDiff{ArticleId: articleId, From: from, To: to, Diff: diff}, nil
+}
+
+func (s *articleRevisionService) Restore(articleId, version int, op *model.Operator) (*model.ArticleRevision, error) {
+	current, err := s.checkArticle(articleId, op)
+	if err != nil {
+		return nil, err
+	}
+	old, err := s.findVersion(articleId, version)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := &model.ArticleRevision{
+		ArticleId:  articleId,
+		Title:      old.Title,
+		Content:    old.Content,
+		Tags:       old.Tags,
+		Categories: old.Categories,
+		CategoryId: old.CategoryId,
+		EditorId:   op.UserId,
+		EditorName: op.Username,
+		Remark:     fmt.Sprintf("恢复自版本 %d", version),
+		Created:    time.Now(),
+	}
+	// 与编辑文章相同：标题变了重新生成 slug，旧链接保留跳转
+	target := &model.Article{Id: articleId, Title: restored.Title}
+	if err := resolveSlug(s.slugRepo, target, current); err != nil {
+		return nil, err
+	}
+	if err := s.revisionRepo.Restore(restored, target.Slug); err != nil {
+		return nil, errors.New("恢复失败")
+	}
+	keepOldSlug(s.slugRepo, articleId, current.Slug, target.Slug)
+	s.searchSvc.IndexArticle(articleId)
+	return restored, nil
+}
+
+// --- Helper Functions ---
+
+func (s *articleRevisionService) checkArticle(articleId int, op *model.Operator) (*model.Article, error) {
+	article, err := s.articleRepo.FindById(articleId)
+	if err != nil {
+		return nil, errors.New("文章不存在")
+	}
+	if !op.CanManage(article.UserId) {
+		return nil, errors.New("只能查看自己文章的历史版本")
+	}
+	return article, nil
+}
+
+func (s *articleRevisionService) findVersion(articleId, version int) (*model.ArticleRevision, error) {
+	revision, err := s.revisionRepo.FindByVersion(articleId, version)
+	if err != nil {
+		return nil, fmt.Errorf("版本 %d 不存在", version)
+	}
+	return revision, nil
+}
+
+// 参与比较的文本：标题、标签、分类各占一行，空一行后是正文
+func revisionText(r *model.ArticleRevision) string {
+	return fmt.Sprintf("标题: %s\n标签: %s\n分类: %s\n\n%s", r.Title, r.Tags, r.Categories, r.Content)
+}
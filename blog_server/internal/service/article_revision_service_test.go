@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"testing"
+)
+
+func TestRestoreRevisionSlug(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string // 被恢复版本的标题
+		wantSlug string
+		wantOld  []string // 恢复后保留跳转的旧 slug
+	}{
+		{name: "title changed", title: "Go 语言入门", wantSlug: "go-yu-yan-ru-men", wantOld: []string{"go-yu-yan-jin-jie"}},
+		{name: "same title", title: "Go 语言进阶", wantSlug: "go-yu-yan-jin-jie"},
+		{name: "slug taken", title: "Redis 实战", wantSlug: "redis-shi-zhan-2", wantOld: []string{"go-yu-yan-jin-jie"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles := &memArticleRepo{article: &model.Article{Id: 1, UserId: 7, Title: "Go 语言进阶", Slug: "go-yu-yan-jin-jie"}}
+			revisions := &memRevisionRepo{articles: articles, old: &model.ArticleRevision{ArticleId: 1, Version: 1, Title: tt.title}}
+			slugs := &memSlugRepo{taken: map[string]bool{"redis-shi-zhan": true}}
+			s := NewArticleRevisionService(revisions, articles, slugs, stubSearchService{})
+
+			if _, err := s.Restore(1, 1, &model.Operator{UserId: 7, Username: "alice"}); err != nil {
+				t.Fatal(err)
+			}
+			if articles.article.Title != tt.title || articles.article.Slug != tt.wantSlug {
+				t.Fatalf("article = %q / %q, want %q / %q", articles.article.Title, articles.article.Slug, tt.title, tt.wantSlug)
+			}
+			if len(slugs.old) != len(tt.wantOld) || (len(tt.wantOld) > 0 && slugs.old[0] != tt.wantOld[0]) {
+				t.Fatalf("old slugs = %v, want %v", slugs.old, tt.wantOld)
+			}
+		})
+	}
+}
+
+func TestRestoreRevisionOwnership(t *testing.T) {
+	articles := &memArticleRepo{article: &model.Article{Id: 1, UserId: 7, Title: "Go 语言进阶", Slug: "go-yu-yan-jin-jie"}}
+	revisions := &memRevisionRepo{articles: articles, old: &model.ArticleRevision{ArticleId: 1, Version: 1, Title: "Go 语言入门"}}
+	s := NewArticleRevisionService(revisions, articles, &memSlugRepo{}, stubSearchService{})
+
+	if _, err := s.Restore(1, 1, &model.Operator{UserId: 8, Username: "bob"}); err == nil {
+		t.Fatal("restored another user's article")
+	}
+	if articles.article.Title != "Go 语言进阶" {
+		t.Fatal("article changed")
+	}
+}
+
+// --- Helper Functions ---
+
+// memArticleRepo 只有一篇文章，只实现了 FindById
+type memArticleRepo struct {
+	repository.ArticleRepository
+	article *model.Article
+}
+
+func (r *memArticleRepo) FindById(id int) (*model.Article, error) {
+	if r.article == nil || r.article.Id != id {
+		return nil, errors.New("record not found")
+	}
+	found := *r.article
+	return &found, nil
+}
+
+// memRevisionRepo 只有一个历史版本，Restore 直接改 articles 里的文章
+type memRevisionRepo struct {
+	repository.ArticleRevisionRepository
+	articles *memArticleRepo
+	old      *model.ArticleRevision
+}
+
+func (r *memRevisionRepo) FindByVersion(articleId, version int) (*model.ArticleRevision, error) {
+	if r.old.ArticleId != articleId || r.old.Version != version {
+		return nil, errors.New("record not found")
+	}
+	return r.old, nil
+}
+
+func (r *memRevisionRepo) Restore(revision *model.ArticleRevision, slug string) error {
+	r.articles.article.Title = revision.Title
+	r.articles.article.Slug = slug
+	return nil
+}
+
+// memSlugRepo taken 为其他文章占用的 slug，old 为记录下来的旧 slug
+type memSlugRepo struct {
+	repository.ArticleSlugRepository
+	taken map[string]bool
+	old   []string
+}
+
+func (r *memSlugRepo) IsTaken(slug string, exceptArticleId int) (bool, error) {
+	return r.taken[slug], nil
+}
+
+func (r *memSlugRepo) Rename(articleId int, oldSlug, newSlug string) error {
+	r.old = append(r.old, oldSlug)
+	return nil
+}
+
+type stubSearchService struct {
+	SearchService
+}
+
+func (stubSearchService) IndexArticle(id int) {}
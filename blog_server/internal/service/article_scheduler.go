@@ -0,0 +1,23 @@
+package service
+
+import (
+	"log"
+	"time"
+)
+
+// [NEW] 定时发布：每隔 interval 检查一次到时间的定时文章
+// 多个实例同时运行也没关系，PublishScheduled 只会有一个实例更新成功 (只通知一次)
+func StartArticleScheduler(articleService ArticleService, interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			n, err := articleService.PublishDueArticles()
+			if err != nil {
+				log.Printf("⚠️ 定时发布检查失败: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("✅ 定时发布了 %d 篇文章", n)
+			}
+		}
+	}()
+}
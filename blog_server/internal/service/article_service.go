@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"fmt"
+	"log"
 	"my-blog/internal/model"
 	"my-blog/internal/repository"
 	"my-blog/pkg/utils" // 引入我们刚写的工具包
@@ -13,14 +14,17 @@ import (
 // 1. 接口
 type ArticleService interface {
 	GetArticleList() ([]model.Article, error)
-	GetArticleDetail(id int) (*model.Article, error)
+	// [MODIFY] 草稿、定时、归档的文章只有作者本人能查看 (viewerId 为 0 表示游客)
+	GetArticleDetail(id, viewerId int) (*model.Article, error)
 	// [NEW] 对应 Java 的 getAPageOfArticle
 	GetPageList(pageParams *utils.PageParams) (*utils.Result, error)
 
 	// [NEW] 发布文章 (复刻 Java 的 publishArticle)
-	Publish(article *model.Article, isEdit bool) error
+	// [MODIFY] 新文章的作者为 op；编辑只允许作者本人或管理员
+	Publish(article *model.Article, isEdit bool, op *model.Operator) error
 	// [NEW] 删除文章
-	Delete(id int) error
+	// [MODIFY] 只允许作者本人或管理员
+	Delete(id int, op *model.Operator) error
 
 	// [NEW] 新增真实业务接口
 	GetAllTags() ([]model.Tag, error)
@@ -39,6 +43,17 @@ type ArticleService interface {
 
 	// [NEW] 获取我点赞的文章
 	GetMyLikedArticles(userId int, pageParams *utils.PageParams) (*utils.Result, error)
+
+	// [NEW] 发布到时间的定时文章并通知粉丝，返回本次发布的篇数 (由 StartArticleScheduler 定时调用)
+	PublishDueArticles() (int, error)
+
+	// [NEW] 按 slug 查文章；slug 是旧的时返回 nil 和现在的 slug (用于 301 跳转)
+	GetArticleBySlug(slug string, viewerId int) (*model.Article, string, error)
+	// [NEW] 给加 slug 之前发布的文章生成 slug，返回处理的篇数
+	FillMissingSlugs() (int, error)
+
+	// [NEW] 根据标题和正文推荐标签
+	SuggestTags(title, content string) ([]string, error)
 }
 
 // 2. 结构体
@@ -51,6 +66,15 @@ type articleService struct {
 	commentRepo repository.CommentRepository
 	// [NEW] 注入 CategoryRepo 以便在发布时反查分类ID
 	categoryRepo repository.CategoryRepository
+	// [NEW] 发布新文章时通知粉丝
+	followRepo repository.FollowRepository
+	blockRepo  repository.BlockRepository // [NEW] 拉黑 / 屏蔽
+	// [NEW] 每次保存记录一个历史版本
+	revisionRepo repository.ArticleRevisionRepository
+	// [NEW] 正文渲染 (Markdown -> HTML、目录、阅读时间)
+	renderSvc RenderService
+	slugRepo  repository.ArticleSlugRepository // [NEW] 旧 slug
+	searchSvc SearchService                    // [NEW] 全文搜索索引
 }
 
 // 3. 构造函数
@@ -61,6 +85,12 @@ func NewArticleService(
 	notifyRepo repository.NotificationRepository,
 	commentRepo repository.CommentRepository,   // 新增参数
 	categoryRepo repository.CategoryRepository, // [NEW] 新增参数
+	followRepo repository.FollowRepository, // [NEW]
+	blockRepo repository.BlockRepository, // [NEW]
+	revisionRepo repository.ArticleRevisionRepository, // [NEW]
+	renderSvc RenderService, // [NEW]
+	slugRepo repository.ArticleSlugRepository, // [NEW]
+	searchSvc SearchService, // [NEW]
 ) ArticleService {
 	return &articleService{
 		repo:         repo,
@@ -68,6 +98,12 @@ func NewArticleService(
 		notifyRepo:   notifyRepo,
 		commentRepo:  commentRepo,
 		categoryRepo: categoryRepo,
+		followRepo:   followRepo,
+		blockRepo:    blockRepo,
+		revisionRepo: revisionRepo,
+		renderSvc:    renderSvc,
+		slugRepo:     slugRepo,
+		searchSvc:    searchSvc,
 	}
 }
 
@@ -77,8 +113,15 @@ func (s *articleService) GetArticleList() ([]model.Article, error) {
 	return s.repo.FindAll()
 }
 
-func (s *articleService) GetArticleDetail(id int) (*model.Article, error) {
-	return s.repo.FindById(id)
+func (s *articleService) GetArticleDetail(id, viewerId int) (*model.Article, error) {
+	article, err := s.repo.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canViewArticle(article, viewerId) {
+		return nil, errors.New("文章不存在")
+	}
+	return article, nil
 }
 
 // [NEW] 实现方法
@@ -105,7 +148,7 @@ func (s *articleService) GetPageList(pageParams *utils.PageParams) (*utils.Resul
 
 // [NEW] 实现 Publish
 // 参数说明：isEdit=true 代表是编辑，false 代表是新增
-func (s *articleService) Publish(article *model.Article, isEdit bool) error {
+func (s *articleService) Publish(article *model.Article, isEdit bool, op *model.Operator) error {
 	// 🔴 [新增校验] 必须要有标题
 	if article.Title == "" {
 		return errors.New("文章标题不能为空")
@@ -165,28 +208,188 @@ func (s *articleService) Publish(article *model.Article, isEdit bool) error {
 
 	// 2. 自动填充时间
 	now := time.Now()
+	// [NEW] 校验发布状态 (新增时不传默认直接发布，编辑时不传保持原状态)
+	if err := checkArticleStatus(article, now); err != nil {
+		return err
+	}
 	if !isEdit {
 		// 如果是新增，设置创建时间
 		article.Created = now
-		// 暂时写死 UserID，因为还没做登录
-		// 等后面做了 User 模块，这里换成从 Context 取 ID
-		article.UserId = 1
-		article.Author = "Admin"
+		// [MODIFY] 作者为当前登录用户 (不再写死 1 / Admin)
+		article.UserId = op.UserId
+		article.Author = op.Username
+		if article.Status == "" {
+			article.Status = model.ArticleStatusPublished
+		}
+		if article.Status == model.ArticleStatusPublished {
+			article.PublishAt = &now
+		}
+		// [NEW] 文章链接
+		if err := resolveSlug(s.slugRepo, article, nil); err != nil {
+			return err
+		}
 
-		return s.repo.Create(article)
+		if err := s.repo.Create(article); err != nil {
+			return err
+		}
+		// [NEW] 第一个版本
+		s.saveRevision(article, op)
+		s.searchSvc.IndexArticle(article.Id) // [NEW] 更新搜索索引
+		// [NEW] 通知关注了作者的用户 (FOLLOW_POST)，草稿和定时文章等真正发布时再通知
+		if article.Status == model.ArticleStatusPublished {
+			go s.notifyFollowers(article)
+		}
+		return nil
 	} else {
+		// [NEW] 只有作者本人或管理员可以编辑
+		old, err := s.repo.FindById(article.Id)
+		if err != nil {
+			return errors.New("文章不存在")
+		}
+		if !op.CanManage(old.UserId) {
+			return errors.New("只能编辑自己的文章")
+		}
+		// 管理员编辑时作者不变，也不允许通过参数改掉作者
+		article.UserId = old.UserId
+		article.Author = old.Author
+		// [NEW] 在有历史版本之前发布的文章，先把修改前的内容存为第一个版本
+		if _, err := s.revisionRepo.FindByVersion(old.Id, 1); err != nil {
+			s.saveRevision(old, &model.Operator{UserId: old.UserId, Username: old.Author})
+		}
+
+		// [NEW] 文章链接 (标题改了会重新生成，旧链接保留跳转)
+		if err := resolveSlug(s.slugRepo, article, old); err != nil {
+			return err
+		}
+
+		// [NEW] 草稿 / 定时文章改为已发布：以现在作为发布时间，并通知粉丝
+		goLive := article.Status == model.ArticleStatusPublished && !old.IsPublished()
+		if goLive {
+			article.Created = now
+			article.PublishAt = &now
+		}
+
 		// 如果是编辑，设置修改时间
 		article.Modified = &now
-		return s.repo.Update(article)
+		if err := s.repo.Update(article); err != nil {
+			return err
+		}
+		keepOldSlug(s.slugRepo, article.Id, old.Slug, article.Slug)
+		// [NEW] 记录保存后的内容 (Updates 不更新零值，以数据库里的为准)
+		if saved, err := s.repo.FindById(article.Id); err == nil {
+			s.saveRevision(saved, op)
+		}
+		// [NEW] 更新搜索索引 (改为草稿、归档时从索引中删除)
+		s.searchSvc.IndexArticle(article.Id)
+		if goLive {
+			go s.notifyFollowers(article)
+		}
+		return nil
+	}
+}
+
+// [NEW] 记录历史版本，失败不影响保存
+func (s *articleService) saveRevision(article *model.Article, op *model.Operator) {
+	if err := s.revisionRepo.Create(model.NewArticleRevision(article, op, "")); err != nil {
+		log.Printf("⚠️ 历史版本保存失败 (articleId=%d): %v", article.Id, err)
+	}
+}
+
+// [NEW] 草稿、定时、归档的文章只有作者本人能查看
+// 游客的 viewerId 为 0，注销用户的文章 user_id 也是 0，不能因此匹配上
+func canViewArticle(article *model.Article, viewerId int) bool {
+	return article.IsPublished() || (viewerId > 0 && article.UserId == viewerId)
+}
+
+// [NEW] 校验文章状态，定时发布的时间必须晚于现在
+func checkArticleStatus(article *model.Article, now time.Time) error {
+	switch article.Status {
+	case "", model.ArticleStatusDraft, model.ArticleStatusPublished, model.ArticleStatusArchived:
+		return nil
+	case model.ArticleStatusScheduled:
+		if article.PublishAt == nil || !article.PublishAt.After(now) {
+			return errors.New("定时发布时间必须晚于当前时间")
+		}
+		return nil
+	default:
+		return errors.New("不支持的文章状态")
+	}
+}
+
+// [NEW] 实现 PublishDueArticles
+func (s *articleService) PublishDueArticles() (int, error) {
+	articles, err := s.repo.FindDueScheduled(time.Now(), 100)
+	if err != nil {
+		return 0, err
+	}
+	published := 0
+	for i := range articles {
+		article := &articles[i]
+		ok, err := s.repo.PublishScheduled(article.Id)
+		if err != nil {
+			log.Printf("⚠️ 定时发布失败 (articleId=%d): %v", article.Id, err)
+			continue
+		}
+		// 已被其他实例发布，或作者刚把它改回了草稿
+		if !ok {
+			continue
+		}
+		article.Status = model.ArticleStatusPublished
+		article.Created = *article.PublishAt
+		s.searchSvc.IndexArticle(article.Id)
+		s.notifyFollowers(article)
+		published++
+	}
+	return published, nil
+}
+
+// [NEW] 新文章通知：给作者的每个粉丝发一条 FOLLOW_POST 通知
+func (s *articleService) notifyFollowers(article *model.Article) {
+	followerIds, err := s.followRepo.FindFollowerIds(article.UserId)
+	if err != nil || len(followerIds) == 0 {
+		return
+	}
+	now := time.Now()
+	list := make([]*model.Notification, 0, len(followerIds))
+	for _, id := range followerIds {
+		list = append(list, &model.Notification{
+			ReceiverId: id,
+			SenderId:   article.UserId,
+			SenderName: article.Author,
+			ArticleId:  article.Id,
+			Content:    fmt.Sprintf("发表了新文章: %s", article.Title),
+			Type:       model.NotifyTypeFollowPost,
+			Status:     0,
+			Created:    now,
+		})
+	}
+	if err := s.notifyRepo.CreateBatch(list); err != nil {
+		log.Printf("⚠️ 新文章通知发送失败 (articleId=%d): %v", article.Id, err)
 	}
 }
 
 // [NEW] 实现 Delete
-func (s *articleService) Delete(id int) error {
+func (s *articleService) Delete(id int, op *model.Operator) error {
 	if id <= 0 {
 		return errors.New("无效的 ID")
 	}
-	return s.repo.Delete(id)
+	// [NEW] 只有作者本人或管理员可以删除
+	article, err := s.repo.FindById(id)
+	if err != nil {
+		return errors.New("文章不存在")
+	}
+	if !op.CanManage(article.UserId) {
+		return errors.New("只能删除自己的文章")
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.searchSvc.RemoveArticles(id) // [NEW]
+	// [NEW] 历史版本、旧 slug 一起删除
+	if err := s.revisionRepo.DeleteByArticleIds([]int{id}); err != nil {
+		return err
+	}
+	return s.slugRepo.DeleteByArticleIds([]int{id})
 }
 
 func (s *articleService) GetHotArticles() ([]model.Article, error) {
@@ -233,6 +436,10 @@ func (s *articleService) GetArticleAndFirstPageCommentByArticleId(articleId, use
 	if err != nil {
 		return nil, err
 	}
+	// [NEW] 未发布的文章只有作者本人能看到
+	if !canViewArticle(article, userId) {
+		return nil, errors.New("文章不存在")
+	}
 
 	// 2. 增加阅读数 (Hits)
 	// (确保你的 article_repo.go 里有 UpdateReadCount 方法)
@@ -251,7 +458,8 @@ func (s *articleService) GetArticleAndFirstPageCommentByArticleId(articleId, use
 
 	// 4. 查第一页评论 (默认取 5 条，按最新排序)
 	// 这里调用了新注入的 commentRepo
-	comments, total, _ := s.commentRepo.GetPage(articleId, 1, 5)
+	// [MODIFY] 不返回当前用户拉黑 / 屏蔽的用户的评论
+	comments, total, _ := s.commentRepo.GetPage(articleId, userId, 1, 5)
 
 	// 5. 组装结果
 	res := utils.Ok()
@@ -259,6 +467,17 @@ func (s *articleService) GetArticleAndFirstPageCommentByArticleId(articleId, use
 	res.Put("comments", comments)
 	res.Put("total", total)
 
+	// 6. [NEW] 服务端渲染好的正文、目录、阅读时间 (article.content 仍返回原文，方便编辑)
+	rendered, err := s.renderSvc.Render(article.Content)
+	if err != nil {
+		log.Printf("⚠️ 文章渲染失败 (articleId=%d): %v", articleId, err)
+		rendered = &model.RenderedArticle{Toc: []*model.TocItem{}}
+	}
+	res.Put("html", rendered.Html)
+	res.Put("toc", rendered.Toc)
+	res.Put("wordCount", rendered.WordCount)
+	res.Put("readingMinutes", rendered.ReadingMinutes)
+
 	return res, nil
 }
 
@@ -292,12 +511,15 @@ func (s *articleService) LikeArticle(userId, articleId int) (string, error) {
 			if article != nil && article.UserId != userId {
 				notify := &model.Notification{
 					ReceiverId: article.UserId,
+					SenderId:   userId,
+					ArticleId:  articleId,
 					Content:    fmt.Sprintf("点赞了你的文章: %s", article.Title),
 					Type:       "LIKE", // 通知类型
 					Status:     0,
 					Created:    time.Now(),
 				}
-				s.notifyRepo.Create(notify)
+				// [MODIFY] 作者拉黑 / 屏蔽了点赞者时不通知
+				createNotification(s.blockRepo, s.notifyRepo, notify)
 			}
 		}()
 
@@ -312,19 +534,75 @@ func (s *articleService) GetReadRanking() ([]model.Article, error) {
 }
 
 // [NEW] 实现 Search (对应 Java 的 search 方法)
+// [MODIFY] 有搜索词时走全文索引 (按相关度排序、高亮)，否则按发布时间倒序列出
 func (s *articleService) Search(p *utils.PageParams, condition *model.ArticleCondition) (*utils.Result, error) {
-	// 调用 Repo 进行搜索
-	articles, total, err := s.repo.Search(p.Page, p.Rows, condition)
+	if err := s.fillCondition(condition); err != nil {
+		return nil, err
+	}
+
+	var articles []model.Article
+	var total int64
+	var err error
+	// 只搜已发布的文章；查自己的文章 (可以看到草稿等) 时不走索引
+	ownArticles := condition.UserId > 0 && condition.UserId == condition.ViewerId
+	if condition.HasKeyword() && !ownArticles && s.searchSvc.Ready() {
+		articles, total, err = s.searchSvc.Search(condition, p.Page, p.Rows)
+	} else {
+		// 调用 Repo 进行搜索
+		articles, total, err = s.repo.Search(p.Page, p.Rows, condition)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	p.Total = total
 	res := utils.Ok()
 	res.Put("articles", articles)
 	res.Put("total", total)
+	res.Put("pageParams", p)
 	return res, nil
 }
 
+// [NEW] 把分类展开成自己和所有子分类，解析发布时间范围
+func (s *articleService) fillCondition(condition *model.ArticleCondition) error {
+	condition.Keyword = strings.TrimSpace(condition.Keyword)
+	condition.Title = strings.TrimSpace(condition.Title)
+	condition.Content = strings.TrimSpace(condition.Content)
+
+	if condition.CategoryId > 0 {
+		all, err := s.categoryRepo.FindAll()
+		if err != nil {
+			return err
+		}
+		condition.CategoryIds = categoryWithDescendants(all, condition.CategoryId)
+	}
+	if condition.StartDate != "" {
+		from, err := time.ParseInLocation("2006-01-02", condition.StartDate, time.Local)
+		if err != nil {
+			return errors.New("起始日期格式错误")
+		}
+		condition.CreatedFrom = &from
+	}
+	if condition.EndDate != "" {
+		end, err := time.ParseInLocation("2006-01-02", condition.EndDate, time.Local)
+		if err != nil {
+			return errors.New("结束日期格式错误")
+		}
+		// 包含结束日期当天
+		to := end.AddDate(0, 0, 1)
+		condition.CreatedTo = &to
+	}
+	return nil
+}
+
+// [NEW] 实现 SuggestTags (关键词提取见 SearchService)
+func (s *articleService) SuggestTags(title, content string) ([]string, error) {
+	if strings.TrimSpace(title) == "" && strings.TrimSpace(content) == "" {
+		return []string{}, nil
+	}
+	return s.searchSvc.SuggestTags(title, content)
+}
+
 // [NEW] 实现 GetMyLikedArticles
 func (s *articleService) GetMyLikedArticles(userId int, p *utils.PageParams) (*utils.Result, error) {
 	articles, total, err := s.repo.GetMyLikedArticles(userId, p.Page, p.Rows)
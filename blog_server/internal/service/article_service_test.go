@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"my-blog/internal/model"
+	"testing"
+)
+
+func TestArticleVisibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		ownerId  int
+		viewerId int
+		want     bool
+	}{
+		{"published, guest", model.ArticleStatusPublished, 7, 0, true},
+		{"legacy empty status, guest", "", 7, 0, true},
+		{"draft, owner", model.ArticleStatusDraft, 7, 7, true},
+		{"draft, other user", model.ArticleStatusDraft, 7, 8, false},
+		{"draft, guest", model.ArticleStatusDraft, 7, 0, false},
+		// 注销用户的文章 user_id 为 0，不能被游客 (viewerId 也是 0) 看到
+		{"anonymized draft, guest", model.ArticleStatusDraft, 0, 0, false},
+		{"anonymized scheduled, guest", model.ArticleStatusScheduled, 0, 0, false},
+		{"anonymized archived, guest", model.ArticleStatusArchived, 0, 0, false},
+		{"anonymized published, guest", model.ArticleStatusPublished, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := &model.Article{Id: 1, UserId: tt.ownerId, Status: tt.status, Slug: "hello"}
+			s := &articleService{repo: &slugArticleRepo{memArticleRepo: memArticleRepo{article: article}}}
+
+			_, err := s.GetArticleDetail(1, tt.viewerId)
+			if got := err == nil; got != tt.want {
+				t.Errorf("GetArticleDetail() err = %v, want visible %v", err, tt.want)
+			}
+			_, _, err = s.GetArticleBySlug("hello", tt.viewerId)
+			if got := err == nil; got != tt.want {
+				t.Errorf("GetArticleBySlug() err = %v, want visible %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// --- Helper Functions ---
+
+// slugArticleRepo 在 memArticleRepo 的基础上实现 FindBySlug
+type slugArticleRepo struct {
+	memArticleRepo
+}
+
+func (r *slugArticleRepo) FindBySlug(slug string) (*model.Article, error) {
+	if r.article == nil || r.article.Slug != slug {
+		return nil, errors.New("record not found")
+	}
+	found := *r.article
+	return &found, nil
+}
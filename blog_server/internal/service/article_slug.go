@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"regexp"
+	"strings"
+
+	"github.com/gosimple/slug"
+	"gorm.io/gorm"
+)
+
+// [NEW] 文章 slug：默认由标题生成 (中文转拼音，例如 "Go 语言入门" -> go-yu-yan-ru-men)，重复时加序号
+// 作者可以在发布时自定义；slug 变了以后旧的保留在 t_article_slug，旧链接 301 跳转到新的
+
+const articleSlugMaxLen = 100
+
+var customSlugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// 发布 / 编辑 / 恢复历史版本时确定 slug：传了就用自定义的；没传时新文章或标题改了重新生成，否则保持不变
+func resolveSlug(slugRepo repository.ArticleSlugRepository, article *model.Article, old *model.Article) error {
+	custom := strings.ToLower(strings.TrimSpace(article.Slug))
+	if custom != "" {
+		if len(custom) > articleSlugMaxLen || !customSlugPattern.MatchString(custom) {
+			return errors.New("自定义链接只能包含小写字母、数字和短横线，最多 100 个字符")
+		}
+		taken, err := slugRepo.IsTaken(custom, article.Id)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return errors.New("该链接已被其他文章使用")
+		}
+		article.Slug = custom
+		return nil
+	}
+
+	if old != nil && old.Slug != "" && old.Title == article.Title {
+		article.Slug = old.Slug
+		return nil
+	}
+	generated, err := uniqueSlug(slugRepo, article.Title, article.Id)
+	if err != nil {
+		return err
+	}
+	article.Slug = generated
+	return nil
+}
+
+// 由标题生成不重复的 slug：go-yu-yan、go-yu-yan-2、go-yu-yan-3 ...
+func uniqueSlug(slugRepo repository.ArticleSlugRepository, title string, articleId int) (string, error) {
+	base := truncateSlug(slug.Make(title))
+	if base == "" {
+		base = "article" // 标题全是表情符号等无法转写的字符
+	}
+	for i := 1; i <= 100; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		taken, err := slugRepo.IsTaken(candidate, articleId)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	return "", errors.New("无法生成文章链接，请自定义")
+}
+
+// [NEW] 实现 GetArticleBySlug
+func (s *articleService) GetArticleBySlug(slugText string, viewerId int) (*model.Article, string, error) {
+	article, err := s.repo.FindBySlug(slugText)
+	if err == nil {
+		if !canViewArticle(article, viewerId) {
+			return nil, "", errors.New("文章不存在")
+		}
+		return article, "", nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", err
+	}
+
+	// 旧链接：返回现在的 slug，由 Controller 301 跳转
+	articleId, err := s.slugRepo.FindArticleIdByOldSlug(slugText)
+	if err != nil || articleId == 0 {
+		return nil, "", errors.New("文章不存在")
+	}
+	current, err := s.repo.FindById(articleId)
+	if err != nil || current.Slug == "" || !canViewArticle(current, viewerId) {
+		return nil, "", errors.New("文章不存在")
+	}
+	return nil, current.Slug, nil
+}
+
+// [NEW] 实现 FillMissingSlugs
+func (s *articleService) FillMissingSlugs() (int, error) {
+	filled := 0
+	for {
+		articles, err := s.repo.FindWithoutSlug(100)
+		if err != nil {
+			return filled, err
+		}
+		if len(articles) == 0 {
+			return filled, nil
+		}
+		for _, a := range articles {
+			generated, err := uniqueSlug(s.slugRepo, a.Title, a.Id)
+			if err != nil {
+				// 生成不了就用 ID，保证不会一直查到这篇
+				generated = fmt.Sprintf("article-%d", a.Id)
+			}
+			if err := s.repo.UpdateSlug(a.Id, generated); err != nil {
+				return filled, err
+			}
+			filled++
+		}
+	}
+}
+
+// --- Helper Functions ---
+
+// 超长时在最后一个 "-" 处截断，避免截断半个单词
+func truncateSlug(s string) string {
+	if len(s) <= articleSlugMaxLen {
+		return s
+	}
+	s = s[:articleSlugMaxLen]
+	if i := strings.LastIndex(s, "-"); i > 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// 记录旧 slug，失败不影响保存 (只是旧链接不能跳转)
+func keepOldSlug(slugRepo repository.ArticleSlugRepository, articleId int, oldSlug, newSlug string) {
+	if oldSlug == newSlug {
+		return
+	}
+	if err := slugRepo.Rename(articleId, oldSlug, newSlug); err != nil {
+		log.Printf("⚠️ 旧链接保存失败 (articleId=%d): %v", articleId, err)
+	}
+}
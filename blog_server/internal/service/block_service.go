@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+)
+
+// [NEW] 拉黑 / 屏蔽用户
+type BlockService interface {
+	// blockType: model.BlockTypeBlock / model.BlockTypeMute
+	Block(blockerId, blockedId int, blockType string) error
+	Unblock(blockerId, blockedId int) error
+	List(blockerId int, blockType string, page, pageSize int) ([]*model.BlockedUser, int64, error)
+	// blocker 对 blocked 的处理方式，没有时返回 ""
+	Status(blockerId, blockedId int) (string, error)
+}
+
+type blockService struct {
+	blockRepo repository.BlockRepository
+	userRepo  repository.UserRepository
+}
+
+func NewBlockService(blockRepo repository.BlockRepository, userRepo repository.UserRepository) BlockService {
+	return &blockService{blockRepo: blockRepo, userRepo: userRepo}
+}
+
+func (s *blockService) Block(blockerId, blockedId int, blockType string) error {
+	if blockType != model.BlockTypeBlock && blockType != model.BlockTypeMute {
+		return errors.New("不支持的类型")
+	}
+	if blockerId == blockedId {
+		return errors.New("不能拉黑自己")
+	}
+	if _, err := s.userRepo.FindById(blockedId); err != nil {
+		return errors.New("用户不存在")
+	}
+	if err := s.blockRepo.Save(blockerId, blockedId, blockType); err != nil {
+		return errors.New("操作失败")
+	}
+	return nil
+}
+
+func (s *blockService) Unblock(blockerId, blockedId int) error {
+	if err := s.blockRepo.Delete(blockerId, blockedId); err != nil {
+		return errors.New("操作失败")
+	}
+	return nil
+}
+
+func (s *blockService) List(blockerId int, blockType string, page, pageSize int) ([]*model.BlockedUser, int64, error) {
+	return s.blockRepo.FindByBlocker(blockerId, blockType, page, pageSize)
+}
+
+func (s *blockService) Status(blockerId, blockedId int) (string, error) {
+	return s.blockRepo.FindType(blockerId, blockedId)
+}
+
+// --- Helper Functions ---
+
+// 发送通知：接收者拉黑 / 屏蔽了发送者时不发 (评论、回复、点赞共用)
+func createNotification(blockRepo repository.BlockRepository, notifyRepo repository.NotificationRepository, notify *model.Notification) {
+	if notify.SenderId > 0 {
+		blockType, err := blockRepo.FindType(notify.ReceiverId, notify.SenderId)
+		if err != nil || blockType != "" {
+			return
+		}
+	}
+	if err := notifyRepo.Create(notify); err != nil {
+		log.Printf("⚠️ 通知发送失败 (receiverId=%d): %v", notify.ReceiverId, err)
+	}
+}
+
+// 是否被拉黑 (屏蔽不影响对方的操作)
+func isBlockedBy(blockRepo repository.BlockRepository, blockerId, userId int) bool {
+	blockType, err := blockRepo.FindType(blockerId, userId)
+	return err == nil && blockType == model.BlockTypeBlock
+}
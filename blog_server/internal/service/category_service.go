@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"fmt"
+	"log"
 	"my-blog/internal/model"
 	"my-blog/internal/repository"
 	"my-blog/pkg/utils"
@@ -20,10 +21,11 @@ type CategoryService interface {
 type categoryService struct {
 	repo        repository.CategoryRepository
 	articleRepo repository.ArticleRepository
+	searchSvc   SearchService // [NEW] 文章移动或删除后重建搜索索引
 }
 
-func NewCategoryService(repo repository.CategoryRepository, articleRepo repository.ArticleRepository) CategoryService {
-	return &categoryService{repo: repo, articleRepo: articleRepo}
+func NewCategoryService(repo repository.CategoryRepository, articleRepo repository.ArticleRepository, searchSvc SearchService) CategoryService {
+	return &categoryService{repo: repo, articleRepo: articleRepo, searchSvc: searchSvc}
 }
 
 // [NEW] 获取树形结构
@@ -118,6 +120,13 @@ func (s *categoryService) Delete(id int, mode int) error {
 		return errors.New("未知的删除模式")
 	}
 
+	// [NEW] 索引里的分类 / 文章已经变了，后台重建
+	go func() {
+		if _, err := s.searchSvc.Rebuild(); err != nil {
+			log.Printf("⚠️ 搜索索引重建失败: %v", err)
+		}
+	}()
+
 	// 4. 删除分类本身
 	return s.repo.Delete(id)
 }
@@ -149,6 +158,19 @@ func (s *categoryService) buildTree(all []*model.Category) []*model.Category {
 	return roots
 }
 
+// [NEW] 分类 id 及其所有子孙分类的 id
+func categoryWithDescendants(all []*model.Category, id int) []int {
+	children := make(map[int][]int)
+	for _, cat := range all {
+		children[cat.ParentId] = append(children[cat.ParentId], cat.Id)
+	}
+	ids := []int{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
 // 递归构建路径
 func (s *categoryService) buildPath(id int) (string, error) {
 	if id == 0 {
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"my-blog/internal/model"
 	"my-blog/internal/repository"
@@ -9,7 +10,8 @@ import (
 )
 
 type CommentService interface {
-	GetComments(articleId int, pageParams *utils.PageParams) (*utils.Result, error)
+	// [MODIFY] viewerId: 当前用户 (游客为 0)，不返回其拉黑 / 屏蔽的用户的评论
+	GetComments(articleId, viewerId int, pageParams *utils.PageParams) (*utils.Result, error)
 	AddComment(comment *model.Comment) error
 	// [NEW] 点赞
 	LikeComment(userId, commentId int) (string, error) // 返回 "点赞成功" 或 "取消点赞"
@@ -19,6 +21,9 @@ type CommentService interface {
 
 	// [NEW] 获取我点赞的评论
 	GetMyLikedComments(userId int, pageParams *utils.PageParams) (*utils.Result, error)
+
+	// [NEW] 删除评论 (连同下面的回复)，只允许作者本人或管理员
+	DeleteComment(commentId int, op *model.Operator) error
 }
 
 type commentService struct {
@@ -30,6 +35,7 @@ type commentService struct {
 
 	replyRepo   repository.ReplyRepository
 	commentRepo repository.CommentRepository
+	blockRepo   repository.BlockRepository // [NEW] 拉黑 / 屏蔽
 }
 
 // [MODIFIED] 修改构造函数，注入新的依赖
@@ -39,6 +45,7 @@ func NewCommentService(
 	notifyRepo repository.NotificationRepository, // 新增
 	articleRepo repository.ArticleRepository, // 新增
 	replyRepo repository.ReplyRepository,
+	blockRepo repository.BlockRepository, // [NEW]
 ) CommentService {
 	return &commentService{
 		userRepo:    userRepo,
@@ -46,23 +53,24 @@ func NewCommentService(
 		articleRepo: articleRepo,
 		replyRepo:   replyRepo,
 		commentRepo: commentRepo,
+		blockRepo:   blockRepo,
 	}
 }
 
 // 获取评论列表
-func (s *commentService) GetComments(articleId int, p *utils.PageParams) (*utils.Result, error) {
-	comments, total, err := s.commentRepo.GetPageByArticleId(articleId, p.Page, p.Rows)
+func (s *commentService) GetComments(articleId, viewerId int, p *utils.PageParams) (*utils.Result, error) {
+	comments, total, err := s.commentRepo.GetPageByArticleId(articleId, viewerId, p.Page, p.Rows)
 	if err != nil {
 		return nil, err
 	}
 
 	// 填充 User 信息
 	for i := range comments {
-		user, _ := s.userRepo.FindById(comments[i].UserId)
-		if user != nil {
-			user.Password = ""
+		// [MODIFY] 用户已注销 (user_id=0) 时保留数据库里的作者名
+		user, err := s.userRepo.FindById(comments[i].UserId)
+		if err == nil {
 			// 前端需要 user 里的 avatar
-			comments[i].User = user
+			comments[i].User = user.Public()
 			// 如果数据库 author 为空，用 user 表的
 			if comments[i].Author == "" {
 				comments[i].Author = user.Username
@@ -76,26 +84,20 @@ func (s *commentService) GetComments(articleId int, p *utils.PageParams) (*utils
 	return res, nil
 }
 
-// 发表评论 (复刻 Java 逻辑：根据 Author 名字查 UserId)
+// 发表评论
+// [MODIFY] 评论者只认 comment.UserId (登录用户)，作者名从用户表取，不再信任前端传的 Author
 func (s *commentService) AddComment(comment *model.Comment) error {
-	comment.Created = time.Now()
-	comment.Status = "approved" // 对应 SQL 默认值
-	comment.Likes = 0
-
-	// 关键：如果前端传了 Author 但没传 UserId，我们去查 User 表
-	if comment.UserId == 0 && comment.Author != "" {
-		user, err := s.userRepo.FindByUsername(comment.Author)
-		if err == nil && user != nil {
-			comment.UserId = user.Id
-		}
+	user, err := s.userRepo.FindById(comment.UserId)
+	if err != nil || user == nil {
+		return errors.New("用户不存在")
 	}
+	comment.Author = user.Username
+	comment.Likes = 0
 
-	// 反之，如果传了 UserId 但没传 Author (防御性编程)
-	if comment.UserId != 0 && comment.Author == "" {
-		user, err := s.userRepo.FindById(comment.UserId)
-		if err == nil && user != nil {
-			comment.Author = user.Username
-		}
+	// [NEW] 文章必须存在；未发布的文章只有作者本人能评论 (否则可以借此探测草稿、骚扰作者)
+	article, err := s.articleRepo.FindById(comment.ArticleId)
+	if err != nil || !canViewArticle(article, comment.UserId) {
+		return errors.New("文章不存在")
 	}
 
 	comment.Created = time.Now()
@@ -111,8 +113,7 @@ func (s *commentService) AddComment(comment *model.Comment) error {
 
 	// 2. [NEW] 发送通知 (复刻 NotificationAspect)
 	go func() { // 开个协程异步发，不卡主线程
-		article, _ := s.articleRepo.FindById(comment.ArticleId)
-		if article != nil && article.UserId != comment.UserId { // 自己评论自己不发通知
+		if article.UserId != 0 && article.UserId != comment.UserId { // 自己评论自己、作者已注销不发通知
 			notify := &model.Notification{
 				ReceiverId: article.UserId,    // 接收者：文章作者
 				SenderId:   comment.UserId,    // ✅ 必须填
@@ -124,7 +125,8 @@ func (s *commentService) AddComment(comment *model.Comment) error {
 				Status:     0,
 				Created:    time.Now(),
 			}
-			s.notifyRepo.Create(notify) // 需要在 notifyRepo 加个 Create 方法
+			// [MODIFY] 作者拉黑 / 屏蔽了评论者时不通知
+			createNotification(s.blockRepo, s.notifyRepo, notify)
 		}
 	}()
 
@@ -194,3 +196,26 @@ func (s *commentService) GetMyLikedComments(userId int, p *utils.PageParams) (*u
 	res.Put("total", total)
 	return res, nil
 }
+
+// [NEW] 实现 DeleteComment
+func (s *commentService) DeleteComment(commentId int, op *model.Operator) error {
+	comment, err := s.commentRepo.FindById(commentId)
+	if err != nil {
+		return errors.New("评论不存在")
+	}
+	if !op.CanManageComment(comment.UserId) {
+		return errors.New("只能删除自己的评论")
+	}
+
+	ids := []int{commentId}
+	if err := s.replyRepo.DeleteByCommentIds(ids); err != nil {
+		return errors.New("删除失败")
+	}
+	// 同时扣减文章的评论数
+	if err := s.commentRepo.DeleteByIds(ids); err != nil {
+		return errors.New("删除失败")
+	}
+	// 指向这条评论的通知 (评论、回复) 已经打不开了
+	s.notifyRepo.DeleteByCommentIds(ids)
+	return nil
+}
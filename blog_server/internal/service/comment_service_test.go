@@ -0,0 +1,88 @@
+package service
+
+import (
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"sync"
+	"testing"
+)
+
+func TestAddCommentArticleVisibility(t *testing.T) {
+	tests := []struct {
+		name      string
+		article   *model.Article
+		articleId int
+		userId    int
+		wantErr   bool
+	}{
+		{"missing article", &model.Article{Id: 1, UserId: 7, Status: model.ArticleStatusPublished}, 2, 8, true},
+		{"other user's draft", &model.Article{Id: 1, UserId: 7, Status: model.ArticleStatusDraft}, 1, 8, true},
+		{"other user's scheduled", &model.Article{Id: 1, UserId: 7, Status: model.ArticleStatusScheduled}, 1, 8, true},
+		{"other user's archived", &model.Article{Id: 1, UserId: 7, Status: model.ArticleStatusArchived}, 1, 8, true},
+		{"own draft", &model.Article{Id: 1, UserId: 7, Status: model.ArticleStatusDraft}, 1, 7, false},
+		{"published", &model.Article{Id: 1, UserId: 7, Status: model.ArticleStatusPublished}, 1, 8, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comments := &memCommentRepo{}
+			users := newMemUserRepo(&model.User{Id: 7, Username: "alice"}, &model.User{Id: 8, Username: "bob"})
+			s := NewCommentService(comments, users, &memNotificationRepo{}, &memArticleRepo{article: tt.article}, nil, noBlocks{})
+
+			comment := &model.Comment{ArticleId: tt.articleId, UserId: tt.userId, Content: "hello"}
+			err := s.AddComment(comment)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("AddComment() err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(comments.created) != 0 {
+					t.Fatal("comment saved")
+				}
+				return
+			}
+			if len(comments.created) != 1 || comment.Author != users.users[tt.userId].Username {
+				t.Fatalf("created = %+v, author = %q", comments.created, comment.Author)
+			}
+		})
+	}
+}
+
+// --- Helper Functions ---
+
+// memCommentRepo 只记录新建的评论
+type memCommentRepo struct {
+	repository.CommentRepository
+	created []*model.Comment
+}
+
+func (r *memCommentRepo) Create(comment *model.Comment) error {
+	comment.Id = len(r.created) + 1
+	r.created = append(r.created, comment)
+	return nil
+}
+
+func (r *memCommentRepo) UpdateArticleCommentCount(articleId int, step int) error {
+	return nil
+}
+
+// memNotificationRepo 记录发出的通知 (通知在协程里异步发送)
+type memNotificationRepo struct {
+	repository.NotificationRepository
+	mu   sync.Mutex
+	sent []*model.Notification
+}
+
+func (r *memNotificationRepo) Create(notify *model.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, notify)
+	return nil
+}
+
+// noBlocks 没有任何拉黑 / 屏蔽关系
+type noBlocks struct {
+	repository.BlockRepository
+}
+
+func (noBlocks) FindType(blockerId, userId int) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"my-blog/config"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// useMiniredis 把 config.RDB 换成内存 Redis，测试结束后还原
+func useMiniredis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	old := config.RDB
+	config.RDB = rdb
+	t.Cleanup(func() {
+		rdb.Close()
+		config.RDB = old
+	})
+	return mr
+}
+
+// memUserRepo 内存中的 UserRepository，只实现了测试用到的方法，调用其他方法会 panic
+type memUserRepo struct {
+	repository.UserRepository
+	users  map[int]*model.User
+	nextId int
+}
+
+func newMemUserRepo(users ...*model.User) *memUserRepo {
+	r := &memUserRepo{users: map[int]*model.User{}}
+	for _, user := range users {
+		r.users[user.Id] = user
+		r.nextId = max(r.nextId, user.Id)
+	}
+	return r
+}
+
+func (r *memUserRepo) FindById(id int) (*model.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *memUserRepo) FindByUsername(username string) (*model.User, error) {
+	return r.find(func(user *model.User) bool { return user.Username == username })
+}
+
+func (r *memUserRepo) FindByEmail(email string) (*model.User, error) {
+	return r.find(func(user *model.User) bool { return user.Email != "" && user.Email == email })
+}
+
+func (r *memUserRepo) Create(user *model.User) error {
+	r.nextId++
+	user.Id = r.nextId
+	stored := *user
+	r.users[user.Id] = &stored
+	return nil
+}
+
+func (r *memUserRepo) find(match func(user *model.User) bool) (*model.User, error) {
+	for _, user := range r.users {
+		if match(user) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+// stubRoleService 所有用户都是读者，并记录分配了默认角色的用户
+type stubRoleService struct {
+	RoleService
+	defaults []int
+}
+
+func (s *stubRoleService) AssignDefaultRole(userId int) error {
+	s.defaults = append(s.defaults, userId)
+	return nil
+}
+
+func (s *stubRoleService) GetUserAuthorities(userId int) ([]string, []string, error) {
+	return []string{model.RoleReader}, nil, nil
+}
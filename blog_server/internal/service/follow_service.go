@@ -0,0 +1,136 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"time"
+)
+
+// FeedPage 关注动态的一页
+type FeedPage struct {
+	Articles   []model.Article `json:"articles"`
+	NextCursor string          `json:"nextCursor"` // 为空表示没有更多了
+}
+
+// [NEW] 关注作者、粉丝 / 关注列表、关注动态
+type FollowService interface {
+	Follow(followerId, followeeId int) error
+	Unfollow(followerId, followeeId int) error
+	IsFollowing(followerId, followeeId int) (bool, error)
+	Followers(userId, page, pageSize int) ([]*model.FollowUser, int64, error)
+	Following(userId, page, pageSize int) ([]*model.FollowUser, int64, error)
+	// cursor 为上一页返回的 NextCursor，第一页传空
+	Feed(userId int, cursor string, pageSize int) (*FeedPage, error)
+}
+
+type followService struct {
+	followRepo  repository.FollowRepository
+	userRepo    repository.UserRepository
+	articleRepo repository.ArticleRepository
+	blockRepo   repository.BlockRepository
+}
+
+func NewFollowService(
+	followRepo repository.FollowRepository,
+	userRepo repository.UserRepository,
+	articleRepo repository.ArticleRepository,
+	blockRepo repository.BlockRepository,
+) FollowService {
+	return &followService{
+		followRepo:  followRepo,
+		userRepo:    userRepo,
+		articleRepo: articleRepo,
+		blockRepo:   blockRepo,
+	}
+}
+
+func (s *followService) Follow(followerId, followeeId int) error {
+	if followerId == followeeId {
+		return errors.New("不能关注自己")
+	}
+	user, err := s.userRepo.FindById(followeeId)
+	if err != nil || user.Valid != model.UserValid {
+		return errors.New("用户不存在")
+	}
+	// 任意一方拉黑了对方都不能关注
+	if isBlockedBy(s.blockRepo, followeeId, followerId) {
+		return errors.New("对方已将你拉黑，无法关注")
+	}
+	if isBlockedBy(s.blockRepo, followerId, followeeId) {
+		return errors.New("你已拉黑该用户，请先解除拉黑")
+	}
+	if err := s.followRepo.Follow(followerId, followeeId); err != nil {
+		return errors.New("关注失败")
+	}
+	return nil
+}
+
+func (s *followService) Unfollow(followerId, followeeId int) error {
+	if err := s.followRepo.Unfollow(followerId, followeeId); err != nil {
+		return errors.New("取消关注失败")
+	}
+	return nil
+}
+
+func (s *followService) IsFollowing(followerId, followeeId int) (bool, error) {
+	return s.followRepo.IsFollowing(followerId, followeeId)
+}
+
+func (s *followService) Followers(userId, page, pageSize int) ([]*model.FollowUser, int64, error) {
+	return s.followRepo.FindFollowers(userId, page, pageSize)
+}
+
+func (s *followService) Following(userId, page, pageSize int) ([]*model.FollowUser, int64, error) {
+	return s.followRepo.FindFollowing(userId, page, pageSize)
+}
+
+func (s *followService) Feed(userId int, cursor string, pageSize int) (*FeedPage, error) {
+	var before *model.FeedCursor
+	if cursor != "" {
+		c, err := decodeFeedCursor(cursor)
+		if err != nil {
+			return nil, errors.New("游标无效")
+		}
+		before = c
+	}
+
+	// 多查一条判断是否还有下一页
+	articles, err := s.articleRepo.FindFeed(userId, before, pageSize+1)
+	if err != nil {
+		return nil, errors.New("获取关注动态失败")
+	}
+	page := &FeedPage{Articles: articles}
+	if len(articles) > pageSize {
+		page.Articles = articles[:pageSize]
+		last := page.Articles[pageSize-1]
+		page.NextCursor = encodeFeedCursor(&model.FeedCursor{Created: last.Created, Id: last.Id})
+	}
+	if page.Articles == nil {
+		page.Articles = []model.Article{}
+	}
+	return page, nil
+}
+
+// --- Helper Functions ---
+
+// 游标对前端不透明：base64("毫秒时间戳:文章ID")
+func encodeFeedCursor(c *model.FeedCursor) string {
+	raw := fmt.Sprintf("%d:%d", c.Created.UnixMilli(), c.Id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeFeedCursor(cursor string) (*model.FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var millis int64
+	var id int
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &millis, &id); err != nil {
+		return nil, err
+	}
+	return &model.FeedCursor{Created: time.UnixMilli(millis), Id: id}, nil
+}
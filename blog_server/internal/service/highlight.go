@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// [NEW] 代码块语法高亮 (chroma)
+// 输出只带 class 不带颜色，颜色由前端引用的主题 CSS 决定 (GET /api/article/highlight.css?style=github)
+// 代码块写法：```go {3-5,8}  语言可省略 (自动识别)，{} 里是要高亮的行
+
+const DefaultHighlightStyle = "github"
+
+// 语言 + 可选的 {行号范围}
+var fenceInfoPattern = regexp.MustCompile(`^([^\s{]*)\s*(?:\{([\d,\s-]*)\})?`)
+
+// 代码块渲染器，优先级高于 goldmark 默认的 HTML 渲染器 (1000)
+type codeBlockRenderer struct{}
+
+func (r *codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.render)
+}
+
+func (r *codeBlockRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	block := node.(*ast.FencedCodeBlock)
+
+	var code strings.Builder
+	lines := block.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		code.Write(segment.Value(source))
+	}
+
+	var info []byte
+	if block.Info != nil {
+		info = block.Info.Segment.Value(source)
+	}
+	lang, ranges := parseFenceInfo(string(info))
+
+	formatter := chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(true),
+		chromahtml.HighlightLines(ranges),
+	)
+	iterator, err := detectLexer(lang, code.String()).Tokenise(nil, code.String())
+	if err != nil {
+		return ast.WalkStop, err
+	}
+	if err := formatter.Format(w, styles.Get(DefaultHighlightStyle), iterator); err != nil {
+		return ast.WalkStop, err
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// HighlightCSS 某个主题的 CSS (只包含 .chroma 下的规则)
+func HighlightCSS(style string) (string, error) {
+	s, ok := styles.Registry[style]
+	if !ok {
+		return "", errors.New("不支持的主题: " + style)
+	}
+	var buf bytes.Buffer
+	if err := chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&buf, s); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// HighlightStyles 可选的主题
+func HighlightStyles() []string {
+	return styles.Names()
+}
+
+// --- Helper Functions ---
+
+// 按语言名找 lexer，没写语言或不认识时根据代码内容猜，猜不出来就当纯文本
+func detectLexer(lang, code string) chroma.Lexer {
+	var lexer chroma.Lexer
+	if lang != "" {
+		lexer = lexers.Get(lang)
+	}
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	return chroma.Coalesce(lexer)
+}
+
+// "go {3-5,8}" -> "go", [[3 5] [8 8]]
+func parseFenceInfo(info string) (string, [][2]int) {
+	m := fenceInfoPattern.FindStringSubmatch(strings.TrimSpace(info))
+	if m == nil {
+		return "", nil
+	}
+	var ranges [][2]int
+	for _, part := range strings.Split(m[2], ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to, found := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			continue
+		}
+		end := start
+		if found {
+			if end, err = strconv.Atoi(strings.TrimSpace(to)); err != nil || end < start {
+				continue
+			}
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return strings.ToLower(m[1]), ranges
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"my-blog/config"
+	"my-blog/internal/model"
+	"strings"
+	"time"
+)
+
+// Redis Key 前缀
+const (
+	loginFailUserKey = "login_fail:user:" // + username -> 失败次数
+	loginFailIpKey   = "login_fail:ip:"   // + ip -> 失败次数
+	loginLockKey     = "login_lock:"      // + username -> Hash (锁定信息)
+)
+
+// 需要图形验证码时返回，Controller 据此通知前端展示验证码
+var ErrCaptchaRequired = errors.New("登录失败次数过多，请输入图形验证码")
+
+// LoginGuardService 登录防爆破：失败计数、图形验证码、临时锁定
+type LoginGuardService interface {
+	// 登录前检查：账号是否被锁定、是否需要并通过图形验证码
+	Check(username, ip, captcha, captchaKey string) error
+	// [NEW] 只检查账号是否被锁定 (登录第二步不需要图形验证码)
+	CheckLocked(username string) error
+	// 登录失败计数 (密码错误、两步验证码错误)，达到阈值时锁定账号并发邮件提醒 (user 为 nil 表示用户名不存在)
+	RecordFailure(username, ip string, user *model.User)
+	// 登录成功 (包括两步验证)，清空该账号的失败计数
+	RecordSuccess(username string)
+	// 当前是否需要图形验证码
+	NeedCaptcha(username, ip string) bool
+
+	// [Admin] 查看与解除锁定
+	ListLockouts() ([]*model.LoginLockout, error)
+	ClearLockout(username string) error
+}
+
+type loginGuardService struct {
+	mailService MailService
+}
+
+func NewLoginGuardService(mailService MailService) LoginGuardService {
+	return &loginGuardService{mailService: mailService}
+}
+
+func (s *loginGuardService) captchaThreshold() int {
+	if config.Config.Security.LoginCaptchaThreshold > 0 {
+		return config.Config.Security.LoginCaptchaThreshold
+	}
+	return 3
+}
+
+func (s *loginGuardService) lockThreshold() int {
+	if config.Config.Security.LoginLockThreshold > 0 {
+		return config.Config.Security.LoginLockThreshold
+	}
+	return 5
+}
+
+func (s *loginGuardService) lockDuration() time.Duration {
+	if config.Config.Security.LoginLockMinutes > 0 {
+		return time.Duration(config.Config.Security.LoginLockMinutes) * time.Minute
+	}
+	return 15 * time.Minute
+}
+
+func (s *loginGuardService) Check(username, ip, captcha, captchaKey string) error {
+	// 1. 账号是否已锁定
+	if err := s.CheckLocked(username); err != nil {
+		return err
+	}
+
+	// 2. 失败次数达到阈值后必须带图形验证码
+	if !s.NeedCaptcha(username, ip) {
+		return nil
+	}
+	if captcha == "" || captchaKey == "" {
+		return ErrCaptchaRequired
+	}
+	return verifyCaptcha(captcha, captchaKey)
+}
+
+func (s *loginGuardService) CheckLocked(username string) error {
+	ttl, err := config.RDB.TTL(config.Ctx, loginLockKey+username).Result()
+	if err == nil && ttl > 0 {
+		minutes := int(ttl.Minutes()) + 1
+		return fmt.Errorf("账号已被临时锁定，请 %d 分钟后再试", minutes)
+	}
+	return nil
+}
+
+func (s *loginGuardService) RecordFailure(username, ip string, user *model.User) {
+	window := s.lockDuration()
+
+	ipCount := config.RDB.Incr(config.Ctx, loginFailIpKey+ip).Val()
+	if ipCount == 1 {
+		config.RDB.Expire(config.Ctx, loginFailIpKey+ip, window)
+	}
+
+	userCount := config.RDB.Incr(config.Ctx, loginFailUserKey+username).Val()
+	if userCount == 1 {
+		config.RDB.Expire(config.Ctx, loginFailUserKey+username, window)
+	}
+
+	// 只锁定真实存在的账号
+	if user == nil || userCount < int64(s.lockThreshold()) {
+		return
+	}
+
+	now := time.Now()
+	until := now.Add(s.lockDuration())
+	config.RDB.HSet(config.Ctx, loginLockKey+username, map[string]interface{}{
+		"ip":       ip,
+		"failures": userCount,
+		"lockedAt": now.Unix(),
+	})
+	config.RDB.Expire(config.Ctx, loginLockKey+username, s.lockDuration())
+	config.RDB.Del(config.Ctx, loginFailUserKey+username)
+
+	// 邮件提醒 (异步，不影响响应)
+	if user.Email != "" {
+		go s.mailService.SendMail(user.Email,
+			"【你的博客名】账号安全提醒",
+			fmt.Sprintf("您的账号 <b>%s</b> 连续 %d 次密码或验证码输入错误，已被临时锁定至 %s。<br>"+
+				"最后一次尝试来自 IP：%s。如果不是您本人操作，建议尽快修改密码。",
+				username, userCount, until.Format("2006-01-02 15:04:05"), ip))
+	}
+}
+
+func (s *loginGuardService) RecordSuccess(username string) {
+	config.RDB.Del(config.Ctx, loginFailUserKey+username)
+}
+
+func (s *loginGuardService) NeedCaptcha(username, ip string) bool {
+	threshold := int64(s.captchaThreshold())
+	userCount, _ := config.RDB.Get(config.Ctx, loginFailUserKey+username).Int64()
+	ipCount, _ := config.RDB.Get(config.Ctx, loginFailIpKey+ip).Int64()
+	return userCount >= threshold || ipCount >= threshold
+}
+
+func (s *loginGuardService) ListLockouts() ([]*model.LoginLockout, error) {
+	list := []*model.LoginLockout{}
+	iter := config.RDB.Scan(config.Ctx, 0, loginLockKey+"*", 100).Iterator()
+	for iter.Next(config.Ctx) {
+		key := iter.Val()
+		data, err := config.RDB.HGetAll(config.Ctx, key).Result()
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		ttl := config.RDB.TTL(config.Ctx, key).Val()
+
+		lockout := &model.LoginLockout{
+			Username: strings.TrimPrefix(key, loginLockKey),
+			Ip:       data["ip"],
+			Until:    time.Now().Add(ttl),
+		}
+		fmt.Sscan(data["failures"], &lockout.Failures)
+		var lockedAt int64
+		fmt.Sscan(data["lockedAt"], &lockedAt)
+		lockout.LockedAt = time.Unix(lockedAt, 0)
+
+		list = append(list, lockout)
+	}
+	return list, iter.Err()
+}
+
+func (s *loginGuardService) ClearLockout(username string) error {
+	if username == "" {
+		return errors.New("用户名不能为空")
+	}
+	return config.RDB.Del(config.Ctx, loginLockKey+username, loginFailUserKey+username).Err()
+}
+
+// 校验图形验证码 (验证后立即删除，一个验证码只能用一次)
+// captcha 需要是小写 (Controller 层统一转换)
+func verifyCaptcha(captcha, captchaKey string) error {
+	redisCaptchaKey := "captcha:" + captchaKey
+	redisCaptcha, err := config.RDB.Get(config.Ctx, redisCaptchaKey).Result()
+	if err != nil || redisCaptcha == "" {
+		return errors.New("图形验证码已失效，请刷新")
+	}
+	config.RDB.Del(config.Ctx, redisCaptchaKey)
+	if redisCaptcha != captcha {
+		return errors.New("图形验证码错误")
+	}
+	return nil
+}
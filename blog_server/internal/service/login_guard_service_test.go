@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"my-blog/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoginLockout(t *testing.T) {
+	mr := useMiniredis(t)
+	g := NewLoginGuardService(nil)
+	user := &model.User{Id: 1, Username: "alice"}
+
+	// 默认阈值：失败 5 次锁定
+	for i := 1; i <= 4; i++ {
+		g.RecordFailure("alice", "10.0.0.1", user)
+		if err := g.CheckLocked("alice"); err != nil {
+			t.Fatalf("locked after %d failures: %v", i, err)
+		}
+	}
+	g.RecordFailure("alice", "10.0.0.1", user)
+	err := g.CheckLocked("alice")
+	if err == nil || !strings.Contains(err.Error(), "锁定") {
+		t.Fatalf("after 5 failures CheckLocked() = %v, want lockout", err)
+	}
+	if err := g.Check("alice", "10.0.0.9", "", ""); err == nil {
+		t.Fatal("Check() passed for a locked account")
+	}
+	lockouts, _ := g.ListLockouts()
+	if len(lockouts) != 1 || lockouts[0].Username != "alice" || lockouts[0].Failures != 5 {
+		t.Fatalf("ListLockouts() = %+v", lockouts)
+	}
+
+	// 默认锁定 15 分钟，到期后自动解锁
+	mr.FastForward(14 * time.Minute)
+	if g.CheckLocked("alice") == nil {
+		t.Fatal("lock expired too early")
+	}
+	mr.FastForward(time.Minute + time.Second)
+	if err := g.CheckLocked("alice"); err != nil {
+		t.Fatalf("lock did not expire: %v", err)
+	}
+}
+
+func TestLoginLockoutUnknownUser(t *testing.T) {
+	useMiniredis(t)
+	g := NewLoginGuardService(nil)
+
+	// 不存在的用户名只计数、不锁定 (避免借此探测用户名)
+	for i := 0; i < 10; i++ {
+		g.RecordFailure("nobody", "10.0.0.1", nil)
+	}
+	if err := g.CheckLocked("nobody"); err != nil {
+		t.Fatalf("unknown user locked: %v", err)
+	}
+}
+
+func TestLoginSuccessResetsFailures(t *testing.T) {
+	useMiniredis(t)
+	g := NewLoginGuardService(nil)
+	user := &model.User{Id: 1, Username: "alice"}
+
+	for i := 0; i < 4; i++ {
+		g.RecordFailure("alice", "10.0.0.1", user)
+	}
+	g.RecordSuccess("alice")
+	g.RecordFailure("alice", "10.0.0.2", user)
+	if err := g.CheckLocked("alice"); err != nil {
+		t.Fatalf("failures not reset by success: %v", err)
+	}
+}
+
+func TestLoginCaptchaThreshold(t *testing.T) {
+	type failure struct{ username, ip string }
+	tests := []struct {
+		name     string
+		failures []failure
+		checkIp  string
+		want     bool
+	}{
+		{"below threshold", []failure{{"alice", "10.0.0.1"}, {"alice", "10.0.0.1"}}, "10.0.0.1", false},
+		{"account threshold from any ip", []failure{{"alice", "10.0.0.1"}, {"alice", "10.0.0.2"}, {"alice", "10.0.0.3"}}, "10.0.0.99", true},
+		{"ip threshold across accounts", []failure{{"bob", "10.0.0.1"}, {"carol", "10.0.0.1"}, {"dave", "10.0.0.1"}}, "10.0.0.1", true},
+		{"other accounts from other ip", []failure{{"bob", "10.0.0.1"}, {"carol", "10.0.0.1"}, {"dave", "10.0.0.1"}}, "10.0.0.99", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useMiniredis(t)
+			g := NewLoginGuardService(nil)
+			for _, f := range tt.failures {
+				g.RecordFailure(f.username, f.ip, nil)
+			}
+			if got := g.NeedCaptcha("alice", tt.checkIp); got != tt.want {
+				t.Errorf("NeedCaptcha() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginCaptcha(t *testing.T) {
+	mr := useMiniredis(t)
+	g := NewLoginGuardService(nil)
+	for i := 0; i < 3; i++ {
+		g.RecordFailure("alice", "10.0.0.1", &model.User{Id: 1, Username: "alice"})
+	}
+
+	if err := g.Check("alice", "10.0.0.1", "", ""); !errors.Is(err, ErrCaptchaRequired) {
+		t.Fatalf("Check() without captcha = %v, want ErrCaptchaRequired", err)
+	}
+	mr.Set("captcha:k1", "abcd")
+	if err := g.Check("alice", "10.0.0.1", "wxyz", "k1"); err == nil {
+		t.Fatal("wrong captcha accepted")
+	}
+	// 验证码用过一次就作废，答错也一样
+	if err := g.Check("alice", "10.0.0.1", "abcd", "k1"); err == nil {
+		t.Fatal("captcha reused")
+	}
+	mr.Set("captcha:k2", "abcd")
+	if err := g.Check("alice", "10.0.0.1", "abcd", "k2"); err != nil {
+		t.Fatalf("correct captcha rejected: %v", err)
+	}
+}
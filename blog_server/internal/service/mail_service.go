@@ -13,6 +13,8 @@ import (
 type MailService interface {
 	SendCode(to, code string) error
 	GenerateCode() string
+	// [NEW] 发送任意 HTML 邮件 (安全提醒等)
+	SendMail(to, subject, body string) error
 }
 
 type mailService struct{}
@@ -23,15 +25,22 @@ func NewMailService() MailService {
 
 // [NEW] 发送验证码
 func (s *mailService) SendCode(to, code string) error {
+	return s.SendMail(to,
+		"【你的博客名】注册验证码",
+		fmt.Sprintf("欢迎注册，您的验证码是：<b>%s</b>。有效时间为5分钟，请勿泄露给他人。", code))
+}
+
+// [NEW] 发送邮件
+func (s *mailService) SendMail(to, subject, body string) error {
 	m := gomail.NewMessage()
 	// 发件人
 	m.SetHeader("From", config.Config.Mail.Username)
 	// 收件人
 	m.SetHeader("To", to)
 	// 主题
-	m.SetHeader("Subject", "【你的博客名】注册验证码")
+	m.SetHeader("Subject", subject)
 	// 正文
-	m.SetBody("text/html", fmt.Sprintf("欢迎注册，您的验证码是：<b>%s</b>。有效时间为5分钟，请勿泄露给他人。", code))
+	m.SetBody("text/html", body)
 
 	d := gomail.NewDialer(
 		config.Config.Mail.Host,
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"my-blog/config"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"my-blog/pkg/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Redis Key 前缀
+const (
+	mfaSetupKey    = "mfa_setup:"     // + userId -> 待确认的密钥 (10分钟)
+	mfaLastStepKey = "mfa_last_step:" // + userId -> 最近一次使用的时间步 (防重放)
+	mfaPendingKey  = "mfa_pending:"   // + sha256(mfaToken) -> Hash{userId, attempts} (登录第二步)
+)
+
+const (
+	recoveryCodeCount  = 10              // 每次生成的恢复码数量
+	mfaPendingTTL      = 5 * time.Minute // 登录第二步有效期
+	mfaPendingAttempts = 5               // 登录第二步最多尝试次数
+)
+
+// MfaSetup 开始绑定时返回给前端的数据
+type MfaSetup struct {
+	Secret string `json:"secret"` // 无法扫码时手动输入
+	Uri    string `json:"uri"`    // otpauth://totp/...
+	Qr     string `json:"qr"`     // data:image/png;base64,... 可直接赋值给 <img src>
+}
+
+// MfaStatus 两步验证状态
+type MfaStatus struct {
+	Enabled                bool  `json:"enabled"`
+	Required               bool  `json:"required"` // 当前角色是否强制要求
+	RemainingRecoveryCodes int64 `json:"remainingRecoveryCodes"`
+}
+
+type MfaService interface {
+	// 开始绑定：生成密钥，返回 otpauth 链接和二维码
+	Setup(userId int) (*MfaSetup, error)
+	// 确认绑定：校验第一个验证码后开启，返回恢复码 (明文只展示这一次)
+	Enable(userId int, code string) ([]string, error)
+	Disable(userId int, code string) error
+	RegenerateRecoveryCodes(userId int, code string) ([]string, error)
+	Status(userId int) (*MfaStatus, error)
+	// 校验 TOTP 验证码或恢复码
+	Verify(user *model.User, code string) error
+
+	// 登录第二步：密码通过后签发临时 mfaToken，提交验证码后换取正式 Token
+	CreatePendingLogin(userId int) (string, error)
+	// [MODIFY] 验证码错误计入账号的登录失败次数 (与密码错误共用锁定阈值)
+	CompletePendingLogin(mfaToken, code, ip string) (*model.User, error)
+
+	// [Admin] 重置 (用户丢失手机且没有恢复码)
+	Reset(userId int) error
+}
+
+type mfaService struct {
+	userRepo     repository.UserRepository
+	recoveryRepo repository.RecoveryCodeRepository
+	roleService  RoleService
+	loginGuard   LoginGuardService
+}
+
+func NewMfaService(
+	userRepo repository.UserRepository,
+	recoveryRepo repository.RecoveryCodeRepository,
+	roleService RoleService,
+	loginGuard LoginGuardService,
+) MfaService {
+	return &mfaService{
+		userRepo:     userRepo,
+		recoveryRepo: recoveryRepo,
+		roleService:  roleService,
+		loginGuard:   loginGuard,
+	}
+}
+
+func (s *mfaService) Setup(userId int) (*MfaSetup, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if user.TotpEnabled == 1 {
+		return nil, errors.New("已开启两步验证，如需更换请先关闭")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	issuer := config.Config.Security.MfaIssuer
+	if issuer == "" {
+		issuer = "MyBlog"
+	}
+	uri := utils.TOTPURI(issuer, user.Username, secret)
+
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, errors.New("二维码生成失败")
+	}
+
+	// 确认之前密钥只放在 Redis
+	config.RDB.Set(config.Ctx, mfaSetupKey+strconv.Itoa(userId), secret, 10*time.Minute)
+
+	return &MfaSetup{
+		Secret: secret,
+		Uri:    uri,
+		Qr:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+func (s *mfaService) Enable(userId int, code string) ([]string, error) {
+	key := mfaSetupKey + strconv.Itoa(userId)
+	secret, err := config.RDB.Get(config.Ctx, key).Result()
+	if err != nil || secret == "" {
+		return nil, errors.New("绑定已过期，请重新扫码")
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, 1)
+	if !ok {
+		return nil, errors.New("验证码错误")
+	}
+
+	if err := s.userRepo.UpdateTotp(userId, secret, 1); err != nil {
+		return nil, err
+	}
+	config.RDB.Del(config.Ctx, key)
+	config.RDB.Set(config.Ctx, mfaLastStepKey+strconv.Itoa(userId), step, 2*time.Minute)
+
+	return s.newRecoveryCodes(userId)
+}
+
+func (s *mfaService) Disable(userId int, code string) error {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+	if user.TotpEnabled != 1 {
+		return errors.New("未开启两步验证")
+	}
+	roles, _, _ := s.roleService.GetUserAuthorities(userId)
+	if mfaRequiredFor(roles) {
+		return errors.New("当前角色必须开启两步验证，无法关闭")
+	}
+	if err := s.Verify(user, code); err != nil {
+		return err
+	}
+	return s.Reset(userId)
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(userId int, code string) ([]string, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if user.TotpEnabled != 1 {
+		return nil, errors.New("未开启两步验证")
+	}
+	if err := s.Verify(user, code); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(userId)
+}
+
+func (s *mfaService) Status(userId int) (*MfaStatus, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	roles, _, _ := s.roleService.GetUserAuthorities(userId)
+	remaining, _ := s.recoveryRepo.CountUnused(userId)
+
+	return &MfaStatus{
+		Enabled:                user.TotpEnabled == 1,
+		Required:               mfaRequiredFor(roles),
+		RemainingRecoveryCodes: remaining,
+	}, nil
+}
+
+func (s *mfaService) Verify(user *model.User, code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return errors.New("请输入验证码")
+	}
+	if user.TotpEnabled != 1 || user.TotpSecret == "" {
+		return errors.New("未开启两步验证")
+	}
+
+	// 1. 6 位数字：TOTP 验证码
+	if len(code) == 6 && strings.Trim(code, "0123456789") == "" {
+		step, ok := utils.ValidateTOTP(user.TotpSecret, code, 1)
+		if !ok {
+			return errors.New("验证码错误")
+		}
+		// 同一个时间步的验证码只能用一次
+		key := mfaLastStepKey + strconv.Itoa(user.Id)
+		if last, err := config.RDB.Get(config.Ctx, key).Int64(); err == nil && step <= last {
+			return errors.New("验证码已使用，请等待下一个验证码")
+		}
+		config.RDB.Set(config.Ctx, key, step, 2*time.Minute)
+		return nil
+	}
+
+	// 2. 其他：恢复码 (一次性)
+	recovery, err := s.recoveryRepo.FindUnused(user.Id, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return errors.New("验证码错误")
+	}
+	if err := s.recoveryRepo.MarkUsed(recovery.Id); err != nil {
+		return errors.New("恢复码已使用")
+	}
+	return nil
+}
+
+func (s *mfaService) CreatePendingLogin(userId int) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	key := mfaPendingKey + hashToken(token)
+	if err := config.RDB.HSet(config.Ctx, key, "userId", userId, "attempts", 0).Err(); err != nil {
+		return "", err
+	}
+	config.RDB.Expire(config.Ctx, key, mfaPendingTTL)
+	return token, nil
+}
+
+func (s *mfaService) CompletePendingLogin(mfaToken, code, ip string) (*model.User, error) {
+	key := mfaPendingKey + hashToken(mfaToken)
+	userId, err := config.RDB.HGet(config.Ctx, key, "userId").Int()
+	if err != nil {
+		return nil, errors.New("验证已过期，请重新登录")
+	}
+
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	// 账号已锁定时，之前签发的 mfaToken 一并作废
+	if err := s.loginGuard.CheckLocked(user.Username); err != nil {
+		config.RDB.Del(config.Ctx, key)
+		return nil, err
+	}
+
+	if err := s.Verify(user, code); err != nil {
+		// 计入账号的失败次数：反复重新输入密码拿新的 mfaToken 也无法绕过锁定
+		s.loginGuard.RecordFailure(user.Username, ip, user)
+		if lockErr := s.loginGuard.CheckLocked(user.Username); lockErr != nil {
+			config.RDB.Del(config.Ctx, key)
+			return nil, lockErr
+		}
+		// 超过尝试次数作废，必须重新输入密码
+		if config.RDB.HIncrBy(config.Ctx, key, "attempts", 1).Val() >= mfaPendingAttempts {
+			config.RDB.Del(config.Ctx, key)
+			return nil, errors.New("验证码错误次数过多，请重新登录")
+		}
+		return nil, err
+	}
+
+	config.RDB.Del(config.Ctx, key)
+	s.loginGuard.RecordSuccess(user.Username)
+	return user, nil
+}
+
+func (s *mfaService) Reset(userId int) error {
+	if err := s.userRepo.UpdateTotp(userId, "", 0); err != nil {
+		return err
+	}
+	config.RDB.Del(config.Ctx, mfaSetupKey+strconv.Itoa(userId), mfaLastStepKey+strconv.Itoa(userId))
+	return s.recoveryRepo.DeleteByUserId(userId)
+}
+
+// --- Helper Functions ---
+
+// 生成一批新的恢复码 (旧的全部作废)，返回明文
+func (s *mfaService) newRecoveryCodes(userId int) ([]string, error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789" // 去掉易混淆的 0/o/1/l/i
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 8)
+		for j := range b {
+			// rand.Int 在 [0, n) 内均匀分布 (直接对随机字节取模会偏向前面的字符)
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+			if err != nil {
+				return nil, err
+			}
+			b[j] = alphabet[n.Int64()]
+		}
+		code := string(b[:4]) + "-" + string(b[4:])
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := s.recoveryRepo.ReplaceForUser(userId, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// 恢复码输入时忽略大小写和连字符
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+// 角色中是否有被配置为必须开启两步验证的
+func mfaRequiredFor(roles []string) bool {
+	for _, role := range config.Config.Security.MfaRequiredRoles {
+		if slices.Contains(roles, role) {
+			return true
+		}
+	}
+	return false
+}
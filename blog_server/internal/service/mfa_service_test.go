@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"my-blog/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompletePendingLoginLocksAccount(t *testing.T) {
+	mr := useMiniredis(t)
+	s, user := newMfaTestService(t)
+
+	// 每次都重新输入密码拿新的 mfaToken，失败次数照样累计
+	var err error
+	for i := 0; i < 5; i++ {
+		token, _ := s.CreatePendingLogin(user.Id)
+		if _, err = s.CompletePendingLogin(token, "wrong-code", "10.0.0.1"); err == nil {
+			t.Fatal("wrong code accepted")
+		}
+	}
+	if !strings.Contains(err.Error(), "锁定") {
+		t.Fatalf("after 5 failures err = %v, want lockout", err)
+	}
+	if !mr.Exists(loginLockKey + user.Username) {
+		t.Fatal("account not locked")
+	}
+
+	// 锁定期间正确的验证码也不行
+	token, _ := s.CreatePendingLogin(user.Id)
+	if _, err := s.CompletePendingLogin(token, currentTOTP(t, user.TotpSecret), "10.0.0.1"); err == nil {
+		t.Fatal("locked account passed second step")
+	}
+}
+
+func TestCompletePendingLoginResetsFailures(t *testing.T) {
+	mr := useMiniredis(t)
+	s, user := newMfaTestService(t)
+
+	token, _ := s.CreatePendingLogin(user.Id)
+	if _, err := s.CompletePendingLogin(token, "wrong-code", "10.0.0.1"); err == nil {
+		t.Fatal("wrong code accepted")
+	}
+	if !mr.Exists(loginFailUserKey + user.Username) {
+		t.Fatal("second-step failure not counted")
+	}
+
+	got, err := s.CompletePendingLogin(token, currentTOTP(t, user.TotpSecret), "10.0.0.1")
+	if err != nil || got.Id != user.Id {
+		t.Fatalf("CompletePendingLogin = %v, %v", got, err)
+	}
+	if mr.Exists(loginFailUserKey + user.Username) {
+		t.Fatal("failure count not cleared after second step")
+	}
+	if _, err := s.CompletePendingLogin(token, currentTOTP(t, user.TotpSecret), "10.0.0.1"); err == nil {
+		t.Fatal("mfaToken used twice")
+	}
+}
+
+func TestRecoveryCodesUniform(t *testing.T) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	repo := &capturedRecoveryCodes{}
+	s := &mfaService{recoveryRepo: repo}
+
+	counts := map[rune]int{}
+	total := 0
+	for i := 0; i < 2000; i++ {
+		codes, err := s.newRecoveryCodes(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(codes) != recoveryCodeCount || len(repo.hashes) != recoveryCodeCount {
+			t.Fatalf("got %d codes, %d hashes", len(codes), len(repo.hashes))
+		}
+		for _, code := range codes {
+			if len(code) != 9 || code[4] != '-' {
+				t.Fatalf("bad code format: %q", code)
+			}
+			for _, r := range normalizeRecoveryCode(code) {
+				if !strings.ContainsRune(alphabet, r) {
+					t.Fatalf("unexpected character %q in %q", r, code)
+				}
+				counts[r]++
+				total++
+			}
+		}
+	}
+
+	// 每个字符约 5161 次 (标准差约 72，允许偏差 7% 约为 5 倍标准差)；取模的偏差会让前 8 个字符多出 12.5%
+	expected := float64(total) / float64(len(alphabet))
+	for _, r := range alphabet {
+		if diff := float64(counts[r]) - expected; diff > expected*0.07 || diff < -expected*0.07 {
+			t.Errorf("character %q appeared %d times, expected about %.0f", r, counts[r], expected)
+		}
+	}
+}
+
+// --- Helper Functions ---
+
+func newMfaTestService(t *testing.T) (MfaService, *model.User) {
+	t.Helper()
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{Id: 1, Username: "alice", Valid: model.UserValid, TotpEnabled: 1, TotpSecret: secret}
+	s := NewMfaService(newMemUserRepo(user), noRecoveryCodes{}, nil, NewLoginGuardService(nil))
+	return s, user
+}
+
+func currentTOTP(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := utils.TOTPCode(secret, time.Now().Unix()/30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// noRecoveryCodes 没有任何恢复码
+type noRecoveryCodes struct {
+	repository.RecoveryCodeRepository
+}
+
+func (noRecoveryCodes) FindUnused(userId int, hash string) (*model.RecoveryCode, error) {
+	return nil, errors.New("record not found")
+}
+
+// capturedRecoveryCodes 记录最近一次生成的恢复码哈希
+type capturedRecoveryCodes struct {
+	noRecoveryCodes
+	hashes []string
+}
+
+func (r *capturedRecoveryCodes) ReplaceForUser(userId int, hashes []string) error {
+	r.hashes = hashes
+	return nil
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"my-blog/config"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// ExternalIdentity 第三方返回的用户信息 (统一格式)
+type ExternalIdentity struct {
+	Subject       string // 第三方的用户唯一 ID
+	Email         string
+	EmailVerified bool // 第三方确认过邮箱归属，才允许按邮箱关联已有账号
+	Username      string
+	Avatar        string
+}
+
+// OAuthProvider 第三方登录提供方
+// 新增一种提供方：实现该接口，并在 newOAuthProvider 中按 type 注册
+type OAuthProvider interface {
+	Name() string
+	DisplayName() string
+	// 生成跳转到第三方授权页的地址 (state 防 CSRF，verifier 用于 PKCE，nonce 用于 OIDC ID Token)
+	AuthCodeURL(state, verifier, nonce string) (string, error)
+	// 用回调拿到的授权码换取用户信息
+	Exchange(ctx context.Context, code, verifier, nonce string) (*ExternalIdentity, error)
+}
+
+// 按配置创建提供方
+func newOAuthProvider(cfg config.OAuthProviderConfig) (OAuthProvider, error) {
+	if cfg.Name == "" || cfg.ClientId == "" {
+		return nil, errors.New("name 和 client_id 不能为空")
+	}
+	if cfg.DisplayName == "" {
+		cfg.DisplayName = cfg.Name
+	}
+
+	switch cfg.Type {
+	case "github":
+		return newGithubProvider(cfg), nil
+	case "oidc":
+		if cfg.Issuer == "" {
+			return nil, errors.New("oidc 类型必须配置 issuer")
+		}
+		return &oidcProvider{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("不支持的类型: %s", cfg.Type)
+	}
+}
+
+// ==========================================
+// GitHub (OAuth2，没有 ID Token，需要调用 API 获取用户信息)
+// ==========================================
+
+type githubProvider struct {
+	cfg    config.OAuthProviderConfig
+	oauth2 *oauth2.Config
+}
+
+func newGithubProvider(cfg config.OAuthProviderConfig) *githubProvider {
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"read:user", "user:email"}
+	}
+	return &githubProvider{
+		cfg: cfg,
+		oauth2: &oauth2.Config{
+			ClientID:     cfg.ClientId,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectUrl,
+			Endpoint:     github.Endpoint,
+			Scopes:       scopes,
+		},
+	}
+}
+
+func (p *githubProvider) Name() string        { return p.cfg.Name }
+func (p *githubProvider) DisplayName() string { return p.cfg.DisplayName }
+
+func (p *githubProvider) AuthCodeURL(state, verifier, nonce string) (string, error) {
+	return p.oauth2.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (p *githubProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*ExternalIdentity, error) {
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, err
+	}
+	client := p.oauth2.Client(ctx, token)
+
+	// 1. 基本信息
+	var user struct {
+		Id        int64  `json:"id"`
+		Login     string `json:"login"`
+		AvatarUrl string `json:"avatar_url"`
+	}
+	if err := getJSON(client, "https://api.github.com/user", &user); err != nil {
+		return nil, err
+	}
+	identity := &ExternalIdentity{
+		Subject:  strconv.FormatInt(user.Id, 10),
+		Username: user.Login,
+		Avatar:   user.AvatarUrl,
+	}
+
+	// 2. 邮箱 (公开资料里的邮箱可能为空，取已验证的主邮箱)
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(client, "https://api.github.com/user/emails", &emails); err == nil {
+		for _, e := range emails {
+			if e.Primary && e.Verified {
+				identity.Email = e.Email
+				identity.EmailVerified = true
+				break
+			}
+		}
+	}
+	return identity, nil
+}
+
+// ==========================================
+// 通用 OpenID Connect (Keycloak、Google 等)
+// ==========================================
+
+type oidcProvider struct {
+	cfg config.OAuthProviderConfig
+
+	// 发现文档在第一次使用时才拉取 (启动时第三方不可用不影响本站启动)
+	mu       sync.Mutex
+	provider *oidc.Provider
+	oauth2   *oauth2.Config
+}
+
+func (p *oidcProvider) Name() string        { return p.cfg.Name }
+func (p *oidcProvider) DisplayName() string { return p.cfg.DisplayName }
+
+func (p *oidcProvider) init(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.provider != nil {
+		return nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, p.cfg.Issuer)
+	if err != nil {
+		return err
+	}
+	scopes := p.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	p.provider = provider
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.cfg.ClientId,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectUrl,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+	return nil
+}
+
+func (p *oidcProvider) AuthCodeURL(state, verifier, nonce string) (string, error) {
+	if err := p.init(context.Background()); err != nil {
+		return "", err
+	}
+	return p.oauth2.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oidc.Nonce(nonce)), nil
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*ExternalIdentity, error) {
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验 ID Token (签名、签发者、受众、过期时间、nonce)
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("缺少 id_token")
+	}
+	idToken, err := p.provider.Verifier(&oidc.Config{ClientID: p.cfg.ClientId}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("nonce 不匹配")
+	}
+
+	var claims struct {
+		Email             string `json:"email"`
+		EmailVerified     bool   `json:"email_verified"`
+		PreferredUsername string `json:"preferred_username"`
+		Name              string `json:"name"`
+		Picture           string `json:"picture"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	username := claims.PreferredUsername
+	if username == "" {
+		username = claims.Name
+	}
+	return &ExternalIdentity{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Username:      username,
+		Avatar:        claims.Picture,
+	}, nil
+}
+
+// GET 请求并解析 JSON
+func getJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("请求 %s 失败: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
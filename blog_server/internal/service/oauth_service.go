@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"my-blog/config"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"regexp"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
+)
+
+// Redis Key 前缀
+const (
+	oauthStateKey  = "oauth_state:"  // + state -> Hash{provider, verifier, nonce, bindUserId} (10分钟)
+	oauthTicketKey = "oauth_ticket:" // + sha256(ticket) -> userId (回调后前端用 ticket 换 Token，1分钟)
+)
+
+// OAuthProviderInfo 登录页展示的第三方登录按钮
+type OAuthProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type OAuthService interface {
+	// 已配置的第三方登录列表
+	Providers() []OAuthProviderInfo
+	// 生成第三方授权地址；bindUserId > 0 表示已登录用户绑定第三方账号，否则是登录
+	AuthURL(provider string, bindUserId int) (string, error)
+	// 第三方回调：校验 state，换取用户信息，关联或创建本站用户
+	// 登录流程返回一次性 ticket；绑定流程 bound 为 true
+	Callback(provider, code, state string) (ticket string, bound bool, err error)
+	// 前端用 ticket 换取对应的用户 (一次性)
+	ConsumeTicket(ticket string) (*model.User, error)
+
+	// 已绑定的第三方账号
+	ListIdentities(userId int) ([]*model.UserIdentity, error)
+	Unbind(userId int, provider string) error
+}
+
+type oauthService struct {
+	providers    map[string]OAuthProvider
+	order        []string // 保持配置文件中的顺序
+	userRepo     repository.UserRepository
+	identityRepo repository.UserIdentityRepository
+	roleService  RoleService
+}
+
+func NewOAuthService(
+	userRepo repository.UserRepository,
+	identityRepo repository.UserIdentityRepository,
+	roleService RoleService,
+) OAuthService {
+	s := &oauthService{
+		providers:    make(map[string]OAuthProvider),
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		roleService:  roleService,
+	}
+	for _, cfg := range config.Config.OAuth.Providers {
+		if cfg.ClientId == "" {
+			continue // 未填写的提供方视为未启用
+		}
+		provider, err := newOAuthProvider(cfg)
+		if err != nil {
+			log.Printf("⚠️ 第三方登录 [%s] 配置错误，已跳过: %v", cfg.Name, err)
+			continue
+		}
+		s.providers[provider.Name()] = provider
+		s.order = append(s.order, provider.Name())
+	}
+	return s
+}
+
+func (s *oauthService) Providers() []OAuthProviderInfo {
+	list := []OAuthProviderInfo{}
+	for _, name := range s.order {
+		p := s.providers[name]
+		list = append(list, OAuthProviderInfo{Name: p.Name(), DisplayName: p.DisplayName()})
+	}
+	return list
+}
+
+func (s *oauthService) AuthURL(providerName string, bindUserId int) (string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", errors.New("不支持的登录方式")
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	key := oauthStateKey + state
+	err = config.RDB.HSet(config.Ctx, key,
+		"provider", providerName,
+		"verifier", verifier,
+		"nonce", nonce,
+		"bindUserId", bindUserId).Err()
+	if err != nil {
+		return "", err
+	}
+	config.RDB.Expire(config.Ctx, key, 10*time.Minute)
+
+	url, err := provider.AuthCodeURL(state, verifier, nonce)
+	if err != nil {
+		log.Printf("⚠️ 第三方登录 [%s] 不可用: %v", providerName, err)
+		return "", errors.New("第三方登录暂时不可用")
+	}
+	return url, nil
+}
+
+func (s *oauthService) Callback(providerName, code, state string) (string, bool, error) {
+	// 1. 校验 state (一次性)
+	key := oauthStateKey + state
+	data, err := config.RDB.HGetAll(config.Ctx, key).Result()
+	if err != nil || len(data) == 0 || data["provider"] != providerName {
+		return "", false, errors.New("登录已过期，请重试")
+	}
+	// 并发重放同一个 state 时只有一个请求能删除成功
+	if config.RDB.Del(config.Ctx, key).Val() == 0 {
+		return "", false, errors.New("登录已过期，请重试")
+	}
+	bindUserId, _ := strconv.Atoi(data["bindUserId"])
+
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", false, errors.New("不支持的登录方式")
+	}
+
+	// 2. 授权码换用户信息
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	ext, err := provider.Exchange(ctx, code, data["verifier"], data["nonce"])
+	if err != nil {
+		log.Printf("⚠️ 第三方登录 [%s] 换取用户信息失败: %v", providerName, err)
+		return "", false, errors.New("第三方授权失败")
+	}
+
+	// 3. 绑定流程
+	if bindUserId > 0 {
+		return "", true, s.bind(bindUserId, providerName, ext)
+	}
+
+	// 4. 登录流程：找到或创建本站用户，发 ticket
+	user, err := s.resolveUser(providerName, ext)
+	if err != nil {
+		return "", false, err
+	}
+	ticket, err := randomToken()
+	if err != nil {
+		return "", false, err
+	}
+	config.RDB.Set(config.Ctx, oauthTicketKey+hashToken(ticket), user.Id, time.Minute)
+	return ticket, false, nil
+}
+
+func (s *oauthService) ConsumeTicket(ticket string) (*model.User, error) {
+	userId, err := config.RDB.GetDel(config.Ctx, oauthTicketKey+hashToken(ticket)).Int()
+	if err != nil {
+		return nil, errors.New("登录已过期，请重试")
+	}
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	return user, nil
+}
+
+func (s *oauthService) ListIdentities(userId int) ([]*model.UserIdentity, error) {
+	return s.identityRepo.FindByUserId(userId)
+}
+
+func (s *oauthService) Unbind(userId int, provider string) error {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+	// 第三方注册且没有邮箱的账号，解绑最后一个第三方账号后将无法登录 (也无法通过邮箱找回密码)
+	identities, _ := s.identityRepo.FindByUserId(userId)
+	if len(identities) <= 1 && user.Email == "" {
+		return errors.New("请先绑定邮箱，否则解绑后将无法登录")
+	}
+	if err := s.identityRepo.Delete(userId, provider); err != nil {
+		return errors.New("未绑定该账号")
+	}
+	return nil
+}
+
+// --- Helper Functions ---
+
+// 登录：已绑定的直接登录；邮箱已验证且与本站账号一致的自动关联；否则创建新账号
+func (s *oauthService) resolveUser(provider string, ext *ExternalIdentity) (*model.User, error) {
+	if identity, err := s.identityRepo.FindByProviderSubject(provider, ext.Subject); err == nil {
+		s.identityRepo.UpdateLastLogin(identity.Id, time.Now())
+		user, err := s.userRepo.FindById(identity.UserId)
+		if err != nil {
+			return nil, errors.New("用户不存在")
+		}
+		return user, nil
+	}
+
+	var user *model.User
+	if ext.EmailVerified && ext.Email != "" {
+		if existing, err := s.userRepo.FindByEmail(ext.Email); err == nil {
+			user = existing
+		}
+	}
+	if user == nil {
+		created, err := s.createUser(provider, ext)
+		if err != nil {
+			return nil, err
+		}
+		user = created
+	}
+
+	if err := s.createIdentity(user.Id, provider, ext); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// 绑定到已登录的用户
+func (s *oauthService) bind(userId int, provider string, ext *ExternalIdentity) error {
+	if identity, err := s.identityRepo.FindByProviderSubject(provider, ext.Subject); err == nil {
+		if identity.UserId != userId {
+			return errors.New("该第三方账号已绑定其他用户")
+		}
+		return nil
+	}
+	identities, _ := s.identityRepo.FindByUserId(userId)
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			return errors.New("已绑定过该平台的其他账号，请先解绑")
+		}
+	}
+	return s.createIdentity(userId, provider, ext)
+}
+
+func (s *oauthService) createIdentity(userId int, provider string, ext *ExternalIdentity) error {
+	now := time.Now()
+	identity := &model.UserIdentity{
+		UserId:    userId,
+		Provider:  provider,
+		Subject:   ext.Subject,
+		Email:     ext.Email,
+		Nickname:  ext.Username,
+		Created:   now,
+		LastLogin: &now,
+	}
+	if err := s.identityRepo.Create(identity); err != nil {
+		return errors.New("绑定第三方账号失败")
+	}
+	return nil
+}
+
+var usernameUnsafe = regexp.MustCompile(`[^\p{Han}A-Za-z0-9_\-]`)
+
+// 首次第三方登录：自动注册 (随机密码，用户之后可以通过邮箱重置)
+func (s *oauthService) createUser(provider string, ext *ExternalIdentity) (*model.User, error) {
+	base := usernameUnsafe.ReplaceAllString(ext.Username, "")
+	if runes := []rune(base); len(runes) > 16 {
+		base = string(runes[:16])
+	}
+	if base == "" {
+		base = provider + "_user"
+	}
+
+	// 用户名冲突时追加随机数字
+	username := base
+	for i := 0; ; i++ {
+		if _, err := s.userRepo.FindByUsername(username); err != nil {
+			break
+		}
+		if i >= 5 {
+			return nil, errors.New("自动注册失败，请稍后重试")
+		}
+		username = base + "_" + strconv.Itoa(1000+rand.IntN(9000))
+	}
+
+	password, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPwd, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+
+	user := &model.User{
+		Username: username,
+		Password: string(hashedPwd),
+		Avatar:   "/api/images/default-avatar.png",
+		Created:  time.Now(),
+		Valid:    1,
+	}
+	// 只保存第三方确认过的邮箱
+	if ext.EmailVerified {
+		user.Email = ext.Email
+	}
+
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, errors.New("自动注册失败")
+	}
+	if err := s.roleService.AssignDefaultRole(user.Id); err != nil {
+		return nil, errors.New("自动注册失败: 分配角色出错")
+	}
+	return user, nil
+}
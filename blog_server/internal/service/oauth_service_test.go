@@ -0,0 +1,392 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"my-blog/config"
+	"my-blog/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	mockProvider = "mock"
+	mockClientId = "blog"
+	mockKid      = "test-key"
+)
+
+func TestOAuthFirstLoginCreatesUser(t *testing.T) {
+	s, idp, users, identities := newOAuthTestService(t)
+
+	claims := map[string]any{
+		"sub":                "u-1",
+		"email":              "new@example.com",
+		"email_verified":     true,
+		"preferred_username": "new user!",
+	}
+	user := consumeTicket(t, s, oauthLogin(t, s, idp, claims))
+	if user.Username != "newuser" || user.Email != "new@example.com" {
+		t.Fatalf("created user = %+v", user)
+	}
+	if len(identities.identities) != 1 || identities.identities[0].UserId != user.Id {
+		t.Fatalf("identities = %+v", identities.identities)
+	}
+
+	// 再次登录使用已绑定的账号，不会重复创建
+	again := consumeTicket(t, s, oauthLogin(t, s, idp, claims))
+	if again.Id != user.Id || len(users.users) != 1 {
+		t.Fatalf("second login = user %d, %d users", again.Id, len(users.users))
+	}
+}
+
+func TestOAuthTicketSingleUse(t *testing.T) {
+	s, idp, _, _ := newOAuthTestService(t)
+
+	ticket := oauthLogin(t, s, idp, map[string]any{"sub": "u-1", "preferred_username": "bob"})
+	consumeTicket(t, s, ticket)
+	if _, err := s.ConsumeTicket(ticket); err == nil {
+		t.Fatal("ticket used twice")
+	}
+	if _, err := s.ConsumeTicket("forged"); err == nil {
+		t.Fatal("unknown ticket accepted")
+	}
+}
+
+func TestOAuthNonceMismatch(t *testing.T) {
+	s, idp, users, _ := newOAuthTestService(t)
+
+	authURL, err := s.AuthURL(mockProvider, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, code := idp.authorize(t, authURL, map[string]any{"sub": "u-1", "nonce": "forged"})
+	if _, _, err := s.Callback(mockProvider, code, state); err == nil {
+		t.Fatal("id_token with wrong nonce accepted")
+	}
+	if len(users.users) != 0 {
+		t.Fatal("user created after failed login")
+	}
+}
+
+func TestOAuthStateReplay(t *testing.T) {
+	s, idp, _, _ := newOAuthTestService(t)
+	claims := map[string]any{"sub": "u-1"}
+
+	authURL, err := s.AuthURL(mockProvider, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, code := idp.authorize(t, authURL, claims)
+	if _, _, err := s.Callback(mockProvider, code, state); err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+
+	// 同一个 state 配上新的授权码也不能再用
+	_, code = idp.authorize(t, authURL, claims)
+	if _, _, err := s.Callback(mockProvider, code, state); err == nil {
+		t.Fatal("replayed state accepted")
+	}
+	if _, _, err := s.Callback(mockProvider, code, "forged"); err == nil {
+		t.Fatal("unknown state accepted")
+	}
+}
+
+func TestOAuthEmailLinking(t *testing.T) {
+	tests := []struct {
+		name       string
+		verified   bool
+		wantLinked bool
+	}{
+		{name: "verified email links existing account", verified: true, wantLinked: true},
+		{name: "unverified email creates new account", verified: false, wantLinked: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, idp, users, _ := newOAuthTestService(t)
+			users.Create(&model.User{Username: "alice", Email: "alice@example.com"})
+
+			user := consumeTicket(t, s, oauthLogin(t, s, idp, map[string]any{
+				"sub":                "u-1",
+				"email":              "alice@example.com",
+				"email_verified":     tt.verified,
+				"preferred_username": "alice",
+			}))
+			if linked := user.Id == 1; linked != tt.wantLinked {
+				t.Fatalf("logged in as user %d, linked = %v, want %v", user.Id, linked, tt.wantLinked)
+			}
+			if !tt.wantLinked && user.Email != "" {
+				t.Fatalf("unverified email saved: %q", user.Email)
+			}
+		})
+	}
+}
+
+// --- Helper Functions ---
+
+func newOAuthTestService(t *testing.T) (OAuthService, *mockOIDC, *memUserRepo, *memIdentityRepo) {
+	t.Helper()
+	useMiniredis(t)
+	idp := newMockOIDC(t)
+
+	old := config.Config.OAuth.Providers
+	config.Config.OAuth.Providers = []config.OAuthProviderConfig{{
+		Name:         mockProvider,
+		Type:         "oidc",
+		ClientId:     mockClientId,
+		ClientSecret: "secret",
+		RedirectUrl:  "http://localhost:8080/api/oauth/mock/callback",
+		Issuer:       idp.srv.URL,
+	}}
+	t.Cleanup(func() { config.Config.OAuth.Providers = old })
+
+	users := newMemUserRepo()
+	identities := &memIdentityRepo{}
+	return NewOAuthService(users, identities, &stubRoleService{}), idp, users, identities
+}
+
+// 完整走一遍登录流程，返回 ticket
+func oauthLogin(t *testing.T, s OAuthService, idp *mockOIDC, claims map[string]any) string {
+	t.Helper()
+	authURL, err := s.AuthURL(mockProvider, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, code := idp.authorize(t, authURL, claims)
+	ticket, bound, err := s.Callback(mockProvider, code, state)
+	if err != nil || bound {
+		t.Fatalf("Callback = %v, bound %v", err, bound)
+	}
+	return ticket
+}
+
+func consumeTicket(t *testing.T, s OAuthService, ticket string) *model.User {
+	t.Helper()
+	user, err := s.ConsumeTicket(ticket)
+	if err != nil {
+		t.Fatalf("ConsumeTicket: %v", err)
+	}
+	return user
+}
+
+// mockOIDC 模拟的 OpenID Connect 提供方：发现文档、JWKS、token、userinfo
+type mockOIDC struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	codes  map[string]mockGrant      // 授权码 -> 授权信息 (一次性)
+	tokens map[string]map[string]any // access token -> userinfo
+}
+
+type mockGrant struct {
+	challenge string // PKCE code_challenge (S256)
+	claims    map[string]any
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockOIDC{key: key, codes: map[string]mockGrant{}, tokens: map[string]map[string]any{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                                m.srv.URL,
+			"authorization_endpoint":                m.srv.URL + "/authorize",
+			"token_endpoint":                        m.srv.URL + "/token",
+			"jwks_uri":                              m.srv.URL + "/jwks",
+			"userinfo_endpoint":                     m.srv.URL + "/userinfo",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": mockKid,
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", m.token)
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		claims, ok := m.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+		m.mu.Unlock()
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+			return
+		}
+		writeJSON(w, http.StatusOK, claims)
+	})
+
+	m.srv = httptest.NewServer(mux)
+	t.Cleanup(m.srv.Close)
+	return m
+}
+
+// authorize 模拟用户在授权页同意授权，返回回调里的 state 和授权码
+// claims 里没有 nonce 时使用授权地址中的 nonce
+func (m *mockOIDC) authorize(t *testing.T, authURL string, claims map[string]any) (string, string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if !strings.HasPrefix(authURL, m.srv.URL+"/authorize?") || q.Get("client_id") != mockClientId {
+		t.Fatalf("unexpected auth url: %s", authURL)
+	}
+	if q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("PKCE not used: %s", authURL)
+	}
+
+	granted := map[string]any{"nonce": q.Get("nonce")}
+	for k, v := range claims {
+		granted[k] = v
+	}
+	code, err := randomToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	m.codes[code] = mockGrant{challenge: q.Get("code_challenge"), claims: granted}
+	m.mu.Unlock()
+	return q.Get("state"), code
+}
+
+func (m *mockOIDC) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	clientId, _, ok := r.BasicAuth()
+	if !ok {
+		clientId = r.PostForm.Get("client_id")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	grant, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || clientId != mockClientId ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": m.srv.URL,
+		"aud": mockClientId,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	}
+	for k, v := range grant.claims {
+		claims[k] = v
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = mockKid
+	signed, err := idToken.SignedString(m.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	accessToken, _ := randomToken()
+	m.tokens[accessToken] = grant.claims
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// memIdentityRepo 内存中的 UserIdentityRepository
+type memIdentityRepo struct {
+	identities []*model.UserIdentity
+}
+
+func (r *memIdentityRepo) Create(identity *model.UserIdentity) error {
+	identity.Id = len(r.identities) + 1
+	stored := *identity
+	r.identities = append(r.identities, &stored)
+	return nil
+}
+
+func (r *memIdentityRepo) FindByProviderSubject(provider, subject string) (*model.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			found := *identity
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *memIdentityRepo) FindByUserId(userId int) ([]*model.UserIdentity, error) {
+	var found []*model.UserIdentity
+	for _, identity := range r.identities {
+		if identity.UserId == userId {
+			found = append(found, identity)
+		}
+	}
+	return found, nil
+}
+
+func (r *memIdentityRepo) UpdateLastLogin(id int, t time.Time) error {
+	for _, identity := range r.identities {
+		if identity.Id == id {
+			identity.LastLogin = &t
+		}
+	}
+	return nil
+}
+
+func (r *memIdentityRepo) Delete(userId int, provider string) error {
+	return r.deleteWhere(func(identity *model.UserIdentity) bool {
+		return identity.UserId == userId && identity.Provider == provider
+	})
+}
+
+func (r *memIdentityRepo) DeleteByUserId(userId int) error {
+	return r.deleteWhere(func(identity *model.UserIdentity) bool { return identity.UserId == userId })
+}
+
+func (r *memIdentityRepo) deleteWhere(match func(identity *model.UserIdentity) bool) error {
+	kept := r.identities[:0]
+	for _, identity := range r.identities {
+		if !match(identity) {
+			kept = append(kept, identity)
+		}
+	}
+	if len(kept) == len(r.identities) {
+		return errors.New("record not found")
+	}
+	r.identities = kept
+	return nil
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"my-blog/config"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// Redis Key 前缀
+const (
+	passkeyRegKey   = "passkey_reg:"   // + userId -> 注册仪式的 SessionData (JSON)
+	passkeyLoginKey = "passkey_login:" // + sha256(loginKey) -> 登录仪式的 SessionData (JSON)
+)
+
+const passkeyCeremonyTTL = 5 * time.Minute // 挑战有效期
+
+type PasskeyService interface {
+	// 注册仪式：生成 navigator.credentials.create() 的参数
+	BeginRegistration(userId int) (*protocol.CredentialCreation, error)
+	// 校验浏览器返回的凭证并保存
+	FinishRegistration(userId int, name string, response []byte) (*model.Passkey, error)
+
+	// 登录仪式 (可发现凭证，不需要先输入用户名)：返回 navigator.credentials.get() 的参数和本次登录的 loginKey
+	BeginLogin() (*protocol.CredentialAssertion, string, error)
+	// 校验签名，返回对应用户以及认证器是否做了用户验证 (指纹/PIN)
+	FinishLogin(loginKey string, response []byte) (*model.User, bool, error)
+
+	// 管理
+	List(userId int) ([]*model.Passkey, error)
+	Rename(userId, id int, name string) error
+	Remove(userId, id int) error
+}
+
+type passkeyService struct {
+	webAuthn    *webauthn.WebAuthn
+	passkeyRepo repository.PasskeyRepository
+	userRepo    repository.UserRepository
+}
+
+func NewPasskeyService(passkeyRepo repository.PasskeyRepository, userRepo repository.UserRepository) PasskeyService {
+	cfg := config.Config.Webauthn
+	if cfg.RPID == "" {
+		cfg.RPID = "localhost"
+	}
+	if cfg.RPDisplayName == "" {
+		cfg.RPDisplayName = "MyBlog"
+	}
+	if len(cfg.RPOrigins) == 0 {
+		cfg.RPOrigins = []string{"http://localhost:5173"}
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: cfg.RPDisplayName,
+		RPOrigins:     cfg.RPOrigins,
+	})
+	if err != nil {
+		log.Fatalf("❌ WebAuthn 配置错误: %v", err)
+	}
+
+	return &passkeyService{
+		webAuthn:    w,
+		passkeyRepo: passkeyRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (s *passkeyService) BeginRegistration(userId int) (*protocol.CredentialCreation, error) {
+	user, err := s.loadUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 要求可发现凭证 (Passkey)，并排除已绑定的认证器，防止重复注册
+	creation, session, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+		webauthn.WithExclusions(webauthn.Credentials(user.credentials).CredentialDescriptors()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := saveCeremony(passkeyRegKey+strconv.Itoa(userId), session); err != nil {
+		return nil, err
+	}
+	return creation, nil
+}
+
+func (s *passkeyService) FinishRegistration(userId int, name string, response []byte) (*model.Passkey, error) {
+	session, err := takeCeremony(passkeyRegKey + strconv.Itoa(userId))
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.loadUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, errors.New("通行密钥数据格式错误")
+	}
+	credential, err := s.webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, errors.New("通行密钥校验失败")
+	}
+
+	credentialId := base64.RawURLEncoding.EncodeToString(credential.ID)
+	if _, err := s.passkeyRepo.FindByCredentialId(credentialId); err == nil {
+		return nil, errors.New("该通行密钥已绑定")
+	}
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	passkey := &model.Passkey{
+		UserId:       userId,
+		Name:         passkeyName(name),
+		CredentialId: credentialId,
+		Credential:   string(data),
+		Created:      time.Now(),
+	}
+	if err := s.passkeyRepo.Create(passkey); err != nil {
+		return nil, errors.New("保存通行密钥失败")
+	}
+	return passkey, nil
+}
+
+func (s *passkeyService) BeginLogin() (*protocol.CredentialAssertion, string, error) {
+	assertion, session, err := s.webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationPreferred),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	loginKey, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	if err := saveCeremony(passkeyLoginKey+hashToken(loginKey), session); err != nil {
+		return nil, "", err
+	}
+	return assertion, loginKey, nil
+}
+
+func (s *passkeyService) FinishLogin(loginKey string, response []byte) (*model.User, bool, error) {
+	session, err := takeCeremony(passkeyLoginKey + hashToken(loginKey))
+	if err != nil {
+		return nil, false, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, false, errors.New("通行密钥数据格式错误")
+	}
+
+	// 浏览器返回的 userHandle 就是注册时的 WebAuthnID (用户 ID)
+	var owner *passkeyUser
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		userId, err := strconv.Atoi(string(userHandle))
+		if err != nil {
+			return nil, errors.New("invalid user handle")
+		}
+		if owner, err = s.loadUser(userId); err != nil {
+			return nil, err
+		}
+		return owner, nil
+	}
+
+	credential, err := s.webAuthn.ValidateDiscoverableLogin(handler, *session, parsed)
+	if err != nil {
+		return nil, false, errors.New("通行密钥验证失败")
+	}
+	// 签名计数回退，说明认证器可能被复制
+	if credential.Authenticator.CloneWarning {
+		return nil, false, errors.New("检测到通行密钥异常，请使用密码登录")
+	}
+
+	passkey, err := s.passkeyRepo.FindByCredentialId(base64.RawURLEncoding.EncodeToString(credential.ID))
+	if err != nil || passkey.UserId != owner.user.Id {
+		return nil, false, errors.New("通行密钥不存在")
+	}
+	if data, err := json.Marshal(credential); err == nil {
+		s.passkeyRepo.UpdateCredential(passkey.Id, string(data), time.Now())
+	}
+
+	return owner.user, credential.Flags.UserVerified, nil
+}
+
+func (s *passkeyService) List(userId int) ([]*model.Passkey, error) {
+	return s.passkeyRepo.FindByUserId(userId)
+}
+
+func (s *passkeyService) Rename(userId, id int, name string) error {
+	if _, err := s.findOwned(userId, id); err != nil {
+		return err
+	}
+	return s.passkeyRepo.UpdateName(id, passkeyName(name))
+}
+
+func (s *passkeyService) Remove(userId, id int) error {
+	if _, err := s.findOwned(userId, id); err != nil {
+		return err
+	}
+	return s.passkeyRepo.Delete(id)
+}
+
+// --- Helper Functions ---
+
+// passkeyUser 把 model.User 适配成 webauthn.User
+type passkeyUser struct {
+	user        *model.User
+	credentials []webauthn.Credential
+}
+
+func (u *passkeyUser) WebAuthnID() []byte                         { return []byte(strconv.Itoa(u.user.Id)) }
+func (u *passkeyUser) WebAuthnName() string                       { return u.user.Username }
+func (u *passkeyUser) WebAuthnDisplayName() string                { return u.user.Username }
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential { return u.credentials }
+
+// 查询用户及其已绑定的凭证
+func (s *passkeyService) loadUser(userId int) (*passkeyUser, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	passkeys, err := s.passkeyRepo.FindByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(passkeys))
+	for _, p := range passkeys {
+		var credential webauthn.Credential
+		if err := json.Unmarshal([]byte(p.Credential), &credential); err == nil {
+			credentials = append(credentials, credential)
+		}
+	}
+	return &passkeyUser{user: user, credentials: credentials}, nil
+}
+
+// 只能操作自己的通行密钥
+func (s *passkeyService) findOwned(userId, id int) (*model.Passkey, error) {
+	passkey, err := s.passkeyRepo.FindById(id)
+	if err != nil || passkey.UserId != userId {
+		return nil, errors.New("通行密钥不存在")
+	}
+	return passkey, nil
+}
+
+// 名称为空时给默认值，过长截断
+func passkeyName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "通行密钥"
+	}
+	if runes := []rune(name); len(runes) > 50 {
+		return string(runes[:50])
+	}
+	return name
+}
+
+// 仪式数据存入 Redis (挑战只能使用一次)
+func saveCeremony(key string, session *webauthn.SessionData) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return config.RDB.Set(config.Ctx, key, data, passkeyCeremonyTTL).Err()
+}
+
+func takeCeremony(key string) (*webauthn.SessionData, error) {
+	data, err := config.RDB.GetDel(config.Ctx, key).Bytes()
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("操作已过期，请重试")
+	}
+	var session webauthn.SessionData
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, errors.New("操作已过期，请重试")
+	}
+	return &session, nil
+}
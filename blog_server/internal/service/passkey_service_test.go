@@ -0,0 +1,417 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"my-blog/config"
+	"my-blog/internal/model"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:5173"
+)
+
+func TestPasskeyCeremony(t *testing.T) {
+	s, repo := newPasskeyTestService(t)
+	key := newSoftAuthenticator(t)
+
+	passkey := registerPasskey(t, s, key, 1)
+	if passkey.UserId != 1 || passkey.Name != "MacBook" {
+		t.Fatalf("passkey = %+v", passkey)
+	}
+
+	key.signCount = 1
+	user, verified, err := loginPasskey(t, s, key, 1, testOrigin)
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+	if user.Id != 1 || !verified {
+		t.Fatalf("FinishLogin = user %d, verified %v", user.Id, verified)
+	}
+
+	stored := repo.passkeys[passkey.Id]
+	if stored.LastUsed == nil {
+		t.Fatal("LastUsed not updated")
+	}
+	var credential webauthn.Credential
+	if err := json.Unmarshal([]byte(stored.Credential), &credential); err != nil {
+		t.Fatal(err)
+	}
+	if credential.Authenticator.SignCount != key.signCount {
+		t.Fatalf("stored sign count = %d, want %d", credential.Authenticator.SignCount, key.signCount)
+	}
+}
+
+func TestPasskeyReplayedChallenge(t *testing.T) {
+	s, _ := newPasskeyTestService(t)
+	key := newSoftAuthenticator(t)
+
+	creation, err := s.BeginRegistration(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := key.create(t, creation, testOrigin)
+	if _, err := s.FinishRegistration(1, "", response); err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	if _, err := s.FinishRegistration(1, "", response); err == nil {
+		t.Fatal("replayed registration accepted")
+	}
+
+	assertion, loginKey, err := s.BeginLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response = key.get(t, assertion, testOrigin, 1)
+	if _, _, err := s.FinishLogin(loginKey, response); err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+	key.signCount++
+	if _, _, err := s.FinishLogin(loginKey, key.get(t, assertion, testOrigin, 1)); err == nil {
+		t.Fatal("replayed login challenge accepted")
+	}
+}
+
+func TestPasskeyWrongOrigin(t *testing.T) {
+	s, repo := newPasskeyTestService(t)
+	key := newSoftAuthenticator(t)
+
+	creation, err := s.BeginRegistration(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FinishRegistration(1, "", key.create(t, creation, "https://evil.example")); err == nil {
+		t.Fatal("registration from wrong origin accepted")
+	}
+	if len(repo.passkeys) != 0 {
+		t.Fatal("passkey saved after failed registration")
+	}
+
+	registerPasskey(t, s, key, 1)
+	if _, _, err := loginPasskey(t, s, key, 1, "https://evil.example"); err == nil {
+		t.Fatal("login from wrong origin accepted")
+	}
+}
+
+func TestPasskeyOtherUsersCredential(t *testing.T) {
+	s, _ := newPasskeyTestService(t)
+	key := newSoftAuthenticator(t)
+	registerPasskey(t, s, key, 1)
+
+	// 用户 1 的凭证冒充用户 2 登录
+	if _, _, err := loginPasskey(t, s, key, 2, testOrigin); err == nil {
+		t.Fatal("login with another user's credential accepted")
+	}
+
+	// 同一个凭证不能再绑定到用户 2
+	creation, err := s.BeginRegistration(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FinishRegistration(2, "", key.create(t, creation, testOrigin)); err == nil {
+		t.Fatal("credential registered twice")
+	}
+}
+
+func TestPasskeySignCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		counts  []uint32 // 每次登录时认证器的签名计数
+		wantErr bool     // 最后一次登录是否失败
+	}{
+		{name: "increasing", counts: []uint32{1, 2, 10}},
+		{name: "always zero", counts: []uint32{0, 0}},
+		{name: "repeated", counts: []uint32{3, 3}, wantErr: true},
+		{name: "rollback", counts: []uint32{5, 2}, wantErr: true},
+		{name: "zero after non-zero", counts: []uint32{4, 0}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newPasskeyTestService(t)
+			key := newSoftAuthenticator(t)
+			registerPasskey(t, s, key, 1)
+
+			for i, count := range tt.counts {
+				key.signCount = count
+				_, _, err := loginPasskey(t, s, key, 1, testOrigin)
+				last := i == len(tt.counts)-1
+				if !last && err != nil {
+					t.Fatalf("login %d: %v", i, err)
+				}
+				if last && (err != nil) != tt.wantErr {
+					t.Fatalf("login %d: err = %v, wantErr %v", i, err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestPasskeyOwnership(t *testing.T) {
+	s, repo := newPasskeyTestService(t)
+	passkey := registerPasskey(t, s, newSoftAuthenticator(t), 1)
+
+	if err := s.Rename(2, passkey.Id, "stolen"); err == nil {
+		t.Fatal("renamed another user's passkey")
+	}
+	if err := s.Remove(2, passkey.Id); err == nil {
+		t.Fatal("removed another user's passkey")
+	}
+	if repo.passkeys[passkey.Id].Name != "MacBook" {
+		t.Fatalf("name = %q", repo.passkeys[passkey.Id].Name)
+	}
+
+	if err := s.Rename(1, passkey.Id, "  "); err != nil {
+		t.Fatal(err)
+	}
+	if repo.passkeys[passkey.Id].Name != "通行密钥" {
+		t.Fatalf("name = %q", repo.passkeys[passkey.Id].Name)
+	}
+	if err := s.Remove(1, passkey.Id); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.passkeys) != 0 {
+		t.Fatal("passkey not removed")
+	}
+}
+
+// --- Helper Functions ---
+
+func newPasskeyTestService(t *testing.T) (PasskeyService, *memPasskeyRepo) {
+	t.Helper()
+	useMiniredis(t)
+	oldCfg := config.Config.Webauthn
+	config.Config.Webauthn.RPID = testRPID
+	config.Config.Webauthn.RPOrigins = []string{testOrigin}
+	t.Cleanup(func() { config.Config.Webauthn = oldCfg })
+
+	users := newMemUserRepo(&model.User{Id: 1, Username: "alice"}, &model.User{Id: 2, Username: "bob"})
+	repo := &memPasskeyRepo{passkeys: map[int]*model.Passkey{}}
+	return NewPasskeyService(repo, users), repo
+}
+
+func registerPasskey(t *testing.T, s PasskeyService, key *softAuthenticator, userId int) *model.Passkey {
+	t.Helper()
+	creation, err := s.BeginRegistration(userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	passkey, err := s.FinishRegistration(userId, "MacBook", key.create(t, creation, testOrigin))
+	if err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	return passkey
+}
+
+// 用 key 登录，userHandle 为 userId
+func loginPasskey(t *testing.T, s PasskeyService, key *softAuthenticator, userId int, origin string) (*model.User, bool, error) {
+	t.Helper()
+	assertion, loginKey, err := s.BeginLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.FinishLogin(loginKey, key.get(t, assertion, origin, userId))
+}
+
+// softAuthenticator 进程内的软件认证器 (ECDSA P-256，attestation 为 none)
+type softAuthenticator struct {
+	key       *ecdsa.PrivateKey
+	id        []byte
+	signCount uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return &softAuthenticator{key: key, id: id}
+}
+
+// authenticatorData 的标志位
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttestedData = 0x40
+)
+
+// 注册：返回浏览器提交的 PublicKeyCredential (JSON)
+func (a *softAuthenticator) create(t *testing.T, creation *protocol.CredentialCreation, origin string) []byte {
+	t.Helper()
+	clientData := clientDataJSON(t, "webauthn.create", creation.Response.Challenge, origin)
+
+	publicKey, err := webauthncbor.Marshal(map[int]any{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		-3: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authData := a.authData(flagUserPresent | flagUserVerified | flagAttestedData)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.id)))
+	authData = append(authData, a.id...)
+	authData = append(authData, publicKey...)
+
+	attestation, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.credential(t, map[string]string{
+		"clientDataJSON":    b64(clientData),
+		"attestationObject": b64(attestation),
+	})
+}
+
+// 登录：对 authenticatorData || SHA-256(clientDataJSON) 签名
+func (a *softAuthenticator) get(t *testing.T, assertion *protocol.CredentialAssertion, origin string, userId int) []byte {
+	t.Helper()
+	clientData := clientDataJSON(t, "webauthn.get", assertion.Response.Challenge, origin)
+	authData := a.authData(flagUserPresent | flagUserVerified)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.credential(t, map[string]string{
+		"clientDataJSON":    b64(clientData),
+		"authenticatorData": b64(authData),
+		"signature":         b64(signature),
+		"userHandle":        b64([]byte(strconv.Itoa(userId))),
+	})
+}
+
+// rpIdHash | flags | signCount
+func (a *softAuthenticator) authData(flags byte) []byte {
+	rpIdHash := sha256.Sum256([]byte(testRPID))
+	data := append(rpIdHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, a.signCount)
+}
+
+func (a *softAuthenticator) credential(t *testing.T, response map[string]string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{
+		"id":       b64(a.id),
+		"rawId":    b64(a.id),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func clientDataJSON(t *testing.T, typ string, challenge protocol.URLEncodedBase64, origin string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]string{
+		"type":      typ,
+		"challenge": b64(challenge),
+		"origin":    origin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// memPasskeyRepo 内存中的 PasskeyRepository
+type memPasskeyRepo struct {
+	passkeys map[int]*model.Passkey
+	nextId   int
+}
+
+func (r *memPasskeyRepo) Create(passkey *model.Passkey) error {
+	r.nextId++
+	passkey.Id = r.nextId
+	stored := *passkey
+	r.passkeys[passkey.Id] = &stored
+	return nil
+}
+
+func (r *memPasskeyRepo) FindById(id int) (*model.Passkey, error) {
+	passkey, ok := r.passkeys[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	found := *passkey
+	return &found, nil
+}
+
+func (r *memPasskeyRepo) FindByUserId(userId int) ([]*model.Passkey, error) {
+	var passkeys []*model.Passkey
+	for _, passkey := range r.passkeys {
+		if passkey.UserId == userId {
+			found := *passkey
+			passkeys = append(passkeys, &found)
+		}
+	}
+	return passkeys, nil
+}
+
+func (r *memPasskeyRepo) FindByCredentialId(credentialId string) (*model.Passkey, error) {
+	for _, passkey := range r.passkeys {
+		if passkey.CredentialId == credentialId {
+			found := *passkey
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *memPasskeyRepo) UpdateCredential(id int, credential string, lastUsed time.Time) error {
+	if passkey, ok := r.passkeys[id]; ok {
+		passkey.Credential, passkey.LastUsed = credential, &lastUsed
+	}
+	return nil
+}
+
+func (r *memPasskeyRepo) UpdateName(id int, name string) error {
+	if passkey, ok := r.passkeys[id]; ok {
+		passkey.Name = name
+	}
+	return nil
+}
+
+func (r *memPasskeyRepo) Delete(id int) error {
+	delete(r.passkeys, id)
+	return nil
+}
+
+func (r *memPasskeyRepo) DeleteByUserId(userId int) error {
+	for id, passkey := range r.passkeys {
+		if passkey.UserId == userId {
+			delete(r.passkeys, id)
+		}
+	}
+	return nil
+}
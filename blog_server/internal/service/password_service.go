@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"my-blog/config"
+	"my-blog/internal/repository"
+	"my-blog/pkg/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// [NEW] 密码策略：强度、泄露密码库、历史密码
+// 注册、重置密码、修改密码都必须经过这里，校验失败返回 *utils.PasswordError (带错误码)
+type PasswordService interface {
+	Policy() utils.PasswordPolicy
+	// 校验新密码；userId 为 0 (注册) 时不检查历史，currentHash 为当前密码 (没有历史记录的老用户也不能原样改回)
+	Check(userId int, currentHash, password string) error
+	// 校验通过后加密
+	Hash(password string) (string, error)
+	// 密码修改成功后记入历史
+	Remember(userId int, hashed string)
+}
+
+type passwordService struct {
+	historyRepo repository.PasswordHistoryRepository
+}
+
+func NewPasswordService(historyRepo repository.PasswordHistoryRepository) PasswordService {
+	return &passwordService{historyRepo: historyRepo}
+}
+
+func (s *passwordService) Policy() utils.PasswordPolicy {
+	return config.PasswordPolicy()
+}
+
+func (s *passwordService) Check(userId int, currentHash, password string) error {
+	policy := s.Policy()
+	if err := policy.Check(password); err != nil {
+		return err
+	}
+	if config.BreachedCheckEnabled() && utils.IsBreachedPassword(password) {
+		return &utils.PasswordError{Code: utils.PwdErrBreached, Msg: "该密码出现在已泄露的密码库中，请换一个"}
+	}
+
+	if userId == 0 || policy.HistorySize <= 0 {
+		return nil
+	}
+	hashes, err := s.historyRepo.FindRecent(userId, policy.HistorySize)
+	if err != nil {
+		return errors.New("密码校验失败")
+	}
+	if currentHash != "" {
+		hashes = append(hashes, currentHash)
+	}
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return &utils.PasswordError{Code: utils.PwdErrReused, Msg: "不能使用最近用过的密码"}
+		}
+	}
+	return nil
+}
+
+func (s *passwordService) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", errors.New("密码加密失败")
+	}
+	return string(hashed), nil
+}
+
+func (s *passwordService) Remember(userId int, hashed string) {
+	keep := s.Policy().HistorySize
+	if keep <= 0 {
+		return
+	}
+	if err := s.historyRepo.Add(userId, hashed, keep); err != nil {
+		log.Printf("⚠️ 密码历史记录失败 (userId=%d): %v", userId, err)
+	}
+}
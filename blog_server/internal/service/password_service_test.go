@@ -0,0 +1,144 @@
+package service
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"my-blog/config"
+	"my-blog/internal/repository"
+	"my-blog/pkg/utils"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordCheck(t *testing.T) {
+	s, history := newPasswordTestService(t, 3)
+	// 从旧到新改过 4 次密码，历史只保留最近 3 次
+	for _, old := range []string{"Oldest-Pass-1", "Older-Pass-2", "Old-Pass-3", "Recent-Pass-4"} {
+		history.Add(1, bcryptHash(t, old), 3)
+	}
+	current := bcryptHash(t, "Current-Pass-5")
+
+	tests := []struct {
+		name     string
+		userId   int
+		password string
+		wantCode string // 为空表示校验通过
+	}{
+		{"too short", 1, "Ab1!", utils.PwdErrTooShort},
+		{"single character class", 1, "abcdefghijkl", utils.PwdErrTooWeak},
+		{"breached", 1, "Summer-Breach-2024", utils.PwdErrBreached},
+		{"breached on registration", 0, "Summer-Breach-2024", utils.PwdErrBreached},
+		{"current password", 1, "Current-Pass-5", utils.PwdErrReused},
+		{"recent password", 1, "Recent-Pass-4", utils.PwdErrReused},
+		{"oldest kept password", 1, "Older-Pass-2", utils.PwdErrReused},
+		{"outside history window", 1, "Oldest-Pass-1", ""},
+		{"registration has no history", 0, "Recent-Pass-4", ""},
+		{"other user's history", 2, "Recent-Pass-4", ""},
+		{"fresh password", 1, "Blue-Harbor-7421", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentHash := ""
+			if tt.userId == 1 {
+				currentHash = current
+			}
+			err := s.Check(tt.userId, currentHash, tt.password)
+			if got := passwordErrCode(err); got != tt.wantCode {
+				t.Errorf("Check(%q) = %v, want code %q", tt.password, err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestPasswordBreachedCheckDisabled(t *testing.T) {
+	s, _ := newPasswordTestService(t, 0)
+	if err := s.Check(1, "", "Summer-Breach-2024"); passwordErrCode(err) != utils.PwdErrBreached {
+		t.Fatalf("Check() with default config = %v, want breached", err)
+	}
+
+	off := false
+	config.Config.Password.BreachedCheck = &off
+	if err := s.Check(1, "", "Summer-Breach-2024"); err != nil {
+		t.Fatalf("Check() with breached_check: false = %v", err)
+	}
+}
+
+func TestPasswordRemember(t *testing.T) {
+	s, history := newPasswordTestService(t, 2)
+	for _, hash := range []string{"h1", "h2", "h3"} {
+		s.Remember(1, hash)
+	}
+	if got, _ := history.FindRecent(1, 10); strings.Join(got, ",") != "h3,h2" {
+		t.Fatalf("history = %v, want [h3 h2]", got)
+	}
+
+	s, history = newPasswordTestService(t, 0)
+	s.Remember(1, "h1")
+	if got, _ := history.FindRecent(1, 10); len(got) != 0 {
+		t.Fatalf("history kept with history_size 0: %v", got)
+	}
+}
+
+// --- Helper Functions ---
+
+// 使用测试专用的泄露密码库 (只有 "Summer-Breach-2024")，结束后还原配置和内置密码库
+func newPasswordTestService(t *testing.T, historySize int) (PasswordService, *memPasswordHistory) {
+	t.Helper()
+	old := config.Config.Password
+	config.Config.Password.HistorySize = historySize
+	config.Config.Password.BreachedCheck = nil
+	sum := sha1.Sum([]byte("Summer-Breach-2024"))
+	if _, err := utils.LoadBreachedPasswords(strings.NewReader(hex.EncodeToString(sum[:]) + ":42\n")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		config.Config.Password = old
+		utils.LoadBreachedPasswords(nil)
+	})
+
+	history := &memPasswordHistory{}
+	return NewPasswordService(history), history
+}
+
+func bcryptHash(t *testing.T, password string) string {
+	t.Helper()
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(hashed)
+}
+
+func passwordErrCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var pwdErr *utils.PasswordError
+	if errors.As(err, &pwdErr) {
+		return pwdErr.Code
+	}
+	return err.Error()
+}
+
+// memPasswordHistory 每个用户的历史密码 (新的在前)
+type memPasswordHistory struct {
+	repository.PasswordHistoryRepository
+	hashes map[int][]string
+}
+
+func (r *memPasswordHistory) Add(userId int, password string, keep int) error {
+	if r.hashes == nil {
+		r.hashes = map[int][]string{}
+	}
+	list := append([]string{password}, r.hashes[userId]...)
+	r.hashes[userId] = list[:min(len(list), keep)]
+	return nil
+}
+
+func (r *memPasswordHistory) FindRecent(userId int, limit int) ([]string, error) {
+	list := r.hashes[userId]
+	return list[:min(len(list), limit)], nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxNicknameLen    = 32
+	maxBioLen         = 200
+	maxLocationLen    = 64
+	maxProfileUrlLen  = 255
+	maxSocialLinksNum = 8
+)
+
+// [NEW] 个人主页：公开资料 + 统计 + 发表的文章，不包含邮箱等隐私字段
+type ProfileService interface {
+	GetProfile(userId, page, pageSize int) (*model.UserProfile, error)
+	// 修改自己的公开资料 (整体覆盖)
+	UpdateProfile(userId int, form *model.ProfileForm) (*model.PublicUser, error)
+}
+
+type profileService struct {
+	userRepo    repository.UserRepository
+	articleRepo repository.ArticleRepository
+}
+
+func NewProfileService(userRepo repository.UserRepository, articleRepo repository.ArticleRepository) ProfileService {
+	return &profileService{userRepo: userRepo, articleRepo: articleRepo}
+}
+
+func (s *profileService) GetProfile(userId, page, pageSize int) (*model.UserProfile, error) {
+	user, err := s.userRepo.FindById(userId)
+	// 被封禁的用户不展示主页
+	if err != nil || user.Valid != model.UserValid {
+		return nil, errors.New("用户不存在")
+	}
+
+	stats, err := s.userRepo.CountProfileStats(userId)
+	if err != nil {
+		return nil, errors.New("获取用户统计失败")
+	}
+	articles, total, err := s.articleRepo.Search(page, pageSize, &model.ArticleCondition{UserId: userId})
+	if err != nil {
+		return nil, errors.New("获取用户文章失败")
+	}
+	if articles == nil {
+		articles = []model.Article{}
+	}
+
+	return &model.UserProfile{
+		User:         user.Public(),
+		Stats:        stats,
+		Articles:     articles,
+		ArticleTotal: total,
+	}, nil
+}
+
+func (s *profileService) UpdateProfile(userId int, form *model.ProfileForm) (*model.PublicUser, error) {
+	if err := normalizeProfile(form); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateProfile(userId, form); err != nil {
+		return nil, errors.New("保存失败")
+	}
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	return user.Public(), nil
+}
+
+// --- Helper Functions ---
+
+// 去掉首尾空格并校验长度、链接格式
+func normalizeProfile(form *model.ProfileForm) error {
+	form.Nickname = strings.TrimSpace(form.Nickname)
+	form.Bio = strings.TrimSpace(form.Bio)
+	form.Website = strings.TrimSpace(form.Website)
+	form.Location = strings.TrimSpace(form.Location)
+	form.Cover = strings.TrimSpace(form.Cover)
+
+	if utf8.RuneCountInString(form.Nickname) > maxNicknameLen {
+		return fmt.Errorf("昵称不能超过 %d 个字", maxNicknameLen)
+	}
+	if utf8.RuneCountInString(form.Bio) > maxBioLen {
+		return fmt.Errorf("个人简介不能超过 %d 个字", maxBioLen)
+	}
+	if utf8.RuneCountInString(form.Location) > maxLocationLen {
+		return fmt.Errorf("所在地不能超过 %d 个字", maxLocationLen)
+	}
+	if form.Website != "" && !validHttpUrl(form.Website) {
+		return errors.New("个人网站必须是 http(s) 链接")
+	}
+	if len(form.Cover) > maxProfileUrlLen {
+		return errors.New("封面图地址过长")
+	}
+
+	if len(form.SocialLinks) > maxSocialLinksNum {
+		return fmt.Errorf("社交链接最多 %d 个", maxSocialLinksNum)
+	}
+	links := make([]model.SocialLink, 0, len(form.SocialLinks))
+	for _, link := range form.SocialLinks {
+		link.Platform = strings.ToLower(strings.TrimSpace(link.Platform))
+		link.Url = strings.TrimSpace(link.Url)
+		if link.Url == "" {
+			continue
+		}
+		if !slices.Contains(model.SocialLinkPlatforms, link.Platform) {
+			return fmt.Errorf("不支持的社交平台: %s", link.Platform)
+		}
+		if !validHttpUrl(link.Url) {
+			return errors.New("社交链接必须是 http(s) 链接")
+		}
+		links = append(links, link)
+	}
+	form.SocialLinks = links
+	return nil
+}
+
+// 只允许 http(s) 链接，防止 javascript: 之类的地址被渲染到主页上
+func validHttpUrl(raw string) bool {
+	if len(raw) > maxProfileUrlLen {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"math"
+	"my-blog/config"
+	"my-blog/internal/model"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// 渲染规则有变化时修改版本号，旧缓存自然失效
+	renderCacheKey = "article:render:v2:"
+	renderCacheTTL = 7 * 24 * time.Hour
+
+	// 阅读速度：中文按字、英文按单词
+	cjkCharsPerMinute   = 300
+	latinWordsPerMinute = 200
+)
+
+// [NEW] 文章正文渲染：Markdown -> HTML (过滤 XSS) + 目录 + 字数 / 阅读时间
+// 结果按正文内容的哈希缓存在 Redis 里，正文不变就不用重新渲染
+type RenderService interface {
+	Render(content string) (*model.RenderedArticle, error)
+}
+
+type renderService struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+	text     *bluemonday.Policy // 去掉所有标签，只留文字 (用于统计字数)
+}
+
+func NewRenderService() RenderService {
+	return &renderService{
+		markdown: goldmark.New(
+			goldmark.WithExtensions(extension.GFM),
+			goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+			// 正文里的 HTML (包括富文本编辑器保存的文章) 原样输出，统一交给 bluemonday 过滤
+			goldmark.WithRendererOptions(
+				goldmarkhtml.WithUnsafe(),
+				// [NEW] 代码块语法高亮
+				renderer.WithNodeRenderers(util.Prioritized(&codeBlockRenderer{}, 100)),
+			),
+		),
+		policy: newArticlePolicy(),
+		text:   bluemonday.StrictPolicy(),
+	}
+}
+
+func (s *renderService) Render(content string) (*model.RenderedArticle, error) {
+	sum := sha256.Sum256([]byte(content))
+	key := renderCacheKey + hex.EncodeToString(sum[:])
+	if data, err := config.RDB.Get(config.Ctx, key).Bytes(); err == nil {
+		var cached model.RenderedArticle
+		if json.Unmarshal(data, &cached) == nil {
+			return &cached, nil
+		}
+	}
+
+	rendered, err := s.render(content)
+	if err != nil {
+		return nil, err
+	}
+	// 缓存失败不影响返回
+	if data, err := json.Marshal(rendered); err == nil {
+		if err := config.RDB.Set(config.Ctx, key, data, renderCacheTTL).Err(); err != nil {
+			log.Printf("⚠️ 文章渲染结果缓存失败: %v", err)
+		}
+	}
+	return rendered, nil
+}
+
+func (s *renderService) render(content string) (*model.RenderedArticle, error) {
+	source := []byte(content)
+	ctx := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	doc := s.markdown.Parser().Parse(text.NewReader(source), parser.WithContext(ctx))
+
+	var buf bytes.Buffer
+	if err := s.markdown.Renderer().Render(&buf, source, doc); err != nil {
+		return nil, fmt.Errorf("渲染失败: %w", err)
+	}
+	safeHtml := s.policy.Sanitize(buf.String())
+
+	words, cjk := countWords(html.UnescapeString(s.text.Sanitize(safeHtml)))
+	return &model.RenderedArticle{
+		Html:           safeHtml,
+		Toc:            buildToc(doc, source),
+		WordCount:      words + cjk,
+		ReadingMinutes: readingMinutes(words, cjk),
+	}, nil
+}
+
+// --- Helper Functions ---
+
+// 白名单：bluemonday 的 UGC 规则 (不允许 script、事件属性、javascript: 链接等)
+// 额外允许：标题的中文锚点、代码块的语言和高亮、表格和段落的对齐方式、任务列表的复选框
+func newArticlePolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	// [NEW] 代码高亮 (chroma) 的 class：pre.chroma、span.line / span.line.hl / span.ln / span.k ...
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^chroma$`)).OnElements("pre")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^(line|line hl|[a-z][a-z0-9]{0,2})$`)).OnElements("span")
+	p.AllowStyles("text-align").MatchingEnum("left", "center", "right", "justify").OnElements("p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "th", "td")
+	// 任务列表 (- [x] ...)
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// 目录：按标题出现的顺序，级别更低的标题挂到前面最近的更高级标题下面
+func buildToc(doc ast.Node, source []byte) []*model.TocItem {
+	toc := []*model.TocItem{}
+	var stack []*model.TocItem
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		id, _ := heading.AttributeString("id")
+		idBytes, _ := id.([]byte)
+		item := &model.TocItem{Level: heading.Level, Id: string(idBytes), Text: nodeText(heading, source)}
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= item.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			toc = append(toc, item)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, item)
+		}
+		stack = append(stack, item)
+		return ast.WalkSkipChildren, nil
+	})
+	return toc
+}
+
+// 标题的纯文本 (去掉加粗、链接等 Markdown 标记)
+func nodeText(n ast.Node, source []byte) string {
+	var sb strings.Builder
+	ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := child.(type) {
+		case *ast.Text:
+			sb.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String())
+}
+
+// 统计字数：中日韩文字每个字算一个，其他连续的字母数字算一个单词
+func countWords(s string) (words, cjk int) {
+	inWord := false
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+				inWord = true
+			}
+		default:
+			inWord = false
+		}
+	}
+	return words, cjk
+}
+
+func readingMinutes(words, cjk int) int {
+	minutes := float64(cjk)/cjkCharsPerMinute + float64(words)/latinWordsPerMinute
+	return max(int(math.Ceil(minutes)), 1)
+}
+
+// 标题锚点：保留中文等各种文字 (goldmark 默认会把中文标题都变成 heading、heading-1 ...)
+type headingIDs struct {
+	used map[string]bool
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{used: map[string]bool{}}
+}
+
+func (s *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(string(value))) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(r)
+			dash = false
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			if !dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+				dash = true
+			}
+		}
+	}
+	id := strings.TrimSuffix(sb.String(), "-")
+	if id == "" {
+		id = "heading"
+	}
+	// 重复的标题加序号
+	unique := id
+	for i := 1; s.used[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", id, i)
+	}
+	s.used[unique] = true
+	return []byte(unique)
+}
+
+func (s *headingIDs) Put(value []byte) {
+	s.used[string(value)] = true
+}
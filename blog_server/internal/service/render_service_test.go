@@ -0,0 +1,149 @@
+package service
+
+import (
+	"my-blog/internal/model"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderSanitize(t *testing.T) {
+	useMiniredis(t)
+	s := NewRenderService()
+	tests := []struct {
+		name    string
+		content string
+		want    []string // 输出里必须有
+		reject  []string // 输出里不能有
+	}{
+		{"script tag", "<script>alert(1)</script>hi", []string{"hi"}, []string{"<script", "alert"}},
+		{"event handler", `<img src="x.png" onerror="alert(1)">`, []string{`<img src="x.png">`}, []string{"onerror"}},
+		{"javascript markdown link", "[x](javascript:alert(1))", []string{"<p>x</p>"}, []string{"javascript:", "href"}},
+		{"javascript html link", `<a href="JaVaScRiPt:alert(1)">x</a>`, []string{"x"}, []string{"alert", "href"}},
+		{"style other than text-align", `<p style="color:red;text-align:center">a</p>`, []string{`<p style="text-align: center">a</p>`}, []string{"color"}},
+		{"text-align outside enum", `<p style="text-align:evil">a</p>`, []string{"<p>a</p>"}, []string{"style", "evil"}},
+		{"unknown classes", `<span class="evil-class">x</span><pre class="x">y</pre>`, []string{"<span>x</span>", "<pre>y</pre>"}, []string{"class"}},
+		{
+			"chroma classes kept", "```go\nfunc main() {}\n```",
+			[]string{`<pre class="chroma">`, `<span class="line">`, `<span class="ln">1</span>`, `<span class="kd">func</span>`, `<span class="nf">main</span>`},
+			nil,
+		},
+		{"task list", "- [x] done", []string{`<input checked="" disabled="" type="checkbox">`}, nil},
+		{"external link", "[ext](https://example.com)", []string{`rel="nofollow noopener"`, `target="_blank"`}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := s.Render(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(r.Html, want) {
+					t.Errorf("Render(%q) = %q, missing %q", tt.content, r.Html, want)
+				}
+			}
+			for _, reject := range tt.reject {
+				if strings.Contains(r.Html, reject) {
+					t.Errorf("Render(%q) = %q, must not contain %q", tt.content, r.Html, reject)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderHeadingIds(t *testing.T) {
+	useMiniredis(t)
+	r, err := NewRenderService().Render("# 微服务 架构\n## 设计\n## 设计\n# Hello World!\n# !!!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<h1 id="微服务-架构">`,
+		`<h2 id="设计">`,
+		`<h2 id="设计-1">`, // 重复的标题加序号
+		`<h1 id="hello-world">`,
+		`<h1 id="heading">`, // 没有文字的标题
+	} {
+		if !strings.Contains(r.Html, want) {
+			t.Errorf("Render() = %q, missing %q", r.Html, want)
+		}
+	}
+}
+
+func TestRenderToc(t *testing.T) {
+	useMiniredis(t)
+	r, err := NewRenderService().Render("# A\n## B\n### C\n## D\n# E\n### F **bold**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*model.TocItem{
+		{Level: 1, Id: "a", Text: "A", Children: []*model.TocItem{
+			{Level: 2, Id: "b", Text: "B", Children: []*model.TocItem{{Level: 3, Id: "c", Text: "C"}}},
+			{Level: 2, Id: "d", Text: "D"},
+		}},
+		// 跳过的级别 (h1 下直接是 h3) 挂到最近的更高级标题下面
+		{Level: 1, Id: "e", Text: "E", Children: []*model.TocItem{{Level: 3, Id: "f-bold", Text: "F bold"}}},
+	}
+	if !reflect.DeepEqual(r.Toc, want) {
+		t.Fatalf("Toc = %s, want %s", tocString(r.Toc), tocString(want))
+	}
+}
+
+func TestRenderReadingTime(t *testing.T) {
+	useMiniredis(t)
+	s := NewRenderService()
+	tests := []struct {
+		name        string
+		content     string
+		wantWords   int
+		wantMinutes int
+	}{
+		{"empty", "", 0, 1},
+		{"300 cjk chars", strings.Repeat("字", 300), 300, 1},
+		{"301 cjk chars", strings.Repeat("字", 301), 301, 2},
+		{"400 latin words", strings.Repeat("hello world ", 200), 400, 2},
+		{"mixed", strings.Repeat("字", 150) + " " + strings.Repeat("go ", 100), 250, 1},
+		{"markup not counted", "**粗体** [链接](https://example.com) `code`", 5, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := s.Render(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.WordCount != tt.wantWords || r.ReadingMinutes != tt.wantMinutes {
+				t.Errorf("WordCount = %d, ReadingMinutes = %d, want %d, %d", r.WordCount, r.ReadingMinutes, tt.wantWords, tt.wantMinutes)
+			}
+		})
+	}
+}
+
+func TestRenderCached(t *testing.T) {
+	mr := useMiniredis(t)
+	s := NewRenderService()
+	first, err := s.Render("# 标题\n正文")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mr.Keys()) != 1 || !strings.HasPrefix(mr.Keys()[0], renderCacheKey) {
+		t.Fatalf("cache keys = %v", mr.Keys())
+	}
+	second, err := s.Render("# 标题\n正文")
+	if err != nil || !reflect.DeepEqual(first, second) {
+		t.Fatalf("cached render = %+v, %v, want %+v", second, err, first)
+	}
+}
+
+// --- Helper Functions ---
+
+func tocString(items []*model.TocItem) string {
+	var sb strings.Builder
+	for _, item := range items {
+		sb.WriteString("{" + item.Id + " " + item.Text)
+		if len(item.Children) > 0 {
+			sb.WriteString(" " + tocString(item.Children))
+		}
+		sb.WriteString("}")
+	}
+	return sb.String()
+}
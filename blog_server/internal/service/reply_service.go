@@ -10,9 +10,12 @@ import (
 )
 
 type ReplyService interface {
-	GetReplies(commentId int) ([]model.Reply, error)
+	// [MODIFY] viewerId: 当前用户 (游客为 0)，不返回其拉黑 / 屏蔽的用户的回复
+	GetReplies(commentId, viewerId int) ([]model.Reply, error)
 	AddReply(reply *model.Reply) error
 	LikeReply(userId, replyId int) (string, error)
+	// [NEW] 删除回复，只允许作者本人或管理员
+	DeleteReply(replyId int, op *model.Operator) error
 }
 
 type replyService struct {
@@ -21,6 +24,7 @@ type replyService struct {
 	commentRepo repository.CommentRepository
 	notifyRepo  repository.NotificationRepository
 	articleRepo repository.ArticleRepository
+	blockRepo   repository.BlockRepository // [NEW] 拉黑 / 屏蔽
 }
 
 func NewReplyService(
@@ -29,6 +33,7 @@ func NewReplyService(
 	commentRepo repository.CommentRepository,
 	notifyRepo repository.NotificationRepository,
 	articleRepo repository.ArticleRepository,
+	blockRepo repository.BlockRepository, // [NEW]
 ) ReplyService {
 	return &replyService{
 		repo:        repo,
@@ -36,30 +41,30 @@ func NewReplyService(
 		commentRepo: commentRepo,
 		notifyRepo:  notifyRepo,
 		articleRepo: articleRepo,
+		blockRepo:   blockRepo,
 	}
 }
 
-func (s *replyService) GetReplies(commentId int) ([]model.Reply, error) {
-	replies, err := s.repo.GetRepliesByCommentId(commentId)
+func (s *replyService) GetReplies(commentId, viewerId int) ([]model.Reply, error) {
+	replies, err := s.repo.GetRepliesByCommentId(commentId, viewerId)
 	if err != nil {
 		return nil, err
 	}
 
 	for i := range replies {
-		u1, _ := s.userRepo.FindById(replies[i].UserId)
-		if u1 != nil {
-			u1.Password = ""
-			replies[i].User = u1
+		// [MODIFY] 用户已注销 (user_id=0) 时保留数据库里的作者名
+		u1, err := s.userRepo.FindById(replies[i].UserId)
+		if err == nil {
+			replies[i].User = u1.Public()
 			replies[i].Username = u1.Username
 			if replies[i].Author == "" {
 				replies[i].Author = u1.Username
 			}
 		}
 		if replies[i].ToUid != 0 {
-			u2, _ := s.userRepo.FindById(replies[i].ToUid)
-			if u2 != nil {
-				u2.Password = ""
-				replies[i].TargetUser = u2
+			u2, err := s.userRepo.FindById(replies[i].ToUid)
+			if err == nil {
+				replies[i].TargetUser = u2.Public()
 				replies[i].TargetAuthor = u2.Username
 			}
 		}
@@ -74,6 +79,15 @@ func (s *replyService) AddReply(reply *model.Reply) error {
 		return errors.New("父评论不存在")
 	}
 
+	// [NEW] 被回复的人 (没有指定时是评论作者) 拉黑了自己时不能回复
+	receiverId := reply.ToUid
+	if receiverId == 0 {
+		receiverId = parentComment.UserId
+	}
+	if receiverId != 0 && isBlockedBy(s.blockRepo, receiverId, reply.UserId) {
+		return errors.New("对方已将你拉黑，无法回复")
+	}
+
 	reply.Created = time.Now()
 	reply.Likes = 0
 
@@ -95,11 +109,6 @@ func (s *replyService) AddReply(reply *model.Reply) error {
 	}
 
 	go func() {
-		receiverId := reply.ToUid
-		if receiverId == 0 {
-			receiverId = parentComment.UserId
-		}
-
 		if receiverId != 0 && receiverId != reply.UserId {
 			title := ""
 			article, _ := s.articleRepo.FindById(parentComment.ArticleId)
@@ -118,7 +127,8 @@ func (s *replyService) AddReply(reply *model.Reply) error {
 				Status:     0,
 				Created:    time.Now(),
 			}
-			s.notifyRepo.Create(notify)
+			// [MODIFY] 屏蔽了对方时不通知
+			createNotification(s.blockRepo, s.notifyRepo, notify)
 		}
 	}()
 	return nil
@@ -135,3 +145,18 @@ func (s *replyService) LikeReply(userId, replyId int) (string, error) {
 	s.repo.UpdateReplyLikesCount(replyId, 1)
 	return "点赞成功", nil
 }
+
+// [NEW] 实现 DeleteReply
+func (s *replyService) DeleteReply(replyId int, op *model.Operator) error {
+	reply, err := s.repo.FindById(replyId)
+	if err != nil {
+		return errors.New("回复不存在")
+	}
+	if !op.CanManageComment(reply.UserId) {
+		return errors.New("只能删除自己的回复")
+	}
+	if err := s.repo.DeleteById(replyId); err != nil {
+		return errors.New("删除失败")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+)
+
+type RoleService interface {
+	// 获取所有角色 (附带权限列表)
+	GetAllRoles() ([]*model.Role, error)
+	// 获取用户的角色编码和权限编码 (用于签发 JWT)
+	GetUserAuthorities(userId int) (roles []string, permissions []string, err error)
+	// [NEW] 按角色编码计算权限编码 (会话未完成两步验证时，需要去掉部分角色后重新计算)
+	GetRolePermissions(roleCodes []string) ([]string, error)
+	// 管理员给用户分配角色 (覆盖式)
+	AssignRoles(userId int, roleCodes []string) error
+	// 新用户注册时赋予默认角色 (reader)
+	AssignDefaultRole(userId int) error
+}
+
+type roleService struct {
+	repo     repository.RoleRepository
+	userRepo repository.UserRepository
+}
+
+func NewRoleService(repo repository.RoleRepository, userRepo repository.UserRepository) RoleService {
+	return &roleService{repo: repo, userRepo: userRepo}
+}
+
+func (s *roleService) GetAllRoles() ([]*model.Role, error) {
+	roles, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		perms, _ := s.repo.FindPermissionCodes([]int{role.Id})
+		role.Permissions = perms
+	}
+	return roles, nil
+}
+
+func (s *roleService) GetUserAuthorities(userId int) ([]string, []string, error) {
+	roles, err := s.repo.FindByUserId(userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 历史用户在 t_user_role 中没有记录，按读者处理
+	if len(roles) == 0 {
+		roles, err = s.repo.FindByCodes([]string{model.RoleReader})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var roleCodes []string
+	var roleIds []int
+	for _, role := range roles {
+		roleCodes = append(roleCodes, role.Code)
+		roleIds = append(roleIds, role.Id)
+	}
+
+	perms, err := s.repo.FindPermissionCodes(roleIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	return roleCodes, perms, nil
+}
+
+func (s *roleService) GetRolePermissions(roleCodes []string) ([]string, error) {
+	if len(roleCodes) == 0 {
+		return nil, nil
+	}
+	roles, err := s.repo.FindByCodes(roleCodes)
+	if err != nil {
+		return nil, err
+	}
+	var roleIds []int
+	for _, role := range roles {
+		roleIds = append(roleIds, role.Id)
+	}
+	return s.repo.FindPermissionCodes(roleIds)
+}
+
+func (s *roleService) AssignRoles(userId int, roleCodes []string) error {
+	if _, err := s.userRepo.FindById(userId); err != nil {
+		return errors.New("用户不存在")
+	}
+	if len(roleCodes) == 0 {
+		return errors.New("至少需要分配一个角色")
+	}
+
+	// 去重，防止前端重复勾选
+	seen := make(map[string]bool)
+	var codes []string
+	for _, code := range roleCodes {
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	roles, err := s.repo.FindByCodes(codes)
+	if err != nil {
+		return err
+	}
+	if len(roles) != len(codes) {
+		return errors.New("包含不存在的角色")
+	}
+
+	var roleIds []int
+	for _, role := range roles {
+		roleIds = append(roleIds, role.Id)
+	}
+	return s.repo.SetUserRoles(userId, roleIds)
+}
+
+func (s *roleService) AssignDefaultRole(userId int) error {
+	return s.AssignRoles(userId, []string{model.RoleReader})
+}
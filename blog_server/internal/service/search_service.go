@@ -0,0 +1,316 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"my-blog/pkg/search"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 搜索结果摘要的长度 (字符数)
+const searchSnippetSize = 120
+
+// 推荐标签的个数
+const suggestTagCount = 5
+
+// [NEW] 文章全文搜索 (内存倒排索引，只索引已发布的文章)
+type SearchService interface {
+	// Ready 索引是否已经建好 (启动后第一次重建完成之前为 false)
+	Ready() bool
+	// Search 按相关度排序，返回带高亮标题和摘要的文章
+	// 筛选条件使用 condition 的 CategoryIds、UserId、Tag、CreatedFrom / CreatedTo
+	Search(condition *model.ArticleCondition, page, pageSize int) ([]model.Article, int64, error)
+	// IndexArticle 文章发布、编辑后更新索引 (未发布的文章会从索引中删除)
+	IndexArticle(id int)
+	RemoveArticles(ids ...int)
+	// Rebuild 从数据库重建索引，返回索引的文章数
+	Rebuild() (int, error)
+	// [NEW] 根据标题和正文推荐标签 (提取关键词，已有的标签优先)
+	SuggestTags(title, content string) ([]string, error)
+}
+
+type searchService struct {
+	index       *search.Index
+	articleRepo repository.ArticleRepository
+	tagRepo     repository.TagRepository
+	ready       atomic.Bool
+}
+
+// [MODIFY] 中文按词典分词 (内置词典 + 配置的用户词典，见 config.InitSearchDict)
+func NewSearchService(articleRepo repository.ArticleRepository, tagRepo repository.TagRepository) SearchService {
+	return &searchService{
+		index:       search.NewIndex(search.NewDictTokenizer(search.DefaultDictionary())),
+		articleRepo: articleRepo,
+		tagRepo:     tagRepo,
+	}
+}
+
+func (s *searchService) Ready() bool {
+	return s.ready.Load()
+}
+
+func (s *searchService) Search(condition *model.ArticleCondition, page, pageSize int) ([]model.Article, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	result := s.index.Search(searchQuery(condition))
+
+	// 索引最多 10 分钟重建一次，先用数据库剔除已删除、已撤回的文章，再计算总数和分页
+	hits, err := s.publishedHits(result.Hits)
+	if err != nil {
+		return nil, 0, errors.New("搜索失败")
+	}
+	total := int64(len(hits))
+
+	from := min((page-1)*pageSize, len(hits))
+	to := min(from+pageSize, len(hits))
+	ids := make([]int, 0, to-from)
+	for _, hit := range hits[from:to] {
+		ids = append(ids, hit.Id)
+	}
+	found, err := s.articleRepo.FindPublishedByIds(ids)
+	if err != nil {
+		return nil, 0, errors.New("搜索失败")
+	}
+
+	// 按相关度顺序返回 (两次查询之间被删除的文章跳过)
+	byId := make(map[int]*model.Article, len(found))
+	for i := range found {
+		byId[found[i].Id] = &found[i]
+	}
+	articles := make([]model.Article, 0, len(ids))
+	for _, id := range ids {
+		article, ok := byId[id]
+		if !ok {
+			continue
+		}
+		article.Highlight = &model.ArticleHighlight{
+			Title:   s.index.Highlight(article.Title, result.Terms, 0),
+			Snippet: s.index.Highlight(search.PlainText(article.Content), result.Terms, searchSnippetSize),
+		}
+		articles = append(articles, *article)
+	}
+	return articles, total, nil
+}
+
+func (s *searchService) IndexArticle(id int) {
+	article, err := s.articleRepo.FindById(id)
+	if err != nil || !article.IsPublished() {
+		s.index.Remove(id)
+		return
+	}
+	s.index.Put(searchDocument(article))
+}
+
+func (s *searchService) RemoveArticles(ids ...int) {
+	s.index.Remove(ids...)
+}
+
+func (s *searchService) Rebuild() (int, error) {
+	articles, err := s.articleRepo.FindAll()
+	if err != nil {
+		return 0, err
+	}
+	docs := make([]*search.Document, 0, len(articles))
+	for i := range articles {
+		docs = append(docs, searchDocument(&articles[i]))
+	}
+	s.index.Replace(docs)
+	s.ready.Store(true)
+	return len(docs), nil
+}
+
+func (s *searchService) SuggestTags(title, content string) ([]string, error) {
+	tags, err := s.tagRepo.GetAllTags()
+	if err != nil {
+		return nil, errors.New("获取标签失败")
+	}
+	// 已有的标签：小写 -> 原来的写法 (推荐 "Go" 而不是 "go")
+	existing := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		existing[normalizeTag(tag.Name)] = strings.TrimLeft(strings.TrimSpace(tag.Name), "#")
+	}
+
+	// 标题里的词权重加倍，已经有人用过的标签再加一半
+	weights := map[string]float64{}
+	for _, kw := range s.index.Keywords(title, suggestTagCount*2) {
+		weights[kw.Word] += kw.Weight * 2
+	}
+	for _, kw := range s.index.Keywords(search.PlainText(content), suggestTagCount*4) {
+		weights[kw.Word] += kw.Weight
+	}
+	words := make([]string, 0, len(weights))
+	for word := range weights {
+		if _, ok := existing[word]; ok {
+			weights[word] *= 1.5
+		}
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if weights[words[i]] != weights[words[j]] {
+			return weights[words[i]] > weights[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	if len(words) > suggestTagCount {
+		words = words[:suggestTagCount]
+	}
+	for i, word := range words {
+		if name, ok := existing[word]; ok {
+			words[i] = name
+		}
+	}
+	return words, nil
+}
+
+// [NEW] 启动时建索引，之后每隔 interval 全量重建一次
+// (注销账号、删除分类等批量操作不逐篇更新索引，由重建兜底)
+func StartSearchIndexer(searchService SearchService, interval time.Duration) {
+	go func() {
+		for {
+			n, err := searchService.Rebuild()
+			if err != nil {
+				log.Printf("⚠️ 搜索索引重建失败: %v", err)
+			} else {
+				log.Printf("✅ 搜索索引已重建 (%d 篇文章)", n)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// --- Helper Functions ---
+
+// 只保留数据库中仍然存在且已发布的结果，其余的顺便从索引中删除
+func (s *searchService) publishedHits(hits []search.Hit) ([]search.Hit, error) {
+	ids := make([]int, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.Id)
+	}
+	published, err := s.articleRepo.FindPublishedIds(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(published) == len(hits) {
+		return hits, nil
+	}
+
+	keep := make(map[int]bool, len(published))
+	for _, id := range published {
+		keep[id] = true
+	}
+	kept := make([]search.Hit, 0, len(published))
+	var stale []int
+	for _, hit := range hits {
+		if keep[hit.Id] {
+			kept = append(kept, hit)
+		} else {
+			stale = append(stale, hit.Id)
+		}
+	}
+	s.index.Remove(stale...)
+	return kept, nil
+}
+
+func searchDocument(article *model.Article) *search.Document {
+	return &search.Document{
+		Id:         article.Id,
+		Title:      article.Title,
+		Tags:       splitTags(article.Tags),
+		Content:    search.PlainText(article.Content),
+		CategoryId: article.CategoryId,
+		UserId:     article.UserId,
+		Created:    article.Created,
+	}
+}
+
+// 搜索词：Keyword 搜全部字段；否则 Title、Content 只搜对应字段
+func searchQuery(condition *model.ArticleCondition) *search.Query {
+	q := &search.Query{Text: condition.Keyword}
+	if q.Text == "" {
+		q.Text = strings.TrimSpace(condition.Title + " " + condition.Content)
+		if condition.Title != "" {
+			q.Fields = append(q.Fields, search.FieldTitle)
+		}
+		if condition.Content != "" {
+			q.Fields = append(q.Fields, search.FieldContent)
+		}
+	}
+
+	categories := make(map[int]bool, len(condition.CategoryIds))
+	for _, id := range condition.CategoryIds {
+		categories[id] = true
+	}
+	tag := normalizeTag(condition.Tag)
+	q.Filter = func(doc *search.Document) bool {
+		if len(categories) > 0 && !categories[doc.CategoryId] {
+			return false
+		}
+		if condition.UserId > 0 && doc.UserId != condition.UserId {
+			return false
+		}
+		if tag != "" && !hasTag(doc.Tags, tag) {
+			return false
+		}
+		if condition.CreatedFrom != nil && doc.Created.Before(*condition.CreatedFrom) {
+			return false
+		}
+		if condition.CreatedTo != nil && !doc.Created.Before(*condition.CreatedTo) {
+			return false
+		}
+		return true
+	}
+	return q
+}
+
+// 文章的标签：前端保存为 "#Go #Spring Boot" (每个标签以 # 开头，标签内可以有空格)
+// 老数据没有 # 前缀，以逗号或空格分隔
+var tagStartPattern = regexp.MustCompile(`(?:^|\s)#`)
+
+func splitTags(tags string) []string {
+	tags = strings.TrimSpace(tags)
+	var parts []string
+	switch {
+	case strings.HasPrefix(tags, "#"):
+		for _, part := range tagStartPattern.Split(tags, -1) {
+			parts = append(parts, strings.FieldsFunc(part, isTagComma)...)
+		}
+	case strings.ContainsFunc(tags, isTagComma):
+		parts = strings.FieldsFunc(tags, isTagComma)
+	default:
+		parts = strings.Fields(tags)
+	}
+
+	list := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
+
+func isTagComma(r rune) bool {
+	return r == ',' || r == '，'
+}
+
+// 不区分大小写
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if normalizeTag(t) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// 前端保存的标签带 # 前缀 (如 "#Go")，比较时去掉
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimLeft(strings.TrimSpace(tag), "#"))
+}
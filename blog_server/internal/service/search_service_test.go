@@ -0,0 +1,148 @@
+package service
+
+import (
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSearchSkipsStaleIndexEntries(t *testing.T) {
+	repo := &searchArticleRepo{articles: []model.Article{
+		{Id: 1, Title: "Redis 缓存入门", Status: model.ArticleStatusPublished},
+		{Id: 2, Title: "Redis 缓存进阶", Status: model.ArticleStatusPublished},
+		{Id: 3, Title: "Redis 缓存实战", Status: model.ArticleStatusPublished},
+		{Id: 4, Title: "Redis 缓存总结", Status: model.ArticleStatusPublished},
+	}}
+	s := NewSearchService(repo, nil)
+	if _, err := s.Rebuild(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 索引重建之前，有两篇文章被删除、被撤回 (分散在不同的页上)
+	repo.articles = repo.articles[1:3]
+
+	condition := &model.ArticleCondition{Keyword: "缓存"}
+	var ids []int
+	for page := 1; page <= 2; page++ {
+		articles, total, err := s.Search(condition, page, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 2 || len(articles) != 1 {
+			t.Fatalf("page %d: total = %d, %d articles, want 2, 1", page, total, len(articles))
+		}
+		ids = append(ids, articles[0].Id)
+	}
+	if ids[0] == ids[1] {
+		t.Fatalf("both pages returned article %d", ids[0])
+	}
+	if articles, total, _ := s.Search(condition, 3, 1); total != 2 || len(articles) != 0 {
+		t.Fatalf("page 3: total = %d, %d articles", total, len(articles))
+	}
+
+	// 过期的条目同时从索引中删除
+	repo.articles = append(repo.articles, model.Article{Id: 4, Title: "Redis 缓存总结", Status: model.ArticleStatusPublished})
+	if _, total, _ := s.Search(condition, 1, 10); total != 2 {
+		t.Fatalf("stale entry still in index: total = %d", total)
+	}
+}
+
+func TestSearchTagFilter(t *testing.T) {
+	repo := &searchArticleRepo{articles: []model.Article{
+		{Id: 1, Title: "自动配置", Content: "笔记", Tags: "#Spring Boot #Java", Status: model.ArticleStatusPublished},
+		{Id: 2, Title: "启动流程", Content: "笔记", Tags: "#Spring #Java", Status: model.ArticleStatusPublished},
+		{Id: 3, Title: "老文章", Content: "笔记", Tags: "Spring Boot,Java", Status: model.ArticleStatusPublished},
+		{Id: 4, Title: "入门", Content: "笔记", Tags: "#C# #.NET", Status: model.ArticleStatusPublished},
+	}}
+	s := NewSearchService(repo, nil)
+	if _, err := s.Rebuild(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tag  string
+		want []int
+	}{
+		{"Spring Boot", []int{1, 3}},
+		{"#spring boot", []int{1, 3}},
+		{"Spring", []int{2}},
+		{"Boot", nil},
+		{"Java", []int{1, 2, 3}},
+		{"C#", []int{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			articles, total, err := s.Search(&model.ArticleCondition{Keyword: "笔记", Tag: tt.tag}, 1, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, article := range articles {
+				got = append(got, article.Id)
+			}
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, tt.want) || int(total) != len(tt.want) {
+				t.Errorf("Search(tag %q) = %v (total %d), want %v", tt.tag, got, total, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitTags(t *testing.T) {
+	tests := []struct {
+		tags string
+		want []string
+	}{
+		{"#Go #Spring Boot", []string{"Go", "Spring Boot"}},
+		{"  #C# #.NET ", []string{"C#", ".NET"}},
+		{"#Go,#Redis", []string{"Go", "#Redis"}},
+		{"Java,Spring Boot，Docker", []string{"Java", "Spring Boot", "Docker"}},
+		{"Go Redis", []string{"Go", "Redis"}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tags, func(t *testing.T) {
+			if got := splitTags(tt.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+// --- Helper Functions ---
+
+// searchArticleRepo articles 为数据库中的文章 (测试中直接修改来模拟删除)
+type searchArticleRepo struct {
+	repository.ArticleRepository
+	articles []model.Article
+}
+
+func (r *searchArticleRepo) FindAll() ([]model.Article, error) {
+	return append([]model.Article(nil), r.articles...), nil
+}
+
+func (r *searchArticleRepo) FindPublishedIds(ids []int) ([]int, error) {
+	var found []int
+	for _, article := range r.published(ids) {
+		found = append(found, article.Id)
+	}
+	return found, nil
+}
+
+func (r *searchArticleRepo) FindPublishedByIds(ids []int) ([]model.Article, error) {
+	return r.published(ids), nil
+}
+
+func (r *searchArticleRepo) published(ids []int) []model.Article {
+	var found []model.Article
+	for _, article := range r.articles {
+		for _, id := range ids {
+			if article.Id == id && article.IsPublished() {
+				found = append(found, article)
+			}
+		}
+	}
+	return found
+}
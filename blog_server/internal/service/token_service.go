@@ -0,0 +1,378 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"my-blog/config"
+	"my-blog/internal/model"
+	"my-blog/internal/repository"
+	"my-blog/pkg/utils"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	errSessionInvalid = errors.New("登录已失效，请重新登录")
+	errBanned         = errors.New("账号已被封禁，请联系管理员")
+)
+
+// Redis Key 前缀
+const (
+	sessionKey          = "session:"            // + sid -> Hash (会话/设备信息)
+	userSessionSetKey   = "user_sessions:"      // + userId -> Set<sid>
+	refreshTokenKey     = "refresh_token:"      // + sha256(refreshToken) -> sid
+	refreshTokenUsedKey = "refresh_token_used:" // + sha256(refreshToken) -> userId (已轮换，用于重放检测)
+	userBannedKey       = "user_banned:"        // + userId -> 1 (已封禁，中间件直接拒绝)
+)
+
+// TokenPair 登录/刷新后返回给前端的一对 Token
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int64  `json:"expiresIn"` // Access Token 剩余秒数
+	// [NEW] 角色要求两步验证但尚未开启：本次登录暂不授予该角色，前端应引导用户去开启
+	MfaSetupRequired bool `json:"mfaSetupRequired,omitempty"`
+}
+
+type TokenService interface {
+	// 新建一个登录会话并签发 Access Token + Refresh Token，同时把角色信息填充到 user 上
+	// [MODIFY] mfaVerified 表示本次登录是否通过了两步验证 (记录在会话上，刷新时沿用)
+	IssueTokens(user *model.User, client *model.ClientInfo, mfaVerified bool) (*TokenPair, error)
+	// [NEW] 当前会话刚完成两步验证绑定：标记为已验证并重新签发 Token (恢复被暂扣的角色)
+	UpgradeSession(user *model.User, sessionId string) (*TokenPair, error)
+	// 用 Refresh Token 换一对新 Token (旧的 Refresh Token 立即作废，会话不变)
+	Refresh(refreshToken string, client *model.ClientInfo) (*TokenPair, error)
+	// 退出当前登录：删除当前会话，其 Access Token 和 Refresh Token 随之失效
+	Logout(claims jwt.MapClaims) error
+	// [NEW] 踢掉某个设备
+	RevokeSession(userId int, sessionId string) error
+	// 退出所有设备：该用户所有会话立即失效
+	RevokeAll(userId int) error
+	// [NEW] 凭证作废 (修改 / 重置密码、退出所有设备)：吊销全部会话，并删除全部个人访问令牌
+	RevokeAllCredentials(userId int) error
+	// [NEW] 中间件调用：校验 Token 所属会话仍然有效，并刷新最后活跃时间
+	// [MODIFY] 返回具体原因 (会话失效 / 账号已封禁)
+	ValidateSession(claims jwt.MapClaims, ip string) error
+	// [NEW] 封禁 / 解封：封禁时吊销全部会话，并让已签发的 Access Token 立即失效
+	SetBanned(userId int, banned bool) error
+	// [NEW] 获取登录设备列表 (最近活跃的在前)
+	ListSessions(userId int, currentSessionId string) ([]*model.Session, error)
+}
+
+type tokenService struct {
+	userRepo        repository.UserRepository
+	roleService     RoleService
+	accessTokenRepo repository.AccessTokenRepository
+}
+
+func NewTokenService(
+	userRepo repository.UserRepository,
+	roleService RoleService,
+	accessTokenRepo repository.AccessTokenRepository,
+) TokenService {
+	return &tokenService{userRepo: userRepo, roleService: roleService, accessTokenRepo: accessTokenRepo}
+}
+
+func (s *tokenService) accessTTL() time.Duration {
+	if config.Config.Jwt.AccessTTLMinutes > 0 {
+		return time.Duration(config.Config.Jwt.AccessTTLMinutes) * time.Minute
+	}
+	return 30 * time.Minute
+}
+
+func (s *tokenService) refreshTTL() time.Duration {
+	if config.Config.Jwt.RefreshTTLHours > 0 {
+		return time.Duration(config.Config.Jwt.RefreshTTLHours) * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
+
+func (s *tokenService) IssueTokens(user *model.User, client *model.ClientInfo, mfaVerified bool) (*TokenPair, error) {
+	// [NEW] 所有登录方式最终都走这里，统一拦截已封禁的账号
+	if user.Valid != model.UserValid {
+		return nil, errBanned
+	}
+	// 1. 记录会话 (设备、IP、时间)
+	sid := uuid.New().String()
+	now := time.Now().Unix()
+	uid := strconv.Itoa(user.Id)
+	err := config.RDB.HSet(config.Ctx, sessionKey+sid, map[string]interface{}{
+		"userId":    user.Id,
+		"device":    utils.ParseDevice(client.UserAgent),
+		"userAgent": client.UserAgent,
+		"ip":        client.Ip,
+		"created":   now,
+		"lastSeen":  now,
+		"mfa":       mfaVerified,
+	}).Err()
+	if err != nil {
+		return nil, errors.New("会话存储失败")
+	}
+	config.RDB.SAdd(config.Ctx, userSessionSetKey+uid, sid)
+
+	// 2. 签发 Token
+	return s.issueForSession(user, sid)
+}
+
+func (s *tokenService) Refresh(refreshToken string, client *model.ClientInfo) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, errors.New("刷新令牌不能为空")
+	}
+	hash := hashToken(refreshToken)
+
+	// 1. 取出并删除 (轮换：每个 Refresh Token 只能用一次)
+	sid, err := config.RDB.GetDel(config.Ctx, refreshTokenKey+hash).Result()
+	if err != nil || sid == "" {
+		// 已经用过的 Refresh Token 又被提交，说明可能被盗用，直接吊销该用户的全部会话
+		if usedUid, err := config.RDB.Get(config.Ctx, refreshTokenUsedKey+hash).Result(); err == nil {
+			if userId, err := strconv.Atoi(usedUid); err == nil {
+				s.RevokeAll(userId)
+			}
+		}
+		return nil, errors.New("刷新令牌无效或已过期，请重新登录")
+	}
+
+	// 2. 会话必须还在 (可能已在其他设备上被踢掉)
+	userId, err := config.RDB.HGet(config.Ctx, sessionKey+sid, "userId").Int()
+	if err != nil {
+		return nil, errors.New("登录已失效，请重新登录")
+	}
+	config.RDB.Set(config.Ctx, refreshTokenUsedKey+hash, userId, s.refreshTTL())
+	config.RDB.HSet(config.Ctx, sessionKey+sid,
+		"ip", client.Ip,
+		"userAgent", client.UserAgent,
+		"device", utils.ParseDevice(client.UserAgent),
+		"lastSeen", time.Now().Unix())
+
+	// 3. 重新查用户 (角色可能已变更)
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	// [NEW] 已被封禁
+	if user.Valid != model.UserValid {
+		s.RevokeAll(userId)
+		return nil, errBanned
+	}
+
+	return s.issueForSession(user, sid)
+}
+
+func (s *tokenService) UpgradeSession(user *model.User, sessionId string) (*TokenPair, error) {
+	data, err := config.RDB.HGetAll(config.Ctx, sessionKey+sessionId).Result()
+	if err != nil || data["userId"] != strconv.Itoa(user.Id) {
+		return nil, errors.New("登录已失效，请重新登录")
+	}
+	// 旧 Refresh Token 作废，换一对新的
+	if hash := data["refreshHash"]; hash != "" {
+		config.RDB.Del(config.Ctx, refreshTokenKey+hash)
+	}
+	config.RDB.HSet(config.Ctx, sessionKey+sessionId, "mfa", true)
+	return s.issueForSession(user, sessionId)
+}
+
+func (s *tokenService) Logout(claims jwt.MapClaims) error {
+	sid, _ := claims["sid"].(string)
+	if sid == "" {
+		return nil
+	}
+	s.deleteSession(sid)
+	return nil
+}
+
+func (s *tokenService) RevokeSession(userId int, sessionId string) error {
+	owner, err := config.RDB.HGet(config.Ctx, sessionKey+sessionId, "userId").Int()
+	if err != nil || owner != userId {
+		return errors.New("会话不存在或已失效")
+	}
+	s.deleteSession(sessionId)
+	return nil
+}
+
+func (s *tokenService) RevokeAll(userId int) error {
+	uid := strconv.Itoa(userId)
+	sids, err := config.RDB.SMembers(config.Ctx, userSessionSetKey+uid).Result()
+	if err != nil {
+		return err
+	}
+	for _, sid := range sids {
+		s.deleteSession(sid)
+	}
+	return config.RDB.Del(config.Ctx, userSessionSetKey+uid).Err()
+}
+
+func (s *tokenService) RevokeAllCredentials(userId int) error {
+	if err := s.RevokeAll(userId); err != nil {
+		return err
+	}
+	// 个人访问令牌不依赖会话，需要单独删除 (否则泄露的令牌在改密码后仍然可用)
+	return s.accessTokenRepo.DeleteByUserId(userId)
+}
+
+func (s *tokenService) ValidateSession(claims jwt.MapClaims, ip string) error {
+	sid, _ := claims["sid"].(string)
+	if sid == "" {
+		return errSessionInvalid
+	}
+	userIdFloat, _ := claims["userId"].(float64)
+
+	owner, err := config.RDB.HGet(config.Ctx, sessionKey+sid, "userId").Int()
+	if err != nil || owner != int(userIdFloat) {
+		return errSessionInvalid
+	}
+	if config.RDB.Exists(config.Ctx, userBannedKey+strconv.Itoa(owner)).Val() > 0 {
+		return errBanned
+	}
+
+	config.RDB.HSet(config.Ctx, sessionKey+sid, "lastSeen", time.Now().Unix(), "ip", ip)
+	return nil
+}
+
+func (s *tokenService) SetBanned(userId int, banned bool) error {
+	key := userBannedKey + strconv.Itoa(userId)
+	if !banned {
+		return config.RDB.Del(config.Ctx, key).Err()
+	}
+	if err := config.RDB.Set(config.Ctx, key, 1, 0).Err(); err != nil {
+		return err
+	}
+	return s.RevokeAll(userId)
+}
+
+func (s *tokenService) ListSessions(userId int, currentSessionId string) ([]*model.Session, error) {
+	uid := strconv.Itoa(userId)
+	sids, err := config.RDB.SMembers(config.Ctx, userSessionSetKey+uid).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*model.Session{}
+	for _, sid := range sids {
+		data, err := config.RDB.HGetAll(config.Ctx, sessionKey+sid).Result()
+		if err != nil || len(data) == 0 {
+			// 会话已过期，顺手清理集合
+			config.RDB.SRem(config.Ctx, userSessionSetKey+uid, sid)
+			continue
+		}
+		created, _ := strconv.ParseInt(data["created"], 10, 64)
+		lastSeen, _ := strconv.ParseInt(data["lastSeen"], 10, 64)
+		list = append(list, &model.Session{
+			Id:        sid,
+			UserId:    userId,
+			Device:    data["device"],
+			UserAgent: data["userAgent"],
+			Ip:        data["ip"],
+			Created:   time.Unix(created, 0),
+			LastSeen:  time.Unix(lastSeen, 0),
+			Current:   sid == currentSessionId,
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastSeen.After(list[j].LastSeen)
+	})
+	return list, nil
+}
+
+// --- Helper Functions ---
+
+// 为已存在的会话签发一对新 Token
+func (s *tokenService) issueForSession(user *model.User, sid string) (*TokenPair, error) {
+	// 1. 查询真实角色与权限
+	roles, perms, err := s.roleService.GetUserAuthorities(user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// [NEW] 要求两步验证的角色 (如 admin)，会话未通过两步验证时不授予
+	mfaSetupRequired := false
+	if mfaRequiredFor(roles) {
+		mfaVerified, _ := config.RDB.HGet(config.Ctx, sessionKey+sid, "mfa").Bool()
+		if !mfaVerified {
+			mfaSetupRequired = true
+			roles, perms, err = s.withoutMfaRoles(roles)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	FillAuthorities(user, roles)
+
+	// 2. Access Token (短期，载荷带 sid)
+	accessToken, err := utils.GenerateToken(user.Id, user.Username, sid, roles, perms, s.accessTTL())
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Refresh Token (随机串，Redis 中只存哈希)
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashToken(refreshToken)
+	if err := config.RDB.Set(config.Ctx, refreshTokenKey+hash, sid, s.refreshTTL()).Err(); err != nil {
+		return nil, errors.New("Token 存储失败")
+	}
+
+	// 4. 会话记住当前 Refresh Token，踢设备时一并删除；会话有效期随刷新顺延
+	uid := strconv.Itoa(user.Id)
+	config.RDB.HSet(config.Ctx, sessionKey+sid, "refreshHash", hash)
+	config.RDB.Expire(config.Ctx, sessionKey+sid, s.refreshTTL())
+	config.RDB.Expire(config.Ctx, userSessionSetKey+uid, s.refreshTTL())
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int64(s.accessTTL().Seconds()),
+		MfaSetupRequired: mfaSetupRequired,
+	}, nil
+}
+
+// 去掉要求两步验证的角色并重新计算权限 (一个不剩时按读者处理)
+func (s *tokenService) withoutMfaRoles(roles []string) ([]string, []string, error) {
+	var kept []string
+	for _, role := range roles {
+		if !mfaRequiredFor([]string{role}) {
+			kept = append(kept, role)
+		}
+	}
+	if len(kept) == 0 {
+		kept = []string{model.RoleReader}
+	}
+	perms, err := s.roleService.GetRolePermissions(kept)
+	return kept, perms, err
+}
+
+// 删除会话及其 Refresh Token
+func (s *tokenService) deleteSession(sid string) {
+	data, _ := config.RDB.HGetAll(config.Ctx, sessionKey+sid).Result()
+	if hash := data["refreshHash"]; hash != "" {
+		config.RDB.Del(config.Ctx, refreshTokenKey+hash)
+	}
+	if uid := data["userId"]; uid != "" {
+		config.RDB.SRem(config.Ctx, userSessionSetKey+uid, sid)
+	}
+	config.RDB.Del(config.Ctx, sessionKey+sid)
+}
+
+// 生成 32 字节随机串 (URL 安全)
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Redis 中只保存 Token 的 SHA-256，防止 Redis 泄露后 Token 被直接使用
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
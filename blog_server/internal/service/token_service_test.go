@@ -0,0 +1,136 @@
+package service
+
+import (
+	"my-blog/internal/model"
+	"my-blog/pkg/utils"
+	"strings"
+	"testing"
+)
+
+func TestRefreshRotation(t *testing.T) {
+	useMiniredis(t)
+	s, user := newTokenTestService(t)
+
+	first, err := s.IssueTokens(user, testClient, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Refresh(first.RefreshToken, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token not rotated")
+	}
+	// 轮换后会话不变，新的 Access Token 可用
+	if err := s.ValidateSession(parseClaims(t, second.AccessToken), testClient.Ip); err != nil {
+		t.Fatalf("rotated access token rejected: %v", err)
+	}
+	if _, err := s.Refresh("", testClient); err == nil {
+		t.Fatal("empty refresh token accepted")
+	}
+	if _, err := s.Refresh("not-issued", testClient); err == nil {
+		t.Fatal("unknown refresh token accepted")
+	}
+}
+
+func TestRefreshTokenReuseRevokesSessions(t *testing.T) {
+	useMiniredis(t)
+	s, user := newTokenTestService(t)
+
+	stolen, err := s.IssueTokens(user, testClient, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.IssueTokens(user, &model.ClientInfo{Ip: "10.0.0.2", UserAgent: "curl/8.0"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := s.Refresh(stolen.RefreshToken, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 已经轮换掉的 Refresh Token 被再次提交
+	if _, err := s.Refresh(stolen.RefreshToken, testClient); err == nil {
+		t.Fatal("reused refresh token accepted")
+	}
+
+	// 该用户的所有会话 (包括其他设备) 立即失效
+	for name, pair := range map[string]*TokenPair{"rotated": rotated, "other device": other} {
+		if err := s.ValidateSession(parseClaims(t, pair.AccessToken), testClient.Ip); err == nil {
+			t.Errorf("%s session still valid after reuse", name)
+		}
+		if _, err := s.Refresh(pair.RefreshToken, testClient); err == nil {
+			t.Errorf("%s refresh token still valid after reuse", name)
+		}
+	}
+	if sessions, _ := s.ListSessions(user.Id, ""); len(sessions) != 0 {
+		t.Fatalf("sessions = %d, want 0", len(sessions))
+	}
+}
+
+func TestLogoutRevokesRefreshToken(t *testing.T) {
+	useMiniredis(t)
+	s, user := newTokenTestService(t)
+
+	pair, err := s.IssueTokens(user, testClient, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := parseClaims(t, pair.AccessToken)
+	if err := s.Logout(claims); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ValidateSession(claims, testClient.Ip); err == nil {
+		t.Fatal("access token valid after logout")
+	}
+	if _, err := s.Refresh(pair.RefreshToken, testClient); err == nil {
+		t.Fatal("refresh token valid after logout")
+	}
+}
+
+func TestBannedUserCannotRefresh(t *testing.T) {
+	useMiniredis(t)
+	s, user := newTokenTestService(t)
+
+	pair, err := s.IssueTokens(user, testClient, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetBanned(user.Id, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ValidateSession(parseClaims(t, pair.AccessToken), testClient.Ip); err == nil {
+		t.Fatal("banned user's access token still valid")
+	}
+	if _, err := s.Refresh(pair.RefreshToken, testClient); err == nil {
+		t.Fatal("banned user refreshed a token")
+	}
+}
+
+// --- Helper Functions ---
+
+var testClient = &model.ClientInfo{Ip: "10.0.0.1", UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0"}
+
+func newTokenTestService(t *testing.T) (TokenService, *model.User) {
+	t.Helper()
+	key, err := utils.NewHMACKey("test", []byte(strings.Repeat("k", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.SetJwtKeys([]*utils.JwtKey{key}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{Id: 1, Username: "alice", Valid: model.UserValid}
+	return NewTokenService(newMemUserRepo(user), &stubRoleService{}, nil), user
+}
+
+func parseClaims(t *testing.T, accessToken string) map[string]any {
+	t.Helper()
+	claims, err := utils.ParseToken(accessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
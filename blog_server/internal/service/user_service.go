@@ -2,10 +2,15 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"html"
+	"log"
 	"my-blog/config"
 	"my-blog/internal/model"
 	"my-blog/internal/repository"
-	"my-blog/pkg/utils"
+	"net/mail"
+	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -13,12 +18,25 @@ import (
 )
 
 type UserService interface {
-	GetAllUsers() ([]model.User, error)
 	GetUserDetail(id int) (*model.User, error)
 	// [NEW] 注册与登录
 	// ✅ 修正：必须与你的实现保持一致，增加 string 返回值
 	Register(user *model.User, code string) (string, error)
-	Login(username, password string) (*model.User, string, error)
+	// [MODIFY] 登录成功返回 Access Token + Refresh Token
+	// [MODIFY] 记录登录设备 (client)；失败次数过多时需要图形验证码
+	// [MODIFY] 开启了两步验证时不直接签发 Token，而是返回 MfaToken 等待第二步
+	Login(username, password, captcha, captchaKey string, client *model.ClientInfo) (*LoginResult, error)
+	// [NEW] 登录第二步：提交 TOTP 验证码或恢复码
+	LoginMfa(mfaToken, code string, client *model.ClientInfo) (*LoginResult, error)
+	// [NEW] 通行密钥 (Passkey) 登录，免密码
+	LoginPasskey(loginKey string, response []byte, client *model.ClientInfo) (*LoginResult, error)
+	// [NEW] 第三方登录：回调后前端用一次性 ticket 换 Token
+	LoginOAuth(ticket string, client *model.ClientInfo) (*LoginResult, error)
+	// [NEW] 邮件登录链接：发送一次性链接；点击后用链接中的 token 换 Token
+	SendMagicLink(email, captcha, captchaKey, ip string) error
+	LoginMagicLink(token string, client *model.ClientInfo) (*LoginResult, error)
+	// [NEW] 登录是否需要图形验证码 (失败次数达到阈值)
+	NeedCaptcha(username, ip string) bool
 	// [MODIFY] 增加参数：图形验证码、Key、业务类型、用户名
 	SendEmailCode(email, captcha, captchaKey, bizType, username string) error
 	// [NEW] 根据用户名查询
@@ -26,30 +44,65 @@ type UserService interface {
 	// [NEW] 重置密码接口
 	ResetPassword(username, email, password, code string) (string, error)
 	// [NEW] 修改用户信息
+	// [MODIFY] 不再允许直接修改邮箱，需走 SendChangeEmailCode + ChangeEmail
 	UpdateUser(user *model.User) (*model.User, error)
+	// [NEW] 修改邮箱：先向新邮箱发送验证码，确认后才真正修改，并通知旧邮箱
+	SendChangeEmailCode(userId int, newEmail string) error
+	ChangeEmail(userId int, newEmail, code string) (*model.User, error)
 	// [NEW] 修改密码
 	UpdatePassword(userId int, oldPwd, newPwd string) error
 }
 
+// [NEW] LoginResult 登录结果：要么直接拿到 Token，要么还需要两步验证
+type LoginResult struct {
+	User     *model.User
+	Tokens   *TokenPair
+	MfaToken string // 非空表示需要提交两步验证码 (5分钟内有效)
+}
+
 type userService struct {
 	userRepo    repository.UserRepository
 	mailService MailService // [NEW] 注入邮件服务
+	roleService RoleService // [NEW] 注入角色服务 (注册时分配默认角色)
+	// [NEW] 注入 Token 服务 (签发、吊销)
+	tokenService TokenService
+	// [NEW] 登录防爆破
+	loginGuard LoginGuardService
+	// [NEW] 两步验证
+	mfaService MfaService
+	// [NEW] 通行密钥
+	passkeyService PasskeyService
+	// [NEW] 第三方登录
+	oauthService OAuthService
+	// [NEW] 密码策略
+	passwordService PasswordService
 }
 
 func NewUserService(
 	userRepo repository.UserRepository,
-	mailService MailService) UserService {
+	mailService MailService,
+	roleService RoleService,
+	tokenService TokenService,
+	loginGuard LoginGuardService,
+	mfaService MfaService,
+	passkeyService PasskeyService,
+	oauthService OAuthService,
+	passwordService PasswordService) UserService {
 	return &userService{
-		userRepo:    userRepo,
-		mailService: mailService,
+		userRepo:     userRepo,
+		mailService:  mailService,
+		roleService:  roleService,
+		tokenService: tokenService,
+		loginGuard:   loginGuard,
+		mfaService:   mfaService,
+		// [NEW]
+		passkeyService: passkeyService,
+		oauthService:   oauthService,
+		// [NEW]
+		passwordService: passwordService,
 	}
 }
 
-// GetAllUsers 获取所有用户
-func (s *userService) GetAllUsers() ([]model.User, error) {
-	return s.userRepo.FindAll()
-}
-
 // GetUserDetail 获取用户详情
 func (s *userService) GetUserDetail(id int) (*model.User, error) {
 	if id <= 0 {
@@ -124,6 +177,80 @@ func (s *userService) SendEmailCode(email, captcha, captchaKey, bizType, usernam
 	return nil
 }
 
+// Redis Key 前缀 (邮件登录链接)
+const (
+	magicLinkKey       = "magic_link:"        // + sha256(token) -> Hash{userId, email}
+	magicLinkLatestKey = "magic_link_latest:" // + email -> 最新一封链接的 hash (新链接发出后旧链接作废)
+	magicLinkLimitKey  = "magic_link_limit:"  // + email -> 1分钟内不能重复发送
+	magicLinkCountKey  = "magic_link_count:"  // + email / ip:xxx -> 1小时内发送次数
+)
+
+// [NEW] 实现 SendMagicLink (复用图形验证码 + Redis 频率限制)
+func (s *userService) SendMagicLink(email, captcha, captchaKey, ip string) error {
+	// 1. 人机验证 (图形验证码)
+	if err := verifyCaptcha(captcha, captchaKey); err != nil {
+		return err
+	}
+
+	// 2. 频率限制：同一邮箱 1 分钟 1 次、1 小时 5 次；同一 IP 1 小时 20 次
+	if config.RDB.Exists(config.Ctx, magicLinkLimitKey+email).Val() > 0 {
+		return errors.New("登录链接已发送，请勿频繁操作")
+	}
+	if !withinHourlyLimit(magicLinkCountKey+email, 5) || !withinHourlyLimit(magicLinkCountKey+"ip:"+ip, 20) {
+		return errors.New("发送次数过多，请稍后再试")
+	}
+	config.RDB.Set(config.Ctx, magicLinkLimitKey+email, 1, time.Minute)
+
+	// 3. 邮箱未注册时同样返回成功，防止被用来探测邮箱是否注册
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil || user == nil {
+		return nil
+	}
+
+	// 4. 生成一次性 token (Redis 只存哈希，绑定用户和邮箱)
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	ttl := 15 * time.Minute
+	if config.Config.Security.MagicLinkTTLMinutes > 0 {
+		ttl = time.Duration(config.Config.Security.MagicLinkTTLMinutes) * time.Minute
+	}
+	hash := hashToken(token)
+	if old, err := config.RDB.Get(config.Ctx, magicLinkLatestKey+email).Result(); err == nil {
+		config.RDB.Del(config.Ctx, magicLinkKey+old)
+	}
+	config.RDB.HSet(config.Ctx, magicLinkKey+hash, "userId", user.Id, "email", email)
+	config.RDB.Expire(config.Ctx, magicLinkKey+hash, ttl)
+	config.RDB.Set(config.Ctx, magicLinkLatestKey+email, hash, ttl)
+
+	// 5. 发送邮件
+	link := config.Config.Security.MagicLinkUrl
+	if link == "" {
+		link = "http://localhost:5173/magic-login"
+	}
+	link += "?token=" + token
+	err = s.mailService.SendMail(email,
+		"【你的博客名】登录链接",
+		fmt.Sprintf("您好 <b>%s</b>，点击下面的链接即可登录 (%d 分钟内有效，只能使用一次)：<br>"+
+			"<a href=\"%s\">%s</a><br>如果不是您本人操作，请忽略本邮件。",
+			html.EscapeString(user.Username), int(ttl.Minutes()), link, link))
+	if err != nil {
+		config.RDB.Del(config.Ctx, magicLinkKey+hash, magicLinkLatestKey+email)
+		return errors.New("邮件发送失败，请检查邮箱是否正确")
+	}
+	return nil
+}
+
+// 1 小时计数窗口 (第一次计数时设置过期)，未超过 limit 返回 true
+func withinHourlyLimit(key string, limit int64) bool {
+	count := config.RDB.Incr(config.Ctx, key).Val()
+	if count == 1 {
+		config.RDB.Expire(config.Ctx, key, time.Hour)
+	}
+	return count <= limit
+}
+
 // [NEW] 实现 Register (注册)
 // [MODIFY] 注册逻辑
 func (s *userService) Register(user *model.User, code string) (string, error) {
@@ -143,9 +270,15 @@ func (s *userService) Register(user *model.User, code string) (string, error) {
 		return "", errors.New("用户名已存在")
 	}
 
-	// 3. 密码加密
-	hashedPwd, _ := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	user.Password = string(hashedPwd)
+	// 3. [MODIFY] 校验密码强度后加密
+	if err := s.passwordService.Check(0, "", user.Password); err != nil {
+		return "", err
+	}
+	hashedPwd, err := s.passwordService.Hash(user.Password)
+	if err != nil {
+		return "", err
+	}
+	user.Password = hashedPwd
 
 	user.Created = time.Now()
 	user.Valid = 1
@@ -157,33 +290,145 @@ func (s *userService) Register(user *model.User, code string) (string, error) {
 		return "", errors.New("注册失败")
 	}
 
-	// 5. 删除验证码
+	// 5. [NEW] 赋予默认角色 (reader)
+	if err := s.roleService.AssignDefaultRole(user.Id); err != nil {
+		return "", errors.New("注册失败: 分配角色出错")
+	}
+
+	// 6. 删除验证码
 	config.RDB.Del(config.Ctx, key)
+	s.passwordService.Remember(user.Id, hashedPwd) // [NEW]
 
 	return "注册成功", nil
 }
 
 // [NEW] 实现 Login (登录)
-func (s *userService) Login(username, password string) (*model.User, string, error) {
+func (s *userService) Login(username, password, captcha, captchaKey string, client *model.ClientInfo) (*LoginResult, error) {
+	// 0. [NEW] 防爆破：账号锁定 / 图形验证码
+	if err := s.loginGuard.Check(username, client.Ip, captcha, captchaKey); err != nil {
+		return nil, err
+	}
+
 	// 1. 查询用户
 	user, err := s.userRepo.FindByUsername(username)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, "", errors.New("用户名不存在")
+			s.loginGuard.RecordFailure(username, client.Ip, nil)
+			return nil, errors.New("用户名不存在")
 		}
-		return nil, "", err
+		return nil, err
 	}
 
 	// 2. 校验密码 (对比 Hash)
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return nil, "", errors.New("密码错误")
+		s.loginGuard.RecordFailure(username, client.Ip, user)
+		return nil, errors.New("密码错误")
+	}
+
+	// 3. [MODIFY] 签发 Token (开启了两步验证的先走第二步)
+	result, err := s.completeLogin(user, client, false)
+	if err != nil {
+		return nil, err
+	}
+	// 需要两步验证时先不清空失败计数，验证码通过后再清空 (见 MfaService.CompletePendingLogin)
+	if result.MfaToken == "" {
+		s.loginGuard.RecordSuccess(username)
+	}
+	return result, nil
+}
+
+// [NEW] 实现 LoginMfa (登录第二步)
+func (s *userService) LoginMfa(mfaToken, code string, client *model.ClientInfo) (*LoginResult, error) {
+	if mfaToken == "" || code == "" {
+		return nil, errors.New("验证码不能为空")
+	}
+	user, err := s.mfaService.CompletePendingLogin(mfaToken, code, client.Ip)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(user, client, true)
+}
+
+// [NEW] 实现 LoginPasskey
+func (s *userService) LoginPasskey(loginKey string, response []byte, client *model.ClientInfo) (*LoginResult, error) {
+	if loginKey == "" || len(response) == 0 {
+		return nil, errors.New("参数错误")
+	}
+	user, userVerified, err := s.passkeyService.FinishLogin(loginKey, response)
+	if err != nil {
+		return nil, err
+	}
+	// 认证器做了用户验证 (指纹/PIN) 时，通行密钥本身就是多因素，不再要求 TOTP
+	return s.completeLogin(user, client, userVerified)
+}
+
+// [NEW] 实现 LoginOAuth
+func (s *userService) LoginOAuth(ticket string, client *model.ClientInfo) (*LoginResult, error) {
+	if ticket == "" {
+		return nil, errors.New("参数错误")
+	}
+	user, err := s.oauthService.ConsumeTicket(ticket)
+	if err != nil {
+		return nil, err
+	}
+	// 第三方是否做了多因素验证无法得知，开启了两步验证的账号仍需输入验证码
+	return s.completeLogin(user, client, false)
+}
+
+// [NEW] 实现 LoginMagicLink
+func (s *userService) LoginMagicLink(token string, client *model.ClientInfo) (*LoginResult, error) {
+	if token == "" {
+		return nil, errors.New("参数错误")
+	}
+	key := magicLinkKey + hashToken(token)
+	data, err := config.RDB.HGetAll(config.Ctx, key).Result()
+	// Del 返回 1 才算抢到 (同一链接被并发点击时只有一次生效)
+	if err != nil || len(data) == 0 || config.RDB.Del(config.Ctx, key).Val() != 1 {
+		return nil, errors.New("登录链接已失效，请重新获取")
+	}
+	config.RDB.Del(config.Ctx, magicLinkLatestKey+data["email"])
+
+	userId, _ := strconv.Atoi(data["userId"])
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	// 链接只对申请时的邮箱有效 (期间换绑了邮箱则作废)
+	if user.Email != data["email"] {
+		return nil, errors.New("登录链接已失效，请重新获取")
+	}
+	// 能收到邮件只证明拥有邮箱，开启了两步验证的账号仍需输入验证码
+	return s.completeLogin(user, client, false)
+}
+
+// [NEW] 第一因素通过后的统一收尾：
+// 已完成多因素验证则直接签发 Token；否则开启了两步验证的账号先发临时 mfaToken，验证码通过后再签发
+func (s *userService) completeLogin(user *model.User, client *model.ClientInfo, mfaVerified bool) (*LoginResult, error) {
+	// [NEW] 已封禁的账号 (第一因素通过后才提示，避免被用来探测账号状态)
+	if user.Valid != model.UserValid {
+		return nil, errBanned
+	}
+	if !mfaVerified && user.TotpEnabled == 1 {
+		mfaToken, err := s.mfaService.CreatePendingLogin(user.Id)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{MfaToken: mfaToken}, nil
 	}
 
-	// 3. 生成 Token
-	token, _ := utils.GenerateToken(user.Id, user.Username)
+	// 新建会话并签发 Token (角色和权限写入载荷)
+	pair, err := s.tokenService.IssueTokens(user, client, mfaVerified)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{User: user, Tokens: pair}, nil
+}
 
-	return user, token, nil
+// [NEW] 实现 NeedCaptcha
+func (s *userService) NeedCaptcha(username, ip string) bool {
+	return s.loginGuard.NeedCaptcha(username, ip)
 }
 
 // [NEW] 实现 ResetPassword
@@ -216,9 +461,15 @@ func (s *userService) ResetPassword(username, email, password, code string) (str
 		return "", errors.New("验证邮箱与该账户绑定的邮箱不一致！")
 	}
 
-	// 4. 重置密码 (加密)
-	hashedPwd, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	user.Password = string(hashedPwd)
+	// 4. [MODIFY] 校验密码强度、历史密码后加密
+	if err := s.passwordService.Check(user.Id, user.Password, password); err != nil {
+		return "", err
+	}
+	hashedPwd, err := s.passwordService.Hash(password)
+	if err != nil {
+		return "", err
+	}
+	user.Password = hashedPwd
 
 	// 5. 更新数据库
 	// 注意：这里只更新密码字段，避免覆盖其他字段
@@ -230,6 +481,10 @@ func (s *userService) ResetPassword(username, email, password, code string) (str
 
 	// 6. 成功后删除验证码
 	config.RDB.Del(config.Ctx, key)
+	s.passwordService.Remember(user.Id, hashedPwd) // [NEW]
+
+	// 7. [NEW] 密码已变更，吊销该账号所有已登录会话和个人访问令牌
+	s.tokenService.RevokeAllCredentials(user.Id)
 
 	return "密码重置成功", nil
 }
@@ -244,12 +499,10 @@ func (s *userService) UpdateUser(user *model.User) (*model.User, error) {
 
 	// 2. 更新字段 (只允许更新昵称、邮箱、头像等，不含密码)
 	// 注意：Username 通常作为唯一标识不让改，但如果你的业务允许改，也可以覆盖
+	// [MODIFY] 邮箱是找回密码的凭据，这里忽略 Email，修改邮箱必须验证新邮箱 (见 ChangeEmail)
 	if user.Username != "" {
 		oldUser.Username = user.Username
 	}
-	if user.Email != "" {
-		oldUser.Email = user.Email
-	}
 	if user.Avatar != "" {
 		oldUser.Avatar = user.Avatar
 	}
@@ -262,6 +515,133 @@ func (s *userService) UpdateUser(user *model.User) (*model.User, error) {
 	return oldUser, nil
 }
 
+// Redis Key 前缀 (修改邮箱)
+const (
+	changeEmailKey      = "change_email:"       // + userId -> Hash{email, code, attempts} (10分钟)
+	changeEmailLimitKey = "change_email_limit:" // + userId -> 1分钟内不能重复发送
+	changeEmailCountKey = "change_email_count:" // + userId -> 1小时内发送次数
+)
+
+const changeEmailAttempts = 5 // 验证码最多尝试次数
+
+// [NEW] 实现 SendChangeEmailCode
+func (s *userService) SendChangeEmailCode(userId int, newEmail string) error {
+	newEmail = strings.TrimSpace(newEmail)
+	if !validEmail(newEmail) {
+		return errors.New("邮箱格式不正确")
+	}
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+	if strings.EqualFold(user.Email, newEmail) {
+		return errors.New("新邮箱与当前邮箱相同")
+	}
+	if existing, err := s.userRepo.FindByEmail(newEmail); err == nil && existing.Id != userId {
+		return errors.New("该邮箱已被其他账号使用")
+	}
+
+	// 频率限制：1 分钟 1 次、1 小时 5 次
+	uid := strconv.Itoa(userId)
+	if config.RDB.Exists(config.Ctx, changeEmailLimitKey+uid).Val() > 0 {
+		return errors.New("验证码已发送，请勿频繁操作")
+	}
+	if !withinHourlyLimit(changeEmailCountKey+uid, 5) {
+		return errors.New("发送次数过多，请稍后再试")
+	}
+	config.RDB.Set(config.Ctx, changeEmailLimitKey+uid, 1, time.Minute)
+
+	// 验证码与新邮箱绑定，重新获取会覆盖之前的
+	code := s.mailService.GenerateCode()
+	key := changeEmailKey + uid
+	config.RDB.Del(config.Ctx, key)
+	config.RDB.HSet(config.Ctx, key, "email", newEmail, "code", code, "attempts", 0)
+	config.RDB.Expire(config.Ctx, key, 10*time.Minute)
+
+	err = s.mailService.SendMail(newEmail,
+		"【你的博客名】修改邮箱验证码",
+		fmt.Sprintf("您好 <b>%s</b>，您正在将账号邮箱修改为本邮箱，验证码是：<b>%s</b>。有效时间为10分钟，请勿泄露给他人。<br>"+
+			"如果不是您本人操作，请忽略本邮件。", html.EscapeString(user.Username), code))
+	if err != nil {
+		config.RDB.Del(config.Ctx, key)
+		return errors.New("邮件发送失败，请检查邮箱是否正确")
+	}
+	return nil
+}
+
+// [NEW] 实现 ChangeEmail
+func (s *userService) ChangeEmail(userId int, newEmail, code string) (*model.User, error) {
+	newEmail = strings.TrimSpace(newEmail)
+	key := changeEmailKey + strconv.Itoa(userId)
+
+	// 1. 校验验证码 (必须是发给这个新邮箱的)
+	data, err := config.RDB.HGetAll(config.Ctx, key).Result()
+	if err != nil || len(data) == 0 || data["email"] != newEmail {
+		return nil, errors.New("验证码已过期，请重新获取")
+	}
+	if data["code"] != strings.TrimSpace(code) {
+		if config.RDB.HIncrBy(config.Ctx, key, "attempts", 1).Val() >= changeEmailAttempts {
+			config.RDB.Del(config.Ctx, key)
+			return nil, errors.New("验证码错误次数过多，请重新获取")
+		}
+		return nil, errors.New("验证码错误")
+	}
+
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	// 2. 发送验证码之后可能已被别人注册，再检查一次 (数据库唯一索引兜底)
+	if existing, err := s.userRepo.FindByEmail(newEmail); err == nil && existing.Id != userId {
+		config.RDB.Del(config.Ctx, key)
+		return nil, errors.New("该邮箱已被其他账号使用")
+	}
+	if err := s.userRepo.UpdateEmail(userId, newEmail); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, errors.New("该邮箱已被其他账号使用")
+		}
+		return nil, errors.New("修改邮箱失败")
+	}
+	config.RDB.Del(config.Ctx, key)
+
+	// 3. 通知旧邮箱 (账号被盗时原主人能及时发现)
+	oldEmail := user.Email
+	if oldEmail != "" {
+		err := s.mailService.SendMail(oldEmail,
+			"【你的博客名】账号邮箱已修改",
+			fmt.Sprintf("您好 <b>%s</b>，您的账号邮箱已于 %s 修改为 %s。<br>"+
+				"如果不是您本人操作，请立即修改密码并联系管理员。",
+				html.EscapeString(user.Username), time.Now().Format("2006-01-02 15:04:05"), html.EscapeString(maskEmail(newEmail))))
+		if err != nil {
+			log.Printf("⚠️ 邮箱修改通知发送失败 (userId=%d): %v", userId, err)
+		}
+	}
+
+	user.Email = newEmail
+	return user, nil
+}
+
+// 邮箱格式校验 (只接受纯地址，不接受 "Name <a@b.c>" 形式)
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
+
+// 邮箱脱敏：abcdef@qq.com -> ab****@qq.com
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return email
+	}
+	name := []rune(email[:at])
+	keep := 2
+	if len(name) <= keep {
+		keep = 1
+	}
+	return string(name[:keep]) + "****" + email[at:]
+}
+
 // [NEW] 修改密码
 func (s *userService) UpdatePassword(userId int, oldPwd, newPwd string) error {
 	// 1. 查用户
@@ -275,10 +655,33 @@ func (s *userService) UpdatePassword(userId int, oldPwd, newPwd string) error {
 		return errors.New("旧密码错误")
 	}
 
-	// 3. 加密新密码
-	hashedPwd, _ := bcrypt.GenerateFromPassword([]byte(newPwd), bcrypt.DefaultCost)
-	user.Password = string(hashedPwd)
+	// 3. [MODIFY] 校验密码强度、历史密码后加密
+	if err := s.passwordService.Check(userId, user.Password, newPwd); err != nil {
+		return err
+	}
+	hashedPwd, err := s.passwordService.Hash(newPwd)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPwd
 
 	// 4. 更新
-	return s.userRepo.Update(user)
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	s.passwordService.Remember(userId, hashedPwd) // [NEW]
+
+	// 5. [NEW] 吊销所有已登录会话 (包括当前会话，需要重新登录) 和个人访问令牌
+	return s.tokenService.RevokeAllCredentials(userId)
+}
+
+// [NEW] 填充返回给前端的角色信息
+// 前端路由依赖 authorities[0].authority == "ROLE_admin" 判断是否进入后台，
+// roles 已按 id 升序排列，admin 永远排在第一位
+func FillAuthorities(user *model.User, roles []string) {
+	user.Roles = roles
+	user.Authorities = []map[string]string{}
+	for _, role := range roles {
+		user.Authorities = append(user.Authorities, map[string]string{"authority": "ROLE_" + role})
+	}
 }
@@ -0,0 +1,153 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// --- 分词词典 ---
+// 格式与 jieba 的词典一致：每行 "词 [词频] [拼音]"，词频越高切分时越优先
+// 拼音用空格分隔 (如 "重庆 3000 chong qing")，只在多音字读错时需要填写
+
+//go:embed dict.txt
+var bundledDict []byte
+
+// 用户词典里不写词频时的默认值 (比大部分内置词高，优先切出站点自己的词)
+const userWordFreq = 5000
+
+// Dictionary 分词词典，可以在内置词典的基础上追加用户词典
+type Dictionary struct {
+	mu     sync.RWMutex
+	freq   map[string]int
+	pinyin map[string][]string // 指定了拼音的词
+	total  int
+	maxLen int // 最长的词有几个字
+}
+
+var defaultDict = NewDictionary()
+
+// NewDictionary 返回只包含内置词典的词典
+func NewDictionary() *Dictionary {
+	d := &Dictionary{freq: map[string]int{}, pinyin: map[string][]string{}}
+	if _, err := d.load(bytes.NewReader(bundledDict), 1); err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// DefaultDictionary 全局词典 (LoadUserDict 加载的词都在这里)
+func DefaultDictionary() *Dictionary {
+	return defaultDict
+}
+
+// LoadUserDict 把用户词典追加到全局词典，返回加载的词数
+func LoadUserDict(r io.Reader) (int, error) {
+	return defaultDict.load(r, userWordFreq)
+}
+
+// Add 添加一个词，freq <= 0 时使用默认词频，pinyin 可以为空
+func (d *Dictionary) Add(word string, freq int, pinyin ...string) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return
+	}
+	if freq <= 0 {
+		freq = userWordFreq
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.total += freq - d.freq[word]
+	d.freq[word] = freq
+	if len(pinyin) > 0 {
+		syllables := make([]string, 0, len(pinyin))
+		for _, py := range pinyin {
+			syllables = append(syllables, strings.ToLower(py))
+		}
+		d.pinyin[word] = syllables
+	}
+	d.maxLen = max(d.maxLen, utf8.RuneCountInString(word))
+}
+
+// Contains 词典里是否有这个词
+func (d *Dictionary) Contains(word string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.freq[word] > 0
+}
+
+// --- Helper Functions ---
+
+func (d *Dictionary) load(r io.Reader, defaultFreq int) (int, error) {
+	count := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		freq := defaultFreq
+		if len(fields) > 1 {
+			f, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return 0, fmt.Errorf("词典格式错误: %s", line)
+			}
+			freq = f
+		}
+		var pinyin []string
+		if len(fields) > 2 {
+			pinyin = fields[2:]
+		}
+		d.Add(fields[0], freq, pinyin...)
+		count++
+	}
+	return count, scanner.Err()
+}
+
+// 最大概率切分：把一段连续的中文切成词 (词典中没有的字单独成词)
+// 从后往前动态规划，每种切法的得分为各个词 log(词频 / 总词频) 之和
+func (d *Dictionary) cut(runes []rune) []int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	n := len(runes)
+	logTotal := math.Log(float64(max(d.total, 1)))
+	score := make([]float64, n+1)
+	next := make([]int, n+1)
+	for i := n - 1; i >= 0; i-- {
+		score[i] = math.Inf(-1)
+		for end := i + 1; end <= n && end-i <= max(d.maxLen, 1); end++ {
+			freq := d.freq[string(runes[i:end])]
+			if freq == 0 && end-i > 1 {
+				continue
+			}
+			s := math.Log(float64(max(freq, 1))) - logTotal + score[end]
+			if s > score[i] {
+				score[i], next[i] = s, end
+			}
+		}
+	}
+
+	// 每个词的结束位置
+	var ends []int
+	for i := 0; i < n; i = next[i] {
+		ends = append(ends, next[i])
+	}
+	return ends
+}
+
+// 指定的拼音，没有时返回 nil
+func (d *Dictionary) pinyinOf(word string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.pinyin[word]
+}
@@ -0,0 +1,99 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// 去掉所有 HTML 标签
+var textPolicy = bluemonday.StrictPolicy()
+
+// PlainText 把正文 (HTML / Markdown) 转成纯文本，连续空白合并为一个空格
+func PlainText(content string) string {
+	text := html.UnescapeString(textPolicy.Sanitize(content))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Highlight 把 text 中命中 terms 的部分用 <em></em> 包起来，其余内容做 HTML 转义
+// size > 0 时只截取命中最集中的约 size 个字符作为摘要 (两端被截断时加 "…")
+func (ix *Index) Highlight(text string, terms []string, size int) string {
+	spans := matchSpans(ix.tokenizer.Tokenize(text), terms)
+
+	start, end := 0, len(text)
+	if size > 0 && utf8.RuneCountInString(text) > size {
+		start, end = snippetWindow(text, spans, size)
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	pos := start
+	for _, span := range spans {
+		if span[1] <= start || span[0] >= end {
+			continue
+		}
+		s, e := max(span[0], start), min(span[1], end)
+		sb.WriteString(html.EscapeString(text[pos:s]))
+		sb.WriteString("<em>")
+		sb.WriteString(html.EscapeString(text[s:e]))
+		sb.WriteString("</em>")
+		pos = e
+	}
+	sb.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
+
+// --- Helper Functions ---
+
+// 命中的字节区间 [start, end)，已排序并合并重叠的区间
+func matchSpans(tokens []Token, terms []string) [][2]int {
+	want := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		want[term] = true
+	}
+	var spans [][2]int
+	for _, token := range tokens {
+		if want[token.Term] {
+			spans = append(spans, [2]int{token.Start, token.End})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	merged := spans[:0]
+	for _, span := range spans {
+		if n := len(merged); n > 0 && span[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], span[1])
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// 选出包含命中最多的 size 个字符的区间，命中处前面留一点上下文
+func snippetWindow(text string, spans [][2]int, size int) (int, int) {
+	offsets := runeOffsets(text, 0)
+	runeAt := func(b int) int { return sort.SearchInts(offsets, b) }
+
+	best, bestCount := 0, 0
+	for i, j := 0, 0; i < len(spans); i++ {
+		for j < len(spans) && runeAt(spans[j][1])-runeAt(spans[i][0]) <= size {
+			j++
+		}
+		if j-i > bestCount {
+			best, bestCount = runeAt(spans[i][0]), j-i
+		}
+	}
+	start := max(best-size/5, 0)
+	end := min(start+size, len(offsets)-1)
+	start = max(end-size, 0)
+	return offsets[start], offsets[end]
+}
@@ -0,0 +1,68 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestHighlight(t *testing.T) {
+	ix := NewIndex(NewDictTokenizer(NewDictionary()))
+	filler := strings.Repeat("一二三四五六七八九十", 4)
+	long := "开头是一段很长的铺垫文字，和主题没有什么关系，只是为了把正文撑长一些。今天聊聊微服务和单体应用的区别，微服务不是银弹。结尾也很长，同样是一些和主题无关的文字，用来测试摘要窗口的截断位置。"
+
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		size  int
+		want  string
+	}{
+		{
+			name: "hit at start", text: "设计" + filler + "实践", terms: []string{"设计"}, size: 20,
+			want: "<em>设计</em>一二三四五六七八九十一二三四五六七八…",
+		},
+		{
+			name: "hit at end", text: "设计" + filler + "实践", terms: []string{"实践"}, size: 20,
+			want: "…三四五六七八九十一二三四五六七八九十<em>实践</em>",
+		},
+		{
+			name: "hit in middle", text: filler + "微服务" + filler, terms: []string{"微服务"}, size: 20,
+			want: "…七八九十<em>微服务</em>一二三四五六七八九十一二三…",
+		},
+		{
+			name: "window covers several hits", text: long, terms: []string{"微服务"}, size: 30,
+			want: "…些。今天聊聊<em>微服务</em>和单体应用的区别，<em>微服务</em>不是银弹。结尾也很…",
+		},
+		{
+			name: "shorter than window", text: "短文本里的微服务", terms: []string{"微服务"}, size: 20,
+			want: "短文本里的<em>微服务</em>",
+		},
+		{
+			name: "no hit shows the beginning", text: filler + "缓存" + filler, terms: []string{"数据库"}, size: 20,
+			want: "一二三四五六七八九十一二三四五六七八九十…",
+		},
+		{
+			name: "html escaped", text: "<b>微服务</b> & 架构", terms: []string{"微服务"}, size: 0,
+			want: "&lt;b&gt;<em>微服务</em>&lt;/b&gt; &amp; 架构",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ix.Highlight(tt.text, tt.terms, tt.size)
+			if got != tt.want {
+				t.Errorf("Highlight() = %q, want %q", got, tt.want)
+			}
+			// 窗口边界不能切在多字节字符中间
+			if !utf8.ValidString(got) {
+				t.Errorf("Highlight() returned invalid UTF-8: %q", got)
+			}
+			if tt.size > 0 {
+				plain := strings.NewReplacer("<em>", "", "</em>", "", "…", "").Replace(got)
+				if n := utf8.RuneCountInString(plain); n > tt.size {
+					t.Errorf("snippet has %d characters, want at most %d", n, tt.size)
+				}
+			}
+		})
+	}
+}
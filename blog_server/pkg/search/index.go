@@ -0,0 +1,293 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Field 建索引的字段
+type Field int
+
+const (
+	FieldTitle Field = iota
+	FieldTags
+	FieldContent
+	numFields
+)
+
+// 各字段的权重：标题命中比正文命中更相关
+var fieldWeights = [numFields]float64{3, 2, 1}
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Document 被索引的文档，Content 为纯文本
+// 索引里只保存除 Content 以外的字段 (用于筛选)
+type Document struct {
+	Id         int
+	Title      string
+	Tags       []string // 每个元素是一个标签 (标签本身可以包含空格，如 "Spring Boot")
+	Content    string
+	CategoryId int
+	UserId     int
+	Created    time.Time
+}
+
+// Query 搜索条件
+type Query struct {
+	Text   string
+	Fields []Field // 只在这些字段里搜，为空时搜全部字段
+	// Filter 返回 false 的文档不出现在结果里 (分类、作者、标签、时间等筛选)
+	Filter func(doc *Document) bool
+}
+
+// Hit 一条搜索结果
+type Hit struct {
+	Id    int
+	Score float64
+}
+
+// Result 搜索结果，按相关度从高到低排序
+// Terms 为实际匹配的词 (包括拼音对应的中文词，用于高亮)
+type Result struct {
+	Hits  []Hit
+	Terms []string
+}
+
+// Index 内存倒排索引：所有搜索词都要命中 (AND)，按 BM25 计算相关度
+// 搜索词是拼音或拼音首字母时，也能匹配对应的中文词 ("wfw" -> "微服务")
+type Index struct {
+	mu        sync.RWMutex
+	tokenizer Tokenizer
+	docs      map[int]*indexedDoc
+	postings  map[string]map[int]*[numFields]int // 词 -> 文档 ID -> 各字段出现次数
+	totalLen  [numFields]int                     // 各字段的总词数 (计算平均长度)
+	pinyin    map[string]map[string]bool         // 拼音 / 首字母 -> 索引中的中文词
+}
+
+type indexedDoc struct {
+	doc   *Document
+	lens  [numFields]int
+	terms []string
+}
+
+func NewIndex(tokenizer Tokenizer) *Index {
+	return &Index{
+		tokenizer: tokenizer,
+		docs:      make(map[int]*indexedDoc),
+		postings:  make(map[string]map[int]*[numFields]int),
+		pinyin:    make(map[string]map[string]bool),
+	}
+}
+
+// Put 添加或更新文档
+func (ix *Index) Put(doc *Document) {
+	d, counts := ix.analyze(doc)
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(doc.Id)
+	ix.add(d, counts)
+}
+
+// Remove 删除文档
+func (ix *Index) Remove(ids ...int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, id := range ids {
+		ix.remove(id)
+	}
+}
+
+// Replace 用 docs 重建整个索引 (建好之后再替换，重建期间不影响搜索)
+func (ix *Index) Replace(docs []*Document) {
+	fresh := NewIndex(ix.tokenizer)
+	for _, doc := range docs {
+		d, counts := fresh.analyze(doc)
+		fresh.add(d, counts)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.docs, ix.postings, ix.totalLen, ix.pinyin = fresh.docs, fresh.postings, fresh.totalLen, fresh.pinyin
+}
+
+// Search 搜索，没有搜索词时返回空结果
+func (ix *Index) Search(q *Query) *Result {
+	result := &Result{Hits: []Hit{}}
+	fields := q.Fields
+	if len(fields) == 0 {
+		fields = []Field{FieldTitle, FieldTags, FieldContent}
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	// 每个搜索词命中它本身或拼音对应的任意一个词即可
+	var lists []map[int]*[numFields]int
+	for _, term := range dedupe(ix.tokenizer.Cut(q.Text)) {
+		var alternatives []string
+		if _, ok := ix.postings[term]; ok {
+			alternatives = append(alternatives, term)
+		}
+		for word := range ix.pinyin[term] {
+			alternatives = append(alternatives, word)
+		}
+		if len(alternatives) == 0 {
+			return &Result{Hits: []Hit{}}
+		}
+		sort.Strings(alternatives)
+		result.Terms = append(result.Terms, alternatives...)
+		lists = append(lists, ix.mergePostings(alternatives))
+	}
+	if len(lists) == 0 {
+		return result
+	}
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+
+	n := float64(len(ix.docs))
+	var avgLen [numFields]float64
+	for f := range avgLen {
+		avgLen[f] = math.Max(float64(ix.totalLen[f])/n, 1)
+	}
+
+	for id := range lists[0] {
+		d := ix.docs[id]
+		if q.Filter != nil && !q.Filter(d.doc) {
+			continue
+		}
+		score := 0.0
+		for _, list := range lists {
+			counts, ok := list[id]
+			if !ok {
+				score = 0
+				break
+			}
+			termScore := 0.0
+			for _, f := range fields {
+				if tf := float64(counts[f]); tf > 0 {
+					norm := 1 - bm25B + bm25B*float64(d.lens[f])/avgLen[f]
+					termScore += fieldWeights[f] * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+				}
+			}
+			if termScore == 0 {
+				score = 0
+				break
+			}
+			df := float64(len(list))
+			score += math.Log(1+(n-df+0.5)/(df+0.5)) * termScore
+		}
+		if score > 0 {
+			result.Hits = append(result.Hits, Hit{Id: id, Score: score})
+		}
+	}
+
+	// 相关度相同时新文章在前
+	sort.Slice(result.Hits, func(i, j int) bool {
+		a, b := result.Hits[i], result.Hits[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Id > b.Id
+	})
+	return result
+}
+
+// --- Helper Functions ---
+
+// 多个词的倒排表合并成一个 (出现次数相加)
+func (ix *Index) mergePostings(terms []string) map[int]*[numFields]int {
+	if len(terms) == 1 {
+		return ix.postings[terms[0]]
+	}
+	merged := make(map[int]*[numFields]int)
+	for _, term := range terms {
+		for id, counts := range ix.postings[term] {
+			c, ok := merged[id]
+			if !ok {
+				c = &[numFields]int{}
+				merged[id] = c
+			}
+			for f := range counts {
+				c[f] += counts[f]
+			}
+		}
+	}
+	return merged
+}
+
+// 分词并统计每个词在各字段的出现次数 (不需要加锁)
+func (ix *Index) analyze(doc *Document) (*indexedDoc, map[string]*[numFields]int) {
+	meta := *doc
+	meta.Content = ""
+	d := &indexedDoc{doc: &meta}
+	counts := make(map[string]*[numFields]int)
+
+	texts := [numFields]string{FieldTitle: doc.Title, FieldTags: strings.Join(doc.Tags, " "), FieldContent: doc.Content}
+	for f, text := range texts {
+		tokens := ix.tokenizer.Tokenize(text)
+		d.lens[f] = len(tokens)
+		for _, token := range tokens {
+			c, ok := counts[token.Term]
+			if !ok {
+				c = &[numFields]int{}
+				counts[token.Term] = c
+				d.terms = append(d.terms, token.Term)
+			}
+			c[f]++
+		}
+	}
+	return d, counts
+}
+
+func (ix *Index) add(d *indexedDoc, counts map[string]*[numFields]int) {
+	id := d.doc.Id
+	ix.docs[id] = d
+	for term, c := range counts {
+		list, ok := ix.postings[term]
+		if !ok {
+			list = make(map[int]*[numFields]int)
+			ix.postings[term] = list
+			for _, key := range ix.tokenizer.Pinyin(term) {
+				if ix.pinyin[key] == nil {
+					ix.pinyin[key] = make(map[string]bool)
+				}
+				ix.pinyin[key][term] = true
+			}
+		}
+		list[id] = c
+	}
+	for f := range d.lens {
+		ix.totalLen[f] += d.lens[f]
+	}
+}
+
+func (ix *Index) remove(id int) {
+	d, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range d.terms {
+		list := ix.postings[term]
+		delete(list, id)
+		if len(list) == 0 {
+			delete(ix.postings, term)
+			for _, key := range ix.tokenizer.Pinyin(term) {
+				delete(ix.pinyin[key], term)
+				if len(ix.pinyin[key]) == 0 {
+					delete(ix.pinyin, key)
+				}
+			}
+		}
+	}
+	for f := range d.lens {
+		ix.totalLen[f] -= d.lens[f]
+	}
+	delete(ix.docs, id)
+}
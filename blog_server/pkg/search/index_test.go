@@ -0,0 +1,83 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	ix := newTestIndex()
+	tests := []struct {
+		name      string
+		query     *Query
+		wantIds   []int
+		wantTerms []string
+	}{
+		{"pinyin initials", &Query{Text: "wfw"}, []int{1, 4}, []string{"微服务"}},
+		{"full pinyin", &Query{Text: "weifuwu"}, []int{1, 4}, []string{"微服务"}},
+		{"sub word", &Query{Text: "服务"}, []int{1, 4}, []string{"服务"}},
+		// "sj" 同时对应 "实践"、"数据"、"设计"，命中任意一个即可
+		{"pinyin alternatives merged", &Query{Text: "sj"}, []int{1, 3, 2}, []string{"实践", "数据", "设计"}},
+		// 多个搜索词之间是 AND
+		{"and across terms", &Query{Text: "缓存 数据库"}, []int{3}, []string{"缓存", "数据库"}},
+		{"alternatives and another term", &Query{Text: "sj 缓存"}, []int{2, 3}, []string{"实践", "数据", "设计", "缓存"}},
+		{"title outranks content", &Query{Text: "缓存"}, []int{2, 3}, []string{"缓存"}},
+		{"title only", &Query{Text: "缓存", Fields: []Field{FieldTitle}}, []int{2}, []string{"缓存"}},
+		{"filter", &Query{Text: "wfw", Filter: func(doc *Document) bool { return doc.CategoryId == 2 }}, []int{4}, []string{"微服务"}},
+		{"no match", &Query{Text: "不存在"}, nil, nil},
+		{"empty", &Query{Text: ""}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ix.Search(tt.query)
+			if got := hitIds(r); !reflect.DeepEqual(got, tt.wantIds) {
+				t.Errorf("Search(%q) hits = %v, want %v", tt.query.Text, got, tt.wantIds)
+			}
+			if len(r.Terms) != len(tt.wantTerms) || (len(r.Terms) > 0 && !reflect.DeepEqual(r.Terms, tt.wantTerms)) {
+				t.Errorf("Search(%q) terms = %q, want %q", tt.query.Text, r.Terms, tt.wantTerms)
+			}
+		})
+	}
+}
+
+func TestIndexPutRemove(t *testing.T) {
+	ix := newTestIndex()
+
+	// Put 同一个 ID 为更新，旧内容不再命中
+	ix.Put(&Document{Id: 4, Title: "随笔", Content: "聊聊单体应用"})
+	if got := hitIds(ix.Search(&Query{Text: "wfw"})); !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("after update hits = %v, want [1]", got)
+	}
+	if got := hitIds(ix.Search(&Query{Text: "单体"})); !reflect.DeepEqual(got, []int{4}) {
+		t.Fatalf("updated content hits = %v, want [4]", got)
+	}
+
+	ix.Remove(1, 4)
+	if got := hitIds(ix.Search(&Query{Text: "wfw"})); got != nil {
+		t.Fatalf("after remove hits = %v, want none", got)
+	}
+	if got := hitIds(ix.Search(&Query{Text: "缓存"})); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Fatalf("other documents hits = %v, want [2 3]", got)
+	}
+}
+
+// --- Helper Functions ---
+
+func newTestIndex() *Index {
+	ix := NewIndex(NewDictTokenizer(NewDictionary()))
+	ix.Replace([]*Document{
+		{Id: 1, Title: "微服务架构的设计与实践", Content: "服务拆分和数据一致性", CategoryId: 1},
+		{Id: 2, Title: "Redis 缓存设计", Content: "缓存穿透、缓存雪崩", CategoryId: 1},
+		{Id: 3, Title: "数据库索引", Content: "B+ 树和缓存", CategoryId: 1},
+		{Id: 4, Title: "随笔", Content: "聊聊微服务", CategoryId: 2},
+	})
+	return ix
+}
+
+func hitIds(r *Result) []int {
+	var ids []int
+	for _, hit := range r.Hits {
+		ids = append(ids, hit.Id)
+	}
+	return ids
+}
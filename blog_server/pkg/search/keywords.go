@@ -0,0 +1,80 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// --- 关键词提取 (推荐标签) ---
+
+//go:embed stopwords.txt
+var bundledStopwords []byte
+
+// 停用词：虚词、代词等没有区分度的词
+var stopwords = loadStopwords()
+
+// Keyword 关键词及其权重
+type Keyword struct {
+	Word   string
+	Weight float64
+}
+
+// Keywords 用 TF-IDF 提取 text 的关键词 (IDF 使用索引中的文档频率)，按权重从高到低返回最多 n 个
+// 停用词、单个汉字、单个字母和纯数字不作为关键词
+func (ix *Index) Keywords(text string, n int) []Keyword {
+	tf := map[string]int{}
+	for _, word := range ix.tokenizer.Cut(text) {
+		if isKeyword(word) {
+			tf[word]++
+		}
+	}
+
+	ix.mu.RLock()
+	total := float64(len(ix.docs))
+	keywords := make([]Keyword, 0, len(tf))
+	for word, count := range tf {
+		df := float64(len(ix.postings[word]))
+		idf := math.Log((total+1)/(df+1)) + 1
+		keywords = append(keywords, Keyword{Word: word, Weight: float64(count) * idf})
+	}
+	ix.mu.RUnlock()
+
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Weight != keywords[j].Weight {
+			return keywords[i].Weight > keywords[j].Weight
+		}
+		return keywords[i].Word < keywords[j].Word
+	})
+	if len(keywords) > n {
+		keywords = keywords[:n]
+	}
+	return keywords
+}
+
+// --- Helper Functions ---
+
+func loadStopwords() map[string]bool {
+	words := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(bundledStopwords))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			words[line] = true
+		}
+	}
+	return words
+}
+
+func isKeyword(word string) bool {
+	if stopwords[word] || utf8.RuneCountInString(word) < 2 {
+		return false
+	}
+	// 纯数字 (年份、版本号的一部分等)
+	return strings.IndexFunc(word, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0
+}
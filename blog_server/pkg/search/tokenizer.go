@@ -0,0 +1,174 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gosimple/unidecode"
+)
+
+// Token 分词结果，Start / End 为在原文中的字节位置 (用于高亮)
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// Tokenizer 分词器：建索引和搜索必须用同一个分词器
+type Tokenizer interface {
+	// Cut 精确切分 (搜索词、关键词提取用)，英文转小写
+	Cut(text string) []string
+	// Tokenize 建索引用：除了 Cut 的结果，长词里包含的短词也切出来 ("微服务" -> "微服务"、"服务")
+	Tokenize(text string) []Token
+	// Pinyin 中文词的全拼和首字母 ("微服务" -> "weifuwu"、"wfw")，用于拼音搜索；不是中文词时返回 nil
+	Pinyin(term string) []string
+}
+
+// DictTokenizer 英文、数字按单词切分 (转小写)；中文按词典切词
+type DictTokenizer struct {
+	dict *Dictionary
+}
+
+func NewDictTokenizer(dict *Dictionary) *DictTokenizer {
+	return &DictTokenizer{dict: dict}
+}
+
+func (t *DictTokenizer) Cut(text string) []string {
+	tokens := t.cut(text)
+	terms := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		terms = append(terms, token.Term)
+	}
+	return terms
+}
+
+func (t *DictTokenizer) Tokenize(text string) []Token {
+	var tokens []Token
+	for _, token := range t.cut(text) {
+		tokens = append(tokens, token)
+		// 长词里词典中有的短词 (搜索 "服务" 也能找到 "微服务")
+		offsets := runeOffsets(token.Term, token.Start)
+		if len(offsets) <= 3 || !isHan(token.Term) {
+			continue
+		}
+		for size := 2; size < len(offsets)-1; size++ {
+			for i := 0; i+size < len(offsets); i++ {
+				sub := text[offsets[i]:offsets[i+size]]
+				if t.dict.Contains(sub) {
+					tokens = append(tokens, Token{Term: sub, Start: offsets[i], End: offsets[i+size]})
+				}
+			}
+		}
+	}
+	return tokens
+}
+
+func (t *DictTokenizer) Pinyin(term string) []string {
+	// 单字的拼音太短，匹配的结果没有意义
+	if utf8.RuneCountInString(term) < 2 || !isHan(term) {
+		return nil
+	}
+	syllables := t.dict.pinyinOf(term)
+	if syllables == nil {
+		for _, r := range term {
+			s := strings.Map(pinyinLetter, strings.ToLower(unidecode.Unidecode(string(r))))
+			if s == "" {
+				return nil
+			}
+			syllables = append(syllables, s)
+		}
+	}
+	var full, initials strings.Builder
+	for _, s := range syllables {
+		full.WriteString(s)
+		initials.WriteByte(s[0])
+	}
+	return dedupe([]string{full.String(), initials.String()})
+}
+
+// --- Helper Functions ---
+
+// 精确切分，带位置
+func (t *DictTokenizer) cut(text string) []Token {
+	var tokens []Token
+	for _, run := range splitRuns(text) {
+		if !run.han {
+			tokens = append(tokens, Token{Term: strings.ToLower(text[run.start:run.end]), Start: run.start, End: run.end})
+			continue
+		}
+		offsets := runeOffsets(text[run.start:run.end], run.start)
+		runes := []rune(text[run.start:run.end])
+		start := 0
+		for _, end := range t.dict.cut(runes) {
+			tokens = append(tokens, Token{Term: text[offsets[start]:offsets[end]], Start: offsets[start], End: offsets[end]})
+			start = end
+		}
+	}
+	return tokens
+}
+
+// 一段连续的中文，或一个英文 / 数字单词
+type textRun struct {
+	start, end int
+	han        bool
+}
+
+// 按字符类型切段，标点、空白等其他字符作为分隔符
+func splitRuns(text string) []textRun {
+	var runs []textRun
+	start, han := -1, false
+	for i, r := range text {
+		isHan := unicode.Is(unicode.Han, r)
+		isWord := !isHan && (unicode.IsLetter(r) || unicode.IsDigit(r))
+		if start >= 0 && (!(isHan || isWord) || isHan != han) {
+			runs = append(runs, textRun{start: start, end: i, han: han})
+			start = -1
+		}
+		if start < 0 && (isHan || isWord) {
+			start, han = i, isHan
+		}
+	}
+	if start >= 0 {
+		runs = append(runs, textRun{start: start, end: len(text), han: han})
+	}
+	return runs
+}
+
+// 拼音里只保留 a-z (去掉空格、声调等)
+func pinyinLetter(r rune) rune {
+	if r >= 'a' && r <= 'z' {
+		return r
+	}
+	return -1
+}
+
+func isHan(s string) bool {
+	for _, r := range s {
+		if !unicode.Is(unicode.Han, r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// 每个字符的起始字节位置 (加上 base)，最后一个元素为结尾位置
+func runeOffsets(s string, base int) []int {
+	offsets := make([]int, 0, utf8.RuneCountInString(s)+1)
+	for i := range s {
+		offsets = append(offsets, base+i)
+	}
+	return append(offsets, base+len(s))
+}
+
+func dedupe(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	result := terms[:0]
+	for _, term := range terms {
+		if term != "" && !seen[term] {
+			seen[term] = true
+			result = append(result, term)
+		}
+	}
+	return result
+}
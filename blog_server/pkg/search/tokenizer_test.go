@@ -0,0 +1,117 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCut(t *testing.T) {
+	tk := NewDictTokenizer(NewDictionary())
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"微服务架构的设计与实践", []string{"微服务", "架构", "的", "设计", "与", "实践"}},
+		{"Go语言 Redis缓存, Hello World!", []string{"go", "语言", "redis", "缓存", "hello", "world"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := tk.Cut(tt.text); len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("Cut(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeOffsets(t *testing.T) {
+	tk := NewDictTokenizer(NewDictionary())
+	text := "微服务"
+	got := tk.Tokenize(text)
+	want := []Token{{Term: "微服务", Start: 0, End: 9}, {Term: "服务", Start: 3, End: 9}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tokenize(%q) = %+v, want %+v", text, got, want)
+	}
+	for _, tok := range got {
+		if !strings.HasSuffix(text[:tok.End], tok.Term) {
+			t.Errorf("token %q does not match text[%d:%d]", tok.Term, tok.Start, tok.End)
+		}
+	}
+}
+
+func TestPinyin(t *testing.T) {
+	tk := NewDictTokenizer(NewDictionary())
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"微服务", []string{"weifuwu", "wfw"}},
+		{"数据", []string{"shuju", "sj"}},
+		{"设计", []string{"sheji", "sj"}},
+		{"重庆", []string{"zhongqing", "zq"}}, // 内置词典没有指定读音，按常用读音
+		{"微", nil},                          // 单字不生成拼音
+		{"Go", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			if got := tk.Pinyin(tt.term); len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("Pinyin(%q) = %q, want %q", tt.term, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserDictionary(t *testing.T) {
+	tests := []struct {
+		name       string
+		dict       string
+		text       string
+		wantCut    []string
+		wantPinyin map[string][]string
+	}{
+		{
+			name:    "builtin only",
+			text:    "灰度发布系统",
+			wantCut: []string{"灰", "度", "发", "布", "系统"},
+		},
+		{
+			name:       "new word",
+			dict:       "# 站点自定义词\n灰度发布\n",
+			text:       "灰度发布系统",
+			wantCut:    []string{"灰度发布", "系统"},
+			wantPinyin: map[string][]string{"灰度发布": {"huidufabu", "hdfb"}},
+		},
+		{
+			name:       "polyphone override",
+			dict:       "重庆 3000 chong qing\n",
+			text:       "重庆火锅",
+			wantCut:    []string{"重庆", "火", "锅"},
+			wantPinyin: map[string][]string{"重庆": {"chongqing", "cq"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDictionary()
+			if _, err := d.load(strings.NewReader(tt.dict), userWordFreq); err != nil {
+				t.Fatal(err)
+			}
+			tk := NewDictTokenizer(d)
+			if got := tk.Cut(tt.text); !reflect.DeepEqual(got, tt.wantCut) {
+				t.Errorf("Cut(%q) = %q, want %q", tt.text, got, tt.wantCut)
+			}
+			for term, want := range tt.wantPinyin {
+				if got := tk.Pinyin(term); !reflect.DeepEqual(got, want) {
+					t.Errorf("Pinyin(%q) = %q, want %q", term, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUserDictionaryFormatError(t *testing.T) {
+	d := NewDictionary()
+	if _, err := d.load(strings.NewReader("灰度发布 很高\n"), userWordFreq); err == nil {
+		t.Fatal("expected format error for non-numeric frequency")
+	}
+}
@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 变更太多时 (比如整篇重写) 不再逐行比较，剩下的部分直接按"全部删除 + 全部新增"输出，避免占用过多内存
+const diffMaxEdits = 2000
+
+type diffOp struct {
+	kind byte // ' ' 相同 / '-' 删除 / '+' 新增
+	line string
+	a, b int // 该行之前 a、b 各有多少行 (用于计算 @@ 行号)
+}
+
+// UnifiedDiff 按行比较 a、b，生成 unified diff (与 diff -u / git diff 的格式一致)
+// context 为每个变更块前后保留的上下文行数；两者完全相同时返回 ""
+func UnifiedDiff(fromName, toName, a, b string, context int) string {
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	for _, h := range diffHunks(ops, context) {
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		hunk := ops[h[0]:h[1]]
+		aLen, bLen := 0, 0
+		for _, op := range hunk {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", diffRange(hunk[0].a, aLen), diffRange(hunk[0].b, bLen))
+		for _, op := range hunk {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// --- Helper Functions ---
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// @@ 行号：起始行从 1 开始；长度为 0 时起始行为前一行，长度为 1 时省略长度
+func diffRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, length)
+	}
+}
+
+// 把编辑序列切成若干变更块 [start, end)，相邻两处变更间隔不超过 2*context 行时合并成一块
+func diffHunks(ops []diffOp, context int) [][2]int {
+	var hunks [][2]int
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			// 往后找下一处变更，间隔太远就结束这一块
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = next
+		}
+		// 与上一块重叠时合并
+		if n := len(hunks); n > 0 && start <= hunks[n-1][1] {
+			hunks[n-1][1] = end
+		} else {
+			hunks = append(hunks, [2]int{start, end})
+		}
+		i = end
+	}
+	return hunks
+}
+
+// 逐行比较，返回把 a 变成 b 的最短编辑序列 (Myers 差分算法)
+func diffLines(a, b []string) []diffOp {
+	// 去掉相同的开头和结尾，只比较中间变化的部分
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{kind: ' ', line: a[i], a: i, b: i})
+	}
+	for _, op := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		op.a += prefix
+		op.b += prefix
+		ops = append(ops, op)
+	}
+	for i := suffix; i > 0; i-- {
+		ops = append(ops, diffOp{kind: ' ', line: a[len(a)-i], a: len(a) - i, b: len(b) - i})
+	}
+	return ops
+}
+
+func myers(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= n+m && d <= diffMaxEdits; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		return replaceAll(a, b)
+	}
+
+	// 从终点沿 trace 回溯出编辑路径
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{kind: ' ', line: a[x], a: x, b: y})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{kind: '+', line: b[y], a: x, b: y})
+		} else {
+			x--
+			ops = append(ops, diffOp{kind: '-', line: a[x], a: x, b: y})
+		}
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+func replaceAll(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for i, line := range a {
+		ops = append(ops, diffOp{kind: '-', line: line, a: i, b: 0})
+	}
+	for i, line := range b {
+		ops = append(ops, diffOp{kind: '+', line: line, a: len(a), b: i})
+	}
+	return ops
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string // 不含 ---/+++ 文件头，与 diff -U<context> 的输出一致
+	}{
+		{
+			name: "identical",
+			a:    "a\nb\n", b: "a\nb\n", context: 3,
+			want: "",
+		},
+		{
+			name: "line endings ignored",
+			a:    "a\r\nb\r\n", b: "a\nb\n", context: 3,
+			want: "",
+		},
+		{
+			name: "single change",
+			a:    "a\nb\nc\nd\ne\n", b: "a\nb\nX\nd\ne\n", context: 1,
+			want: "@@ -2,3 +2,3 @@\n b\n-c\n+X\n d\n",
+		},
+		{
+			name: "insert into empty",
+			a:    "", b: "x\ny\n", context: 3,
+			want: "@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name: "delete only line",
+			a:    "x\n", b: "", context: 3,
+			want: "@@ -1 +0,0 @@\n-x\n",
+		},
+		{
+			name:    "far apart changes split into hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:       "1\ntwo\n3\n4\n5\n6\n7\n8\nnine\n10\n",
+			context: 1,
+			want:    "@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n@@ -8,3 +8,3 @@\n 8\n-9\n+nine\n 10\n",
+		},
+		{
+			name:    "close changes merged",
+			a:       "1\n2\n3\n4\n5\n",
+			b:       "1\ntwo\n3\nfour\n5\n",
+			context: 1,
+			want:    "@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n-4\n+four\n 5\n",
+		},
+		{
+			name:    "chinese revision",
+			a:       "标题: 旧标题\n\n第一段\n第二段\n",
+			b:       "标题: 新标题\n\n第一段\n新增一段\n第二段\n",
+			context: 3,
+			want:    "@@ -1,4 +1,5 @@\n-标题: 旧标题\n+标题: 新标题\n \n 第一段\n+新增一段\n 第二段\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("v1", "v2", tt.a, tt.b, tt.context)
+			want := tt.want
+			if want != "" {
+				want = "--- v1\n+++ v2\n" + want
+			}
+			if got != want {
+				t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffTooManyEdits(t *testing.T) {
+	// 超过 diffMaxEdits 时退化为全部删除 + 全部新增，结果仍然是合法的 diff
+	var a, b strings.Builder
+	for i := 0; i < diffMaxEdits+10; i++ {
+		a.WriteString("a\n")
+		b.WriteString("b\n")
+	}
+	got := UnifiedDiff("v1", "v2", a.String(), b.String(), 3)
+	if strings.Count(got, "\n-a") != diffMaxEdits+10 || strings.Count(got, "\n+b") != diffMaxEdits+10 {
+		t.Fatalf("expected every line replaced, got %d bytes", len(got))
+	}
+}
@@ -5,25 +5,47 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-var SecretKey = []byte("your-secret-key-llp-blog") // 密钥，随便写
-
 // GenerateToken 生成 JWT Token
-func GenerateToken(userId int, username string) (string, error) {
+// [MODIFY] 载荷中带上角色和权限编码，供 middleware.RequireRole / RequirePermission 使用
+// [MODIFY] 有效期由调用方决定 (短期 Access Token)，sid 为所属登录会话，用于服务端吊销
+// [MODIFY] 使用当前签名密钥 (见 jwt_keys.go)，Header 中带上 kid
+func GenerateToken(userId int, username, sessionId string, roles, permissions []string, ttl time.Duration) (string, error) {
+	now := time.Now()
 	claims := jwt.MapClaims{
+		"jti":      uuid.New().String(),
+		"sid":      sessionId,
 		"userId":   userId,
 		"username": username,
-		"exp":      time.Now().Add(time.Hour * 24 * 7).Unix(), // 7天过期
+		"roles":    roles,
+		"perms":    permissions,
+		"iat":      now.Unix(),
+		"exp":      now.Add(ttl).Unix(),
+	}
+	key, err := activeJwtKey()
+	if err != nil {
+		return "", err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(SecretKey)
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.signKey)
 }
 
 // [NEW] 解析 Token
+// [MODIFY] 按 Header 中的 kid 选择密钥 (轮换期间新旧密钥签发的 Token 都有效)，算法必须与密钥一致
 func ParseToken(tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return SecretKey, nil
+		kid, _ := token.Header["kid"].(string)
+		key, ok := findJwtKey(kid)
+		if !ok {
+			return nil, errors.New("unknown kid")
+		}
+		if token.Method.Alg() != key.Alg {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.verifyKey, nil
 	})
 	if err != nil {
 		return nil, err
@@ -33,3 +55,16 @@ func ParseToken(tokenString string) (jwt.MapClaims, error) {
 	}
 	return nil, errors.New("invalid token")
 }
+
+// [NEW] 从 claims 中读取字符串数组 (JSON 解析出来是 []interface{})
+func ClaimStrings(claims jwt.MapClaims, key string) []string {
+	var list []string
+	if raw, ok := claims[key].([]interface{}); ok {
+		for _, v := range raw {
+			if s, ok := v.(string); ok {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
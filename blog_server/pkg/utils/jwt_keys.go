@@ -0,0 +1,245 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256" // 对称密钥，只能本服务自己校验，不会出现在 JWKS 中
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA" // Ed25519
+)
+
+// JwtKey 一把签名密钥，由 kid 区分
+// 只有公钥时 (其他实例的密钥) 只能用于校验
+type JwtKey struct {
+	Kid       string
+	Alg       string
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// CanSign 是否持有私钥
+func (k *JwtKey) CanSign() bool {
+	return k.signKey != nil
+}
+
+func (k *JwtKey) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Alg)
+}
+
+// 当前生效的密钥 (定期重新加载，需要加锁)
+var (
+	jwtKeysMu sync.RWMutex
+	jwtKeys   = map[string]*JwtKey{}
+	jwtActive *JwtKey
+)
+
+// SetJwtKeys 替换全部密钥，activeKid 为签发新 Token 使用的密钥
+func SetJwtKeys(keys []*JwtKey, activeKid string) error {
+	m := make(map[string]*JwtKey, len(keys))
+	for _, k := range keys {
+		if _, ok := m[k.Kid]; ok {
+			return fmt.Errorf("kid 重复: %s", k.Kid)
+		}
+		m[k.Kid] = k
+	}
+	active, ok := m[activeKid]
+	if !ok {
+		return fmt.Errorf("签名密钥不存在: %s", activeKid)
+	}
+	if !active.CanSign() {
+		return fmt.Errorf("签名密钥缺少私钥: %s", activeKid)
+	}
+
+	jwtKeysMu.Lock()
+	defer jwtKeysMu.Unlock()
+	jwtKeys = m
+	jwtActive = active
+	return nil
+}
+
+// ActiveJwtKid 当前签名密钥的 kid
+func ActiveJwtKid() string {
+	jwtKeysMu.RLock()
+	defer jwtKeysMu.RUnlock()
+	if jwtActive == nil {
+		return ""
+	}
+	return jwtActive.Kid
+}
+
+func activeJwtKey() (*JwtKey, error) {
+	jwtKeysMu.RLock()
+	defer jwtKeysMu.RUnlock()
+	if jwtActive == nil {
+		return nil, errors.New("JWT 签名密钥未初始化")
+	}
+	return jwtActive, nil
+}
+
+func findJwtKey(kid string) (*JwtKey, bool) {
+	jwtKeysMu.RLock()
+	defer jwtKeysMu.RUnlock()
+	k, ok := jwtKeys[kid]
+	return k, ok
+}
+
+// NewHMACKey HS256 密钥 (至少 32 字节)
+func NewHMACKey(kid string, secret []byte) (*JwtKey, error) {
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("密钥 %s 太短，HS256 至少需要 32 字节", kid)
+	}
+	return &JwtKey{Kid: kid, Alg: AlgHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+// ParsePEMKey 解析 PEM 格式的密钥：PKCS#8 私钥 (RSA / Ed25519) 或 PKIX 公钥
+// 算法由密钥类型决定：RSA -> RS256，Ed25519 -> EdDSA
+func ParsePEMKey(kid string, data []byte) (*JwtKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("密钥 %s 不是 PEM 格式", kid)
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("密钥 %s 解析失败: %w", kid, err)
+		}
+		switch k := priv.(type) {
+		case *rsa.PrivateKey:
+			return &JwtKey{Kid: kid, Alg: AlgRS256, signKey: k, verifyKey: &k.PublicKey}, nil
+		case ed25519.PrivateKey:
+			return &JwtKey{Kid: kid, Alg: AlgEdDSA, signKey: k, verifyKey: k.Public()}, nil
+		}
+	case "RSA PRIVATE KEY":
+		k, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("密钥 %s 解析失败: %w", kid, err)
+		}
+		return &JwtKey{Kid: kid, Alg: AlgRS256, signKey: k, verifyKey: &k.PublicKey}, nil
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("密钥 %s 解析失败: %w", kid, err)
+		}
+		switch k := pub.(type) {
+		case *rsa.PublicKey:
+			return &JwtKey{Kid: kid, Alg: AlgRS256, verifyKey: k}, nil
+		case ed25519.PublicKey:
+			return &JwtKey{Kid: kid, Alg: AlgEdDSA, verifyKey: k}, nil
+		}
+	}
+	return nil, fmt.Errorf("密钥 %s 类型不支持 (仅支持 RSA / Ed25519)", kid)
+}
+
+// GenerateJwtKeyPEM 生成新的私钥 (PKCS#8 PEM)
+func GenerateJwtKeyPEM(alg string) ([]byte, error) {
+	var priv interface{}
+	switch alg {
+	case AlgRS256:
+		k, err := rsa.GenerateKey(rand.Reader, 3072)
+		if err != nil {
+			return nil, err
+		}
+		priv = k
+	case AlgEdDSA:
+		_, k, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		priv = k
+	default:
+		return nil, fmt.Errorf("不支持的算法: %s (可选 %s / %s)", alg, AlgRS256, AlgEdDSA)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicKeyPEM 导出公钥 (分发给只需要校验的实例)
+func (k *JwtKey) PublicKeyPEM() ([]byte, error) {
+	if k.Alg == AlgHS256 {
+		return nil, errors.New("HS256 没有公钥")
+	}
+	der, err := x509.MarshalPKIXPublicKey(k.verifyKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// NewJwtKid 生成 kid：日期 + 随机串，按字典序排序即按生成时间排序
+func NewJwtKid() string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+// JWK 单个公钥 (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA
+	E   string `json:"e,omitempty"`   // RSA
+	Crv string `json:"crv,omitempty"` // OKP
+	X   string `json:"x,omitempty"`   // OKP
+}
+
+// JWKSet JWKS 接口返回的格式
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 当前所有非对称密钥的公钥 (供其他服务校验 Token)
+func JWKS() JWKSet {
+	jwtKeysMu.RLock()
+	defer jwtKeysMu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, k := range jwtKeys {
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: k.Kid,
+				Use: "sig",
+				Alg: k.Alg,
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: k.Kid,
+				Use: "sig",
+				Alg: k.Alg,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 密码校验失败的错误码 (前端据此提示，不依赖 msg 文案)
+const (
+	PwdErrTooShort = "PASSWORD_TOO_SHORT"
+	PwdErrTooLong  = "PASSWORD_TOO_LONG"
+	PwdErrTooWeak  = "PASSWORD_TOO_WEAK" // 字符种类不够
+	PwdErrBreached = "PASSWORD_BREACHED" // 出现在泄露密码库中
+	PwdErrReused   = "PASSWORD_REUSED"   // 与最近使用过的密码相同
+)
+
+// bcrypt 只使用前 72 字节，超出部分会报错
+const bcryptMaxBytes = 72
+
+// PasswordError 带错误码的密码校验错误
+type PasswordError struct {
+	Code string
+	Msg  string
+}
+
+func (e *PasswordError) Error() string {
+	return e.Msg
+}
+
+// PasswordPolicy 密码强度要求 (也返回给前端展示)
+type PasswordPolicy struct {
+	MinLength   int `json:"minLength"`
+	MaxLength   int `json:"maxLength"`
+	MinClasses  int `json:"minClasses"`  // 大写字母、小写字母、数字、符号 至少包含几类
+	HistorySize int `json:"historySize"` // 不能与最近几次的密码相同，0 表示不限制
+}
+
+// Check 校验长度和字符种类
+func (p PasswordPolicy) Check(password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return &PasswordError{PwdErrTooShort, fmt.Sprintf("密码长度不能少于 %d 位", p.MinLength)}
+	}
+	if utf8.RuneCountInString(password) > p.MaxLength || len(password) > bcryptMaxBytes {
+		return &PasswordError{PwdErrTooLong, fmt.Sprintf("密码长度不能超过 %d 位", p.MaxLength)}
+	}
+	if passwordClasses(password) < p.MinClasses {
+		return &PasswordError{PwdErrTooWeak, fmt.Sprintf("密码需包含大写字母、小写字母、数字、符号中的至少 %d 种", p.MinClasses)}
+	}
+	return nil
+}
+
+func passwordClasses(password string) int {
+	var upper, lower, digit, other int
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+	return upper + lower + digit + other
+}
+
+// --- 泄露密码库 ---
+// 按 k-anonymity 方式组织：SHA-1 的前 5 位作为分桶前缀，桶内保存剩余 35 位 (有序)
+// 与 Have I Been Pwned 的 range 接口一致，以后可以换成远程查询而不改调用方
+
+//go:embed breached_passwords.txt
+var bundledBreachedPasswords []byte
+
+const breachedPrefixLen = 5
+
+var (
+	breachedMu    sync.RWMutex
+	breachedIndex map[string][]string
+)
+
+// LoadBreachedPasswords 加载泄露密码库，r 为 nil 时使用内置的列表
+// 格式：每行一个 SHA-1 (十六进制)，可带 ":出现次数"，# 开头为注释
+func LoadBreachedPasswords(r io.Reader) (int, error) {
+	if r == nil {
+		r = bytes.NewReader(bundledBreachedPasswords)
+	}
+
+	index := map[string][]string{}
+	count := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != sha1.Size*2 {
+			return 0, fmt.Errorf("泄露密码库格式错误: %s", line)
+		}
+		prefix := hash[:breachedPrefixLen]
+		index[prefix] = append(index[prefix], hash[breachedPrefixLen:])
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	for _, suffixes := range index {
+		sort.Strings(suffixes)
+	}
+
+	breachedMu.Lock()
+	defer breachedMu.Unlock()
+	breachedIndex = index
+	return count, nil
+}
+
+// IsBreachedPassword 密码是否出现在泄露密码库中 (未加载时返回 false)
+func IsBreachedPassword(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes := breachedRange(hash[:breachedPrefixLen])
+	suffix := hash[breachedPrefixLen:]
+	i := sort.SearchStrings(suffixes, suffix)
+	return i < len(suffixes) && suffixes[i] == suffix
+}
+
+// 按前缀取出一个桶
+func breachedRange(prefix string) []string {
+	breachedMu.RLock()
+	defer breachedMu.RUnlock()
+	return breachedIndex[prefix]
+}
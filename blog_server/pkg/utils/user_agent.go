@@ -0,0 +1,54 @@
+package utils
+
+import "strings"
+
+// ParseDevice 从 User-Agent 中粗略解析出 "浏览器 / 系统"，用于登录设备列表展示
+// 只识别常见值，识别不了的返回 "未知设备"
+func ParseDevice(ua string) string {
+	if ua == "" {
+		return "未知设备"
+	}
+
+	browser := ""
+	// 注意顺序：Edge/Opera 的 UA 里也包含 Chrome，Chrome 的 UA 里也包含 Safari
+	switch {
+	case strings.Contains(ua, "MicroMessenger"):
+		browser = "微信"
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(ua, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		os = "iOS"
+	case strings.Contains(ua, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	}
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " / " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	}
+	return "未知设备"
+}
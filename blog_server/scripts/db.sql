@@ -0,0 +1,243 @@
+-- ==========================================
+-- 增量脚本：按功能追加，已有库按顺序执行即可
+-- ==========================================
+
+-- ------------------------------------------
+-- 角色权限 (RBAC)
+-- ------------------------------------------
+CREATE TABLE IF NOT EXISTS `t_role` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `code` varchar(50) NOT NULL COMMENT '角色编码 admin/author/reader',
+  `name` varchar(50) NOT NULL COMMENT '角色名称',
+  `description` varchar(200) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_role_code` (`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `t_permission` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `code` varchar(50) NOT NULL COMMENT '权限编码 article:write 等',
+  `name` varchar(50) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_permission_code` (`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `t_user_role` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL,
+  `role_id` int NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_role` (`user_id`, `role_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `t_role_permission` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `role_id` int NOT NULL,
+  `permission_id` int NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_role_permission` (`role_id`, `permission_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 角色顺序很重要：admin 的 id 最小，登录时会排在 authorities 第一位
+INSERT IGNORE INTO `t_role` (`id`, `code`, `name`, `description`) VALUES
+  (1, 'admin', '管理员', '拥有全部权限'),
+  (2, 'author', '作者', '可以发布和管理文章'),
+  (3, 'reader', '读者', '浏览、评论、点赞');
+
+INSERT IGNORE INTO `t_permission` (`id`, `code`, `name`) VALUES
+  (1, 'article:write', '发布/编辑文章'),
+  (2, 'article:delete', '删除文章'),
+  (3, 'category:manage', '分类管理'),
+  (4, 'comment:manage', '评论管理'),
+  (5, 'user:manage', '用户与角色管理');
+
+INSERT IGNORE INTO `t_role_permission` (`role_id`, `permission_id`) VALUES
+  (1, 1), (1, 2), (1, 3), (1, 4), (1, 5),
+  (2, 1), (2, 2);
+
+-- 历史数据：admin 账号设为管理员，其余用户设为读者
+INSERT IGNORE INTO `t_user_role` (`user_id`, `role_id`)
+  SELECT `id`, 1 FROM `t_user` WHERE `username` = 'admin';
+INSERT IGNORE INTO `t_user_role` (`user_id`, `role_id`)
+  SELECT `id`, 3 FROM `t_user` WHERE `username` <> 'admin';
+
+
+-- ------------------------------------------
+-- 两步验证 (TOTP + 恢复码)
+-- ------------------------------------------
+ALTER TABLE `t_user`
+  ADD COLUMN `totp_secret` varchar(64) NOT NULL DEFAULT '' COMMENT 'TOTP 密钥 (Base32)',
+  ADD COLUMN `totp_enabled` tinyint(1) NOT NULL DEFAULT 0 COMMENT '1:已开启两步验证';
+
+CREATE TABLE IF NOT EXISTS `t_user_recovery_code` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL,
+  `code_hash` char(64) NOT NULL COMMENT '恢复码 SHA-256',
+  `used` tinyint(1) NOT NULL DEFAULT 0,
+  `used_at` datetime DEFAULT NULL,
+  `created` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_hash` (`user_id`, `code_hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- ------------------------------------------
+-- 通行密钥 (Passkey / WebAuthn)
+-- ------------------------------------------
+CREATE TABLE IF NOT EXISTS `t_passkey` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL,
+  `name` varchar(64) NOT NULL DEFAULT '',
+  `credential_id` varchar(255) NOT NULL COMMENT '凭证 ID (base64url)',
+  `credential` text NOT NULL COMMENT 'webauthn.Credential JSON (公钥、签名计数等)',
+  `created` datetime NOT NULL,
+  `last_used` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_credential_id` (`credential_id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- ------------------------------------------
+-- 第三方登录 (OAuth2 / OIDC) 账号绑定
+-- ------------------------------------------
+CREATE TABLE IF NOT EXISTS `t_user_identity` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL,
+  `provider` varchar(32) NOT NULL COMMENT '对应配置中的 name，如 github',
+  `subject` varchar(255) NOT NULL COMMENT '第三方用户唯一 ID',
+  `email` varchar(255) NOT NULL DEFAULT '',
+  `nickname` varchar(255) NOT NULL DEFAULT '',
+  `created` datetime NOT NULL,
+  `last_login` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_provider_subject` (`provider`, `subject`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- ------------------------------------------
+-- 邮箱唯一 (修改邮箱需验证新邮箱)
+-- 空邮箱 (第三方登录注册的账号) 不参与唯一约束，需要 MySQL 8.0.13+
+-- 执行前请先清理重复的邮箱：
+--   SELECT `email`, COUNT(*) FROM `t_user` WHERE `email` <> '' GROUP BY `email` HAVING COUNT(*) > 1;
+-- ------------------------------------------
+ALTER TABLE `t_user`
+  ADD UNIQUE KEY `uk_email` ((NULLIF(`email`, '')));
+
+-- ------------------------------------------
+-- 个人访问令牌 (API Token，脚本 / CI 使用)
+-- ------------------------------------------
+CREATE TABLE IF NOT EXISTS `t_access_token` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL,
+  `name` varchar(64) NOT NULL DEFAULT '',
+  `token_hash` char(64) NOT NULL COMMENT '令牌 SHA-256',
+  `prefix` varchar(16) NOT NULL DEFAULT '' COMMENT '令牌开头几位，方便辨认',
+  `scopes` varchar(255) NOT NULL DEFAULT '' COMMENT '授权范围，逗号分隔',
+  `expires_at` datetime DEFAULT NULL COMMENT '为空表示永不过期',
+  `last_used` datetime DEFAULT NULL,
+  `last_used_ip` varchar(64) NOT NULL DEFAULT '',
+  `created` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_token_hash` (`token_hash`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- ------------------------------------------
+-- 历史密码 (禁止重复使用最近 N 次的密码，N 见 config.yaml password.history_size)
+-- ------------------------------------------
+CREATE TABLE IF NOT EXISTS `t_password_history` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `user_id` INT NOT NULL,
+  `password` VARCHAR(100) NOT NULL COMMENT 'bcrypt 哈希',
+  `created` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- ------------------------------------------
+-- 个人主页公开资料
+-- ------------------------------------------
+ALTER TABLE `t_user`
+  ADD COLUMN `nickname` varchar(32) NOT NULL DEFAULT '',
+  ADD COLUMN `bio` varchar(200) NOT NULL DEFAULT '' COMMENT '个人简介',
+  ADD COLUMN `website` varchar(255) NOT NULL DEFAULT '',
+  ADD COLUMN `location` varchar(64) NOT NULL DEFAULT '',
+  ADD COLUMN `cover` varchar(255) NOT NULL DEFAULT '' COMMENT '主页封面图',
+  ADD COLUMN `social_links` text COMMENT '社交链接 JSON: [{"platform":"github","url":"..."}]';
+
+-- ------------------------------------------
+-- 关注 (follower 关注了 followee)
+-- ------------------------------------------
+CREATE TABLE IF NOT EXISTS `t_user_follow` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `follower_id` INT NOT NULL COMMENT '粉丝',
+  `followee_id` INT NOT NULL COMMENT '被关注的作者',
+  `created` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_follower_followee` (`follower_id`, `followee_id`),
+  KEY `idx_followee_id` (`followee_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 关注动态按 (created, id) 游标翻页
+ALTER TABLE `t_article` ADD INDEX `idx_user_created` (`user_id`, `created`, `id`);
+
+-- ------------------------------------------
+-- 拉黑 / 屏蔽 (blocker 拉黑 / 屏蔽了 blocked)
+-- ------------------------------------------
+CREATE TABLE IF NOT EXISTS `t_user_block` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `blocker_id` INT NOT NULL,
+  `blocked_id` INT NOT NULL,
+  `type` VARCHAR(16) NOT NULL DEFAULT 'block' COMMENT 'block:拉黑 mute:屏蔽',
+  `created` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_blocker_blocked` (`blocker_id`, `blocked_id`),
+  KEY `idx_blocked_id` (`blocked_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- ------------------------------------------
+-- 文章发布状态 (draft 草稿 / published 已发布 / scheduled 定时发布 / archived 已归档)
+-- ------------------------------------------
+ALTER TABLE `t_article`
+  ADD COLUMN `status` VARCHAR(16) NOT NULL DEFAULT 'published' COMMENT 'draft/published/scheduled/archived',
+  ADD COLUMN `publish_at` DATETIME NULL COMMENT '定时发布时间 / 实际发布时间';
+-- 定时发布扫描
+ALTER TABLE `t_article` ADD INDEX `idx_status_publish_at` (`status`, `publish_at`);
+UPDATE `t_article` SET `publish_at` = `created` WHERE `publish_at` IS NULL;
+
+-- ------------------------------------------
+-- 文章历史版本 (每次保存一条，version 从 1 递增)
+-- ------------------------------------------
+CREATE TABLE IF NOT EXISTS `t_article_revision` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `article_id` INT NOT NULL,
+  `version` INT NOT NULL,
+  `title` VARCHAR(255) NOT NULL,
+  `content` LONGTEXT,
+  `tags` VARCHAR(255) DEFAULT NULL,
+  `categories` VARCHAR(255) DEFAULT NULL,
+  `category_id` INT DEFAULT NULL,
+  `editor_id` INT NOT NULL DEFAULT 0 COMMENT '保存这个版本的用户 (作者或管理员)',
+  `editor_name` VARCHAR(64) DEFAULT NULL,
+  `remark` VARCHAR(64) DEFAULT NULL COMMENT '例如: 恢复自版本 3',
+  `created` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_article_version` (`article_id`, `version`),
+  KEY `idx_editor_id` (`editor_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- ------------------------------------------
+-- 文章 slug (链接 /article/slug/:slug)，以前的文章在服务启动时自动生成
+-- ------------------------------------------
+ALTER TABLE `t_article` ADD COLUMN `slug` VARCHAR(128) NULL COMMENT '文章链接 (标题转拼音或自定义)';
+ALTER TABLE `t_article` ADD UNIQUE KEY `uk_slug` (`slug`);
+
+-- 旧 slug (标题或自定义链接修改后保留，旧链接 301 跳转)
+CREATE TABLE IF NOT EXISTS `t_article_slug` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `article_id` INT NOT NULL,
+  `slug` VARCHAR(128) NOT NULL,
+  `created` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_slug` (`slug`),
+  KEY `idx_article_id` (`article_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,57 @@
+// [NEW] 通行密钥 (WebAuthn) 浏览器端封装
+// 后端返回的 options 中二进制字段是 base64url 字符串，浏览器 API 需要 ArrayBuffer，这里负责来回转换
+
+const toBuffer = (value) => {
+  const base64 = value.replace(/-/g, '+').replace(/_/g, '/')
+  const padded = base64 + '='.repeat((4 - (base64.length % 4)) % 4)
+  return Uint8Array.from(atob(padded), c => c.charCodeAt(0)).buffer
+}
+
+const toBase64url = (buffer) => {
+  const bytes = new Uint8Array(buffer)
+  let binary = ''
+  bytes.forEach(b => { binary += String.fromCharCode(b) })
+  return btoa(binary).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '')
+}
+
+export const passkeySupported = () => !!window.PublicKeyCredential
+
+// 注册：options 为 /api/user/passkey/register/begin 返回的 options
+export async function createPasskey(options) {
+  const publicKey = { ...options.publicKey }
+  publicKey.challenge = toBuffer(publicKey.challenge)
+  publicKey.user = { ...publicKey.user, id: toBuffer(publicKey.user.id) }
+  publicKey.excludeCredentials = (publicKey.excludeCredentials || []).map(c => ({ ...c, id: toBuffer(c.id) }))
+
+  const credential = await navigator.credentials.create({ publicKey })
+  return {
+    id: credential.id,
+    rawId: toBase64url(credential.rawId),
+    type: credential.type,
+    response: {
+      clientDataJSON: toBase64url(credential.response.clientDataJSON),
+      attestationObject: toBase64url(credential.response.attestationObject),
+      transports: credential.response.getTransports ? credential.response.getTransports() : []
+    }
+  }
+}
+
+// 登录：options 为 /api/passkey/login/begin 返回的 options
+export async function getPasskey(options) {
+  const publicKey = { ...options.publicKey }
+  publicKey.challenge = toBuffer(publicKey.challenge)
+  publicKey.allowCredentials = (publicKey.allowCredentials || []).map(c => ({ ...c, id: toBuffer(c.id) }))
+
+  const credential = await navigator.credentials.get({ publicKey })
+  return {
+    id: credential.id,
+    rawId: toBase64url(credential.rawId),
+    type: credential.type,
+    response: {
+      clientDataJSON: toBase64url(credential.response.clientDataJSON),
+      authenticatorData: toBase64url(credential.response.authenticatorData),
+      signature: toBase64url(credential.response.signature),
+      userHandle: credential.response.userHandle ? toBase64url(credential.response.userHandle) : null
+    }
+  }
+}
@@ -1,4 +1,5 @@
 import axios from 'axios'
+import { useStore } from '@/stores/my'
 
 // --- 1. 定义 Axios 实例 ---
 const request = axios.create({
@@ -59,6 +60,25 @@ request.interceptors.request.use(
   },
 )
 
+// [NEW] 刷新中的请求 (多个请求同时 401 时只刷新一次)
+let refreshing = null
+
+function refreshToken() {
+  // 注意：useStore 必须在函数内调用，此时 Pinia 已经初始化
+  const store = useStore()
+  const rt = store.user.refreshToken
+  if (!rt) {
+    return Promise.reject(new Error('no refresh token'))
+  }
+  return axios.post('/api/token/refresh', { refreshToken: rt }).then((res) => {
+    if (!res.data.success) {
+      throw new Error(res.data.msg)
+    }
+    store.setTokens(res.data.map.token, res.data.map.refreshToken)
+    return res.data.map.token
+  })
+}
+
 // 响应拦截器
 request.interceptors.response.use(
   (response) => {
@@ -66,6 +86,25 @@ request.interceptors.response.use(
     return response
   },
   (error) => {
+    // [NEW] Access Token 过期：用 Refresh Token 换新后重试一次
+    const original = error.config
+    if (error.response && error.response.status === 401 && original && !original._retried) {
+      original._retried = true
+      if (!refreshing) {
+        refreshing = refreshToken().finally(() => {
+          refreshing = null
+        })
+      }
+      return refreshing
+        .then((token) => {
+          original.headers['Authorization'] = 'Bearer ' + token
+          return request(original)
+        })
+        .catch(() => {
+          useStore().logout()
+          return Promise.reject(error)
+        })
+    }
     console.log('请求出错：' + error)
     return Promise.reject(error)
   },
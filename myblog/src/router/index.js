@@ -21,6 +21,18 @@ const routes = [
     name: 'register', // 统一改为小写驼峰
     component: () => import('@/views/common/Register.vue'),
   },
+  // [NEW] 第三方登录回调
+  {
+    path: '/oauth/callback',
+    name: 'oauthCallback',
+    component: () => import('@/views/common/OAuthCallback.vue'),
+  },
+  // [NEW] 邮件登录链接
+  {
+    path: '/magic-login',
+    name: 'magicLogin',
+    component: () => import('@/views/common/MagicLogin.vue'),
+  },
   {
     path: '/search',
     name: 'search',
@@ -17,16 +17,24 @@ export const useStore = defineStore(
     // 使用 reactive 包裹 user 属性，是为了保持和你项目中 store.user.user 的调用结构一致
     const user = reactive({ 
       user: null, 
-      token: ''  // <--- 这里的空字符串是占位符
+      token: '',  // <--- 这里的空字符串是占位符
+      refreshToken: '' // [NEW] 用于 Access Token 过期后换新
       })
 
     // --- Actions / Functions ---
 
     // 登录：保存用户信息
-    function login(userData, tokenStr) {
+    function login(userData, tokenStr, refreshTokenStr) {
       // 注意：reactive 对象直接修改属性，不需要 .value
       user.user = userData
       user.token = tokenStr // <--- 关键：把 token 存进响应式对象里
+      user.refreshToken = refreshTokenStr || ''
+    }
+
+    // [NEW] 刷新 Token 后只替换 token，不动用户信息
+    function setTokens(tokenStr, refreshTokenStr) {
+      user.token = tokenStr
+      user.refreshToken = refreshTokenStr
     }
 
     // 注销：清空用户信息
@@ -35,9 +43,10 @@ export const useStore = defineStore(
       user.user = null
       // 由于开启了 persist，pinia 插件会自动把 localStorage 里的数据也同步清空
       user.token = '' // <--- 记得一起清空
+      user.refreshToken = ''
     }
 
-    return { articleId, page, home, user, login, logout }
+    return { articleId, page, home, user, login, setTokens, logout }
   },
   {
     // [新增] 开启持久化配置